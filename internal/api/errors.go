@@ -0,0 +1,45 @@
+// Package api provides types shared by the platform API clients.
+package api
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// HTTPError is returned by platform clients when an API responds with a
+// non-success status.
+type HTTPError struct {
+	Platform   string
+	StatusCode int
+	Message    string
+}
+
+// NewHTTPError creates an HTTPError from a response. The response body is
+// not read.
+func NewHTTPError(platform string, resp *http.Response, message string) *HTTPError {
+	return &HTTPError{
+		Platform:   platform,
+		StatusCode: resp.StatusCode,
+		Message:    message,
+	}
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s API: %s (status %d)", e.Platform, e.Message, e.StatusCode)
+}
+
+// HTTPStatus returns the response status code.
+func (e *HTTPError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+// Temporary reports whether retrying the request may succeed: rate limits,
+// request timeouts and server errors are temporary, while authentication
+// failures and other client errors are not.
+func (e *HTTPError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	}
+	return e.StatusCode >= 500
+}
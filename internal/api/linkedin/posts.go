@@ -40,6 +40,27 @@ func (p *Posts) GetUserPosts(ctx context.Context, maxResults int) ([]*data.Linke
 	return nil, nil
 }
 
+// GetUserPostsPage fetches one page of the user's posts, newest first,
+// starting at offset start, for walking the full post history. It returns
+// the offset of the next page, or -1 on the last page.
+// Uses LinkedIn API: GET /rest/posts with start and count
+func (p *Posts) GetUserPostsPage(ctx context.Context, start, count int) ([]*data.LinkedInPost, int, error) {
+	// TODO: Implement using LinkedIn API
+	// Endpoint: GET https://api.linkedin.com/rest/posts
+	// Headers:
+	//   - Authorization: Bearer {access_token}
+	//   - LinkedIn-Version: {YYYYMM}
+	// Parameters:
+	//   - q: author
+	//   - author: urn:li:person:{person_id}
+	//   - start: {start}
+	//   - count: 1-100
+	//   - sortBy: CREATED
+	//
+	// The last page returns fewer than count elements.
+	return nil, -1, nil
+}
+
 // GetPost fetches a specific post by ID.
 // Uses LinkedIn API: GET /ugcPosts/{post_id}
 func (p *Posts) GetPost(ctx context.Context, postID string) (*data.LinkedInPost, error) {
@@ -3,7 +3,13 @@ package x
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 
+	"github.com/omnipulse/omnipulse/internal/api"
 	"github.com/omnipulse/omnipulse/internal/data"
 )
 
@@ -58,6 +64,25 @@ func (m *Metrics) GetUserTweets(ctx context.Context, maxResults int) ([]*data.Tw
 	return nil, nil
 }
 
+// GetUserTweetsPage fetches one page of the user's tweets, newest first,
+// for walking the full timeline. It returns the token for the next page, or
+// an empty string on the last page.
+// Uses X API v2: GET /2/users/:id/tweets with pagination_token
+func (m *Metrics) GetUserTweetsPage(ctx context.Context, paginationToken string, maxResults int) ([]*data.Tweet, string, error) {
+	// TODO: Implement using X API v2
+	// Endpoint: GET https://api.twitter.com/2/users/{id}/tweets
+	// Headers:
+	//   - Authorization: Bearer {bearer_token}
+	// Parameters:
+	//   - max_results: 5-100
+	//   - pagination_token: {token} (omit for the first page)
+	//   - tweet.fields: public_metrics,created_at
+	//
+	// Response includes meta.next_token, absent on the last page.
+	// Note: the endpoint only returns the most recent 3200 tweets.
+	return nil, "", nil
+}
+
 // GetTweetMetrics fetches detailed metrics for a specific tweet.
 // Uses X API v2: GET /2/tweets/:id
 func (m *Metrics) GetTweetMetrics(ctx context.Context, tweetID string) (*data.Tweet, error) {
@@ -92,3 +117,92 @@ func (m *Metrics) GetMentions(ctx context.Context, maxResults int) ([]*data.Twee
 	//   - user.fields: name,username,profile_image_url
 	return nil, nil
 }
+
+// entityLookupBatch is the most tweets the lookup endpoint accepts per
+// request.
+const entityLookupBatch = 100
+
+// tweetEntities is the entities object of an X API v2 tweet.
+type tweetEntities struct {
+	Hashtags []struct {
+		Tag string `json:"tag"`
+	} `json:"hashtags"`
+	Mentions []struct {
+		Username string `json:"username"`
+	} `json:"mentions"`
+	URLs []struct {
+		URL         string `json:"url"`
+		ExpandedURL string `json:"expanded_url"`
+		UnwoundURL  string `json:"unwound_url"` // Final URL after redirects, when X knows it
+	} `json:"urls"`
+}
+
+// contentEntities converts a tweet's entities, attributing links to the
+// domain they lead to rather than t.co.
+func (e *tweetEntities) contentEntities(tweetID string) []data.ContentEntity {
+	var entities []data.ContentEntity
+	add := func(entityType data.EntityType, text string) {
+		if entity, ok := data.NewContentEntity(data.PlatformX, tweetID, entityType, text); ok {
+			entities = append(entities, entity)
+		}
+	}
+	for _, h := range e.Hashtags {
+		add(data.EntityHashtag, "#"+h.Tag)
+	}
+	for _, m := range e.Mentions {
+		add(data.EntityMention, "@"+m.Username)
+	}
+	for _, u := range e.URLs {
+		switch {
+		case u.UnwoundURL != "":
+			add(data.EntityDomain, u.UnwoundURL)
+		case u.ExpandedURL != "":
+			add(data.EntityDomain, u.ExpandedURL)
+		}
+	}
+	return entities
+}
+
+// GetTweetEntities fetches the hashtags, mentions and expanded links of
+// tweets, keyed by tweet ID. Tweets that no longer exist are left out.
+// Uses X API v2: GET /2/tweets with tweet.fields=entities
+func (m *Metrics) GetTweetEntities(ctx context.Context, tweetIDs []string) (map[string][]data.ContentEntity, error) {
+	entities := make(map[string][]data.ContentEntity, len(tweetIDs))
+	for start := 0; start < len(tweetIDs); start += entityLookupBatch {
+		ids := tweetIDs[start:min(start+entityLookupBatch, len(tweetIDs))]
+		params := url.Values{
+			"ids":          {strings.Join(ids, ",")},
+			"tweet.fields": {"entities"},
+		}
+		req, err := http.NewRequestWithContext(ctx, "GET", m.client.baseURL+"/tweets?"+params.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating tweet lookup request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+m.client.GetBearerToken())
+
+		resp, err := m.client.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("looking up tweets: %w", err)
+		}
+		var body struct {
+			Data []struct {
+				ID       string        `json:"id"`
+				Entities tweetEntities `json:"entities"`
+			} `json:"data"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, api.NewHTTPError("x", resp, "tweet lookup failed")
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decoding tweet lookup response: %w", err)
+		}
+
+		for _, tweet := range body.Data {
+			entities[tweet.ID] = tweet.Entities.contentEntities(tweet.ID)
+		}
+	}
+	return entities, nil
+}
@@ -3,7 +3,14 @@ package x
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/omnipulse/omnipulse/internal/api"
 	"github.com/omnipulse/omnipulse/internal/data"
 )
 
@@ -64,3 +71,97 @@ func (r *Replies) GetQuoteTweets(ctx context.Context, tweetID string, maxResults
 	//   - user.fields: name,username
 	return nil, nil
 }
+
+// Reply is a reply in a conversation with the ID of the post it answers,
+// which is the conversation's root tweet for direct replies.
+type Reply struct {
+	Comment   *data.Comment
+	InReplyTo string
+}
+
+// GetConversationReplies fetches recent replies in the conversation started
+// by tweetID, including replies to other replies. Each reply's comment is
+// attributed to tweetID.
+// Uses X API v2: GET /2/tweets/search/recent with conversation_id filter
+func (r *Replies) GetConversationReplies(ctx context.Context, tweetID string, maxResults int) ([]*Reply, error) {
+	params := url.Values{
+		"query":        {"conversation_id:" + tweetID},
+		"max_results":  {strconv.Itoa(min(max(maxResults, 10), 100))},
+		"tweet.fields": {"created_at,author_id,public_metrics,referenced_tweets"},
+		"expansions":   {"author_id"},
+		"user.fields":  {"name,username"},
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", r.client.baseURL+"/tweets/search/recent?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating reply search request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+r.client.GetBearerToken())
+
+	resp, err := r.client.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("searching replies: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, api.NewHTTPError("x", resp, "reply search failed")
+	}
+
+	var body struct {
+		Data []struct {
+			ID            string    `json:"id"`
+			Text          string    `json:"text"`
+			AuthorID      string    `json:"author_id"`
+			CreatedAt     time.Time `json:"created_at"`
+			PublicMetrics struct {
+				LikeCount int64 `json:"like_count"`
+			} `json:"public_metrics"`
+			ReferencedTweets []struct {
+				Type string `json:"type"`
+				ID   string `json:"id"`
+			} `json:"referenced_tweets"`
+		} `json:"data"`
+		Includes struct {
+			Users []struct {
+				ID       string `json:"id"`
+				Name     string `json:"name"`
+				Username string `json:"username"`
+			} `json:"users"`
+		} `json:"includes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding reply search response: %w", err)
+	}
+
+	names := make(map[string]string, len(body.Includes.Users))
+	for _, u := range body.Includes.Users {
+		names[u.ID] = u.Name
+		if names[u.ID] == "" {
+			names[u.ID] = "@" + u.Username
+		}
+	}
+	now := time.Now()
+	replies := make([]*Reply, 0, len(body.Data))
+	for _, tweet := range body.Data {
+		reply := &Reply{
+			Comment: &data.Comment{
+				ID:         tweet.ID,
+				Platform:   data.PlatformX,
+				ContentID:  tweetID,
+				AuthorID:   tweet.AuthorID,
+				AuthorName: names[tweet.AuthorID],
+				Text:       tweet.Text,
+				LikeCount:  tweet.PublicMetrics.LikeCount,
+				CreatedAt:  tweet.CreatedAt,
+				FetchedAt:  now,
+			},
+			InReplyTo: tweetID,
+		}
+		for _, ref := range tweet.ReferencedTweets {
+			if ref.Type == "replied_to" {
+				reply.InReplyTo = ref.ID
+			}
+		}
+		replies = append(replies, reply)
+	}
+	return replies, nil
+}
@@ -55,6 +55,27 @@ func (a *Analytics) GetRecentVideos(ctx context.Context, maxResults int) ([]*dat
 	return nil, nil
 }
 
+// GetUploadsPage fetches one page of the channel's uploads, newest first,
+// for walking the full upload history. It returns the token for the next
+// page, or an empty string on the last page. Each page costs 2 quota units
+// (playlistItems.list and videos.list).
+func (a *Analytics) GetUploadsPage(ctx context.Context, pageToken string, pageSize int) ([]*data.Video, string, error) {
+	// TODO: Implement using YouTube Data API v3
+	// 1. Get the uploads playlist ID from channels.list (contentDetails.relatedPlaylists.uploads)
+	// 2. Endpoint: GET https://www.googleapis.com/youtube/v3/playlistItems
+	//    Parameters:
+	//      - part: contentDetails
+	//      - playlistId: {uploads_playlist_id}
+	//      - maxResults: 1-50
+	//      - pageToken: {page_token} (omit for the first page)
+	//    Response includes nextPageToken, absent on the last page
+	// 3. Use videos.list with the page's video IDs to get full statistics
+	return nil, "", nil
+}
+
+// UploadsPageCost is the quota cost of one GetUploadsPage call.
+const UploadsPageCost = 2
+
 // GetAnalyticsReport fetches detailed analytics from YouTube Analytics API.
 // Requires OAuth 2.0 authentication with yt-analytics.readonly scope.
 func (a *Analytics) GetAnalyticsReport(ctx context.Context, startDate, endDate string, metrics []string) (interface{}, error) {
@@ -9,6 +9,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/omnipulse/omnipulse/internal/api"
 )
 
 // OAuth2Token represents an OAuth 2.0 token for YouTube API access.
@@ -82,7 +84,7 @@ func (a *Authenticator) ExchangeCode(ctx context.Context, code string) (*OAuth2T
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("token exchange failed with status: %d", resp.StatusCode)
+		return nil, api.NewHTTPError("youtube", resp, "token exchange failed")
 	}
 
 	var token OAuth2Token
@@ -118,7 +120,7 @@ func (a *Authenticator) RefreshAccessToken(ctx context.Context, refreshToken str
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("token refresh failed with status: %d", resp.StatusCode)
+		return nil, api.NewHTTPError("youtube", resp, "token refresh failed")
 	}
 
 	var token OAuth2Token
@@ -3,7 +3,14 @@ package youtube
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/omnipulse/omnipulse/internal/api"
 	"github.com/omnipulse/omnipulse/internal/data"
 )
 
@@ -67,3 +74,97 @@ func (c *Comments) GetCommentReplies(ctx context.Context, parentID string, maxRe
 	//   - key: {api_key}
 	return nil, nil
 }
+
+// CommentThread is a top-level comment with the replies YouTube returns
+// with it. commentThreads.list includes at most five replies; the rest can
+// be fetched with GetCommentReplies.
+type CommentThread struct {
+	Comment      *data.Comment
+	Replies      []*data.Comment
+	TotalReplies int64
+}
+
+// youtubeComment is a comment resource from the YouTube Data API.
+type youtubeComment struct {
+	ID      string `json:"id"`
+	Snippet struct {
+		AuthorDisplayName string `json:"authorDisplayName"`
+		AuthorChannelID   struct {
+			Value string `json:"value"`
+		} `json:"authorChannelId"`
+		TextOriginal string    `json:"textOriginal"`
+		LikeCount    int64     `json:"likeCount"`
+		PublishedAt  time.Time `json:"publishedAt"`
+	} `json:"snippet"`
+}
+
+// comment converts the resource into a comment on videoID.
+func (c *youtubeComment) comment(videoID string, fetchedAt time.Time) *data.Comment {
+	return &data.Comment{
+		ID:         c.ID,
+		Platform:   data.PlatformYouTube,
+		ContentID:  videoID,
+		AuthorID:   c.Snippet.AuthorChannelID.Value,
+		AuthorName: c.Snippet.AuthorDisplayName,
+		Text:       c.Snippet.TextOriginal,
+		LikeCount:  c.Snippet.LikeCount,
+		CreatedAt:  c.Snippet.PublishedAt,
+		FetchedAt:  fetchedAt,
+	}
+}
+
+// GetVideoCommentThreads fetches a video's most recent comment threads
+// with their replies. Costs 1 quota unit.
+// Uses YouTube Data API: commentThreads.list
+func (c *Comments) GetVideoCommentThreads(ctx context.Context, videoID string, maxResults int) ([]*CommentThread, error) {
+	params := url.Values{
+		"part":       {"snippet,replies"},
+		"videoId":    {videoID},
+		"maxResults": {strconv.Itoa(min(max(maxResults, 1), 100))},
+		"order":      {"time"},
+		"textFormat": {"plainText"},
+		"key":        {c.client.config.APIKey},
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", c.client.baseURL+"/commentThreads?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating comment threads request: %w", err)
+	}
+
+	resp, err := c.client.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("listing comment threads: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, api.NewHTTPError("youtube", resp, "comment threads request failed")
+	}
+
+	var body struct {
+		Items []struct {
+			Snippet struct {
+				TopLevelComment youtubeComment `json:"topLevelComment"`
+				TotalReplyCount int64          `json:"totalReplyCount"`
+			} `json:"snippet"`
+			Replies struct {
+				Comments []youtubeComment `json:"comments"`
+			} `json:"replies"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding comment threads response: %w", err)
+	}
+
+	now := time.Now()
+	threads := make([]*CommentThread, 0, len(body.Items))
+	for _, item := range body.Items {
+		thread := &CommentThread{
+			Comment:      item.Snippet.TopLevelComment.comment(videoID, now),
+			TotalReplies: item.Snippet.TotalReplyCount,
+		}
+		for i := range item.Replies.Comments {
+			thread.Replies = append(thread.Replies, item.Replies.Comments[i].comment(videoID, now))
+		}
+		threads = append(threads, thread)
+	}
+	return threads, nil
+}
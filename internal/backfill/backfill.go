@@ -0,0 +1,227 @@
+// Package backfill provides resumable jobs that import a platform account's
+// full content history.
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/config"
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/scheduler"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// TaskName is the scheduler task that runs pending backfill jobs.
+const TaskName = "backfill"
+
+// ErrJobActive is returned when starting a backfill for a platform that
+// already has a pending or running job.
+var ErrJobActive = errors.New("backfill already in progress")
+
+// ErrNoSource is returned when starting a backfill for a platform that is
+// not configured.
+var ErrNoSource = errors.New("platform not configured for backfill")
+
+// Page is the result of fetching and saving one page of history.
+type Page struct {
+	Next  string // Cursor for the next page, empty on the last page
+	Items int    // Items saved from this page
+	Units int    // API quota units used
+}
+
+// Source walks one platform's history, newest first.
+type Source interface {
+	// Platform returns the platform the source reads from.
+	Platform() data.Platform
+
+	// PageCost returns the quota units a single FetchPage call uses, so a
+	// run can stop before exceeding its budget.
+	PageCost() int
+
+	// Estimate returns the approximate number of items in the history, or 0
+	// if the platform does not report it.
+	Estimate(ctx context.Context) (int, error)
+
+	// FetchPage fetches the page at cursor, where the empty cursor is the
+	// first page, and saves its items.
+	FetchPage(ctx context.Context, cursor string) (*Page, error)
+}
+
+// Backfiller runs backfill jobs within a per-run quota budget. Each job's
+// cursor is checkpointed after every page, so jobs interrupted by the
+// budget, an error or a restart continue where they stopped on the next run.
+type Backfiller struct {
+	store    storage.Store
+	sources  map[data.Platform]Source
+	budget   int
+	interval time.Duration
+
+	mu sync.Mutex // Serializes Start so each platform has one active job
+}
+
+// New creates a Backfiller for the given sources.
+func New(store storage.Store, cfg config.BackfillConfig, sources ...Source) *Backfiller {
+	b := &Backfiller{
+		store:    store,
+		sources:  make(map[data.Platform]Source),
+		budget:   cfg.UnitsPerRun,
+		interval: cfg.Interval,
+	}
+	for _, src := range sources {
+		b.sources[src.Platform()] = src
+	}
+	return b
+}
+
+// Register adds the backfill task to a scheduler. It runs on start so jobs
+// interrupted by a restart resume immediately.
+func (b *Backfiller) Register(s *scheduler.Scheduler, opts scheduler.TaskOptions) {
+	opts.RunOnStart = true
+	s.AddScheduledTask(TaskName, scheduler.Every(b.interval), opts, b.Run)
+}
+
+// Platforms returns the platforms that can be backfilled.
+func (b *Backfiller) Platforms() []data.Platform {
+	var platforms []data.Platform
+	for _, p := range []data.Platform{data.PlatformYouTube, data.PlatformX, data.PlatformLinkedIn} {
+		if _, ok := b.sources[p]; ok {
+			platforms = append(platforms, p)
+		}
+	}
+	return platforms
+}
+
+// Start creates a pending backfill job for a platform. The job starts on
+// the next run of the backfill task.
+func (b *Backfiller) Start(ctx context.Context, platform data.Platform) (*data.BackfillJob, error) {
+	if _, ok := b.sources[platform]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoSource, platform)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	active, err := b.store.GetActiveBackfillJobs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting active backfill jobs: %w", err)
+	}
+	for _, job := range active {
+		if job.Platform == platform {
+			return job, fmt.Errorf("%w: %s", ErrJobActive, platform)
+		}
+	}
+
+	now := time.Now()
+	job := &data.BackfillJob{
+		Platform:  platform,
+		Status:    data.BackfillPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := b.store.CreateBackfillJob(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Run advances every pending job by up to the per-run quota budget. Jobs
+// run one after another so they share the platform rate limits fairly with
+// the regular fetch tasks.
+func (b *Backfiller) Run(ctx context.Context) error {
+	jobs, err := b.store.GetActiveBackfillJobs(ctx)
+	if err != nil {
+		return fmt.Errorf("getting active backfill jobs: %w", err)
+	}
+
+	var errs []error
+	for _, job := range jobs {
+		if err := b.runJob(ctx, job); err != nil {
+			errs = append(errs, fmt.Errorf("%s backfill: %w", job.Platform, err))
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runJob fetches pages for one job until it completes, fails or exhausts
+// the run's budget.
+func (b *Backfiller) runJob(ctx context.Context, job *data.BackfillJob) error {
+	src, ok := b.sources[job.Platform]
+	if !ok {
+		job.Status = data.BackfillFailed
+		job.Error = ErrNoSource.Error()
+		return b.checkpoint(ctx, job)
+	}
+
+	job.Status = data.BackfillRunning
+	job.Error = ""
+	if err := b.checkpoint(ctx, job); err != nil {
+		return err
+	}
+
+	if job.Pages == 0 && job.Total == 0 {
+		total, err := src.Estimate(ctx)
+		if err != nil {
+			log.Printf("estimating %s backfill size: %v", job.Platform, err)
+		}
+		job.Total = total
+	}
+
+	used := 0
+	for used+src.PageCost() <= b.budget {
+		page, err := src.FetchPage(ctx, job.Cursor)
+		if err != nil {
+			// Cancellation and retryable errors leave the job pending at its
+			// last checkpoint.
+			job.Status = data.BackfillPending
+			if ctx.Err() == nil && !scheduler.IsRetryable(err) {
+				job.Status = data.BackfillFailed
+			}
+			job.Error = err.Error()
+			if cpErr := b.checkpoint(ctx, job); cpErr != nil {
+				log.Printf("checkpointing %s backfill: %v", job.Platform, cpErr)
+			}
+			return fmt.Errorf("fetching page %d: %w", job.Pages+1, err)
+		}
+
+		used += max(page.Units, src.PageCost())
+		job.Cursor = page.Next
+		job.Pages++
+		job.Items += page.Items
+		job.UnitsUsed += page.Units
+		scheduler.ReportItems(ctx, page.Items)
+		scheduler.ReportAPIUnits(ctx, page.Units)
+
+		if page.Next == "" {
+			job.Status = data.BackfillCompleted
+			job.CompletedAt = time.Now()
+			if job.Items > job.Total {
+				job.Total = job.Items
+			}
+			return b.checkpoint(ctx, job)
+		}
+		if err := b.checkpoint(ctx, job); err != nil {
+			return err
+		}
+	}
+
+	job.Status = data.BackfillPending
+	return b.checkpoint(ctx, job)
+}
+
+// checkpoint saves a job's progress. It is not cancelled with ctx so the
+// cursor of a page that was already saved is never lost.
+func (b *Backfiller) checkpoint(ctx context.Context, job *data.BackfillJob) error {
+	job.UpdatedAt = time.Now()
+	if err := b.store.UpdateBackfillJob(context.WithoutCancel(ctx), job); err != nil {
+		return fmt.Errorf("checkpointing backfill job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Package backfill provides comment fetching for saved content, keeping
+// replies linked to the comment they answer.
+package backfill
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/omnipulse/omnipulse/internal/api/x"
+	"github.com/omnipulse/omnipulse/internal/api/youtube"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// CommentFetcher fetches and saves the comments on videos and tweets.
+type CommentFetcher struct {
+	youtube    *youtube.Comments
+	x          *x.Replies
+	store      storage.Store
+	maxResults int
+}
+
+// NewCommentFetcher creates a CommentFetcher requesting up to maxResults
+// threads or replies per content item. Either client may be nil if the
+// platform isn't configured.
+func NewCommentFetcher(youtubeComments *youtube.Comments, xReplies *x.Replies, store storage.Store, maxResults int) *CommentFetcher {
+	return &CommentFetcher{youtube: youtubeComments, x: xReplies, store: store, maxResults: maxResults}
+}
+
+// FetchVideoComments fetches and saves a video's comment threads. Replies
+// are saved under their thread's top-level comment. It returns the number
+// of comments saved.
+func (f *CommentFetcher) FetchVideoComments(ctx context.Context, videoID string) (int, error) {
+	if f.youtube == nil {
+		return 0, nil
+	}
+	threads, err := f.youtube.GetVideoCommentThreads(ctx, videoID, f.maxResults)
+	if err != nil {
+		return 0, fmt.Errorf("getting comments on video %s: %w", videoID, err)
+	}
+
+	saved := 0
+	for _, thread := range threads {
+		if err := f.store.SaveComment(ctx, thread.Comment); err != nil {
+			return saved, fmt.Errorf("saving comment %s: %w", thread.Comment.ID, err)
+		}
+		saved++
+		for _, reply := range thread.Replies {
+			if err := f.store.SaveCommentReply(ctx, thread.Comment.ID, reply); err != nil {
+				return saved, fmt.Errorf("saving reply %s: %w", reply.ID, err)
+			}
+			saved++
+		}
+	}
+	return saved, nil
+}
+
+// FetchTweetReplies fetches and saves the replies in a tweet's
+// conversation. Direct replies are saved as comments on the tweet and
+// replies to them under the reply they answer. It returns the number of
+// replies saved.
+func (f *CommentFetcher) FetchTweetReplies(ctx context.Context, tweetID string) (int, error) {
+	if f.x == nil {
+		return 0, nil
+	}
+	replies, err := f.x.GetConversationReplies(ctx, tweetID, f.maxResults)
+	if err != nil {
+		return 0, fmt.Errorf("getting replies to tweet %s: %w", tweetID, err)
+	}
+
+	saved := 0
+	for _, reply := range replies {
+		if reply.InReplyTo == tweetID {
+			err = f.store.SaveComment(ctx, reply.Comment)
+		} else {
+			err = f.store.SaveCommentReply(ctx, reply.InReplyTo, reply.Comment)
+		}
+		if err != nil {
+			return saved, fmt.Errorf("saving reply %s: %w", reply.Comment.ID, err)
+		}
+		saved++
+	}
+	return saved, nil
+}
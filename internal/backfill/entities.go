@@ -0,0 +1,41 @@
+// Package backfill provides entity extraction for content saved before
+// entities were extracted on ingest.
+package backfill
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// entityScanBatch is the number of content items read per query while
+// scanning for entities.
+const entityScanBatch = 200
+
+// ScanEntities extracts the hashtags, mentions and link domains of saved X
+// and LinkedIn content that hasn't been scanned yet, from its text. It
+// returns the number of items scanned.
+func ScanEntities(ctx context.Context, store storage.Store) (int, error) {
+	scanned := 0
+	for _, platform := range []data.Platform{data.PlatformX, data.PlatformLinkedIn} {
+		for {
+			items, err := store.ListUnscannedContent(ctx, platform, entityScanBatch)
+			if err != nil {
+				return scanned, fmt.Errorf("listing unscanned %s content: %w", platform, err)
+			}
+			for _, item := range items {
+				entities := data.ExtractEntities(platform, item.ID, item.Body)
+				if err := store.SaveContentEntities(ctx, platform, item.ID, entities); err != nil {
+					return scanned, fmt.Errorf("saving %s %s entities: %w", platform, item.ID, err)
+				}
+				scanned++
+			}
+			if len(items) < entityScanBatch {
+				break
+			}
+		}
+	}
+	return scanned, nil
+}
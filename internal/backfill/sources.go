@@ -0,0 +1,181 @@
+// Package backfill provides the platform sources for backfill jobs.
+package backfill
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/omnipulse/omnipulse/internal/api/linkedin"
+	"github.com/omnipulse/omnipulse/internal/api/x"
+	"github.com/omnipulse/omnipulse/internal/api/youtube"
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// YouTubeSource walks a channel's uploads.
+type YouTubeSource struct {
+	analytics *youtube.Analytics
+	store     storage.Store
+	pageSize  int
+}
+
+// NewYouTubeSource creates a YouTubeSource requesting pageSize videos per page.
+func NewYouTubeSource(analytics *youtube.Analytics, store storage.Store, pageSize int) *YouTubeSource {
+	return &YouTubeSource{analytics: analytics, store: store, pageSize: min(pageSize, 50)}
+}
+
+// Platform returns data.PlatformYouTube.
+func (s *YouTubeSource) Platform() data.Platform { return data.PlatformYouTube }
+
+// PageCost returns the quota cost of one uploads page.
+func (s *YouTubeSource) PageCost() int { return youtube.UploadsPageCost }
+
+// Estimate returns the channel's video count.
+func (s *YouTubeSource) Estimate(ctx context.Context) (int, error) {
+	stats, err := s.analytics.GetChannelStats(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("getting channel stats: %w", err)
+	}
+	if stats == nil {
+		return 0, nil
+	}
+	return int(stats.VideoCount), nil
+}
+
+// FetchPage fetches and saves one page of uploads.
+func (s *YouTubeSource) FetchPage(ctx context.Context, cursor string) (*Page, error) {
+	videos, next, err := s.analytics.GetUploadsPage(ctx, cursor, s.pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("getting uploads: %w", err)
+	}
+	for _, video := range videos {
+		if err := s.store.SaveVideo(ctx, video); err != nil {
+			return nil, fmt.Errorf("saving video %s: %w", video.ID, err)
+		}
+	}
+	return &Page{Next: next, Items: len(videos), Units: youtube.UploadsPageCost}, nil
+}
+
+// XSource walks a user's timeline.
+type XSource struct {
+	metrics  *x.Metrics
+	store    storage.Store
+	pageSize int
+}
+
+// NewXSource creates an XSource requesting pageSize tweets per page.
+func NewXSource(metrics *x.Metrics, store storage.Store, pageSize int) *XSource {
+	return &XSource{metrics: metrics, store: store, pageSize: min(max(pageSize, 5), 100)}
+}
+
+// Platform returns data.PlatformX.
+func (s *XSource) Platform() data.Platform { return data.PlatformX }
+
+// PageCost returns 2, for a timeline page and the lookup of its tweets'
+// entities; X budgets are counted in requests.
+func (s *XSource) PageCost() int { return 2 }
+
+// Estimate returns the user's tweet count, capped at the 3200 tweets the
+// timeline endpoint can return.
+func (s *XSource) Estimate(ctx context.Context) (int, error) {
+	stats, err := s.metrics.GetUserStats(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("getting user stats: %w", err)
+	}
+	if stats == nil {
+		return 0, nil
+	}
+	return min(int(stats.TweetCount), 3200), nil
+}
+
+// FetchPage fetches and saves one page of tweets.
+func (s *XSource) FetchPage(ctx context.Context, cursor string) (*Page, error) {
+	tweets, next, err := s.metrics.GetUserTweetsPage(ctx, cursor, s.pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("getting tweets: %w", err)
+	}
+	ids := make([]string, len(tweets))
+	for i, tweet := range tweets {
+		if err := s.store.SaveTweet(ctx, tweet); err != nil {
+			return nil, fmt.Errorf("saving tweet %s: %w", tweet.ID, err)
+		}
+		ids[i] = tweet.ID
+	}
+	if len(tweets) == 0 {
+		return &Page{Next: next, Units: 1}, nil
+	}
+
+	// Entities are looked up for expanded links, falling back to the text
+	// if the lookup fails so the page isn't fetched again.
+	entities, err := s.metrics.GetTweetEntities(ctx, ids)
+	if err != nil {
+		log.Printf("looking up tweet entities: %v", err)
+	}
+	for _, tweet := range tweets {
+		found, ok := entities[tweet.ID]
+		if !ok {
+			found = data.ExtractEntities(data.PlatformX, tweet.ID, tweet.Text)
+		}
+		if err := s.store.SaveContentEntities(ctx, data.PlatformX, tweet.ID, found); err != nil {
+			return nil, fmt.Errorf("saving tweet %s entities: %w", tweet.ID, err)
+		}
+	}
+	return &Page{Next: next, Items: len(tweets), Units: 2}, nil
+}
+
+// LinkedInSource walks a member's posts. Its cursor is the offset of the
+// next page.
+type LinkedInSource struct {
+	posts    *linkedin.Posts
+	store    storage.Store
+	pageSize int
+}
+
+// NewLinkedInSource creates a LinkedInSource requesting pageSize posts per page.
+func NewLinkedInSource(posts *linkedin.Posts, store storage.Store, pageSize int) *LinkedInSource {
+	return &LinkedInSource{posts: posts, store: store, pageSize: min(pageSize, 100)}
+}
+
+// Platform returns data.PlatformLinkedIn.
+func (s *LinkedInSource) Platform() data.Platform { return data.PlatformLinkedIn }
+
+// PageCost returns 1; LinkedIn budgets are counted in requests.
+func (s *LinkedInSource) PageCost() int { return 1 }
+
+// Estimate returns 0; LinkedIn does not report a member's post count.
+func (s *LinkedInSource) Estimate(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// FetchPage fetches and saves one page of posts.
+func (s *LinkedInSource) FetchPage(ctx context.Context, cursor string) (*Page, error) {
+	start := 0
+	if cursor != "" {
+		var err error
+		if start, err = strconv.Atoi(cursor); err != nil {
+			return nil, fmt.Errorf("invalid cursor %q: %w", cursor, err)
+		}
+	}
+
+	posts, next, err := s.posts.GetUserPostsPage(ctx, start, s.pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("getting posts: %w", err)
+	}
+	for _, post := range posts {
+		if err := s.store.SaveLinkedInPost(ctx, post); err != nil {
+			return nil, fmt.Errorf("saving post %s: %w", post.ID, err)
+		}
+		entities := data.ExtractEntities(data.PlatformLinkedIn, post.ID, post.Text)
+		if err := s.store.SaveContentEntities(ctx, data.PlatformLinkedIn, post.ID, entities); err != nil {
+			return nil, fmt.Errorf("saving post %s entities: %w", post.ID, err)
+		}
+	}
+
+	page := &Page{Items: len(posts), Units: 1}
+	if next >= 0 {
+		page.Next = strconv.Itoa(next)
+	}
+	return page, nil
+}
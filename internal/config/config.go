@@ -23,8 +23,14 @@ type Config struct {
 	// LLM settings
 	LLM LLMConfig
 
+	// Generated insight settings
+	Insights InsightsConfig
+
 	// Scheduler settings
 	Scheduler SchedulerConfig
+
+	// Historical backfill settings
+	Backfill BackfillConfig
 }
 
 // ServerConfig holds HTTP server configuration.
@@ -66,17 +72,80 @@ type LinkedInConfig struct {
 	PersonURN    string
 }
 
-// LLMConfig holds LLM (Ollama) configuration.
+// LLMConfig holds LLM configuration.
 type LLMConfig struct {
+	Backend  string // "ollama", "openai" for any OpenAI-compatible server, or "fake"
 	Endpoint string
 	Model    string
+	APIKey   string // Bearer token for OpenAI-compatible servers that require one
 	Timeout  time.Duration
+
+	// Generation defaults, overridable per request
+	Temperature  float64 // Negative uses the model's default
+	MaxTokens    int     // 0 uses the model's default
+	SystemPrompt string
+
+	// PromptTokenBudget is the approximate maximum size of a prompt. Prompt
+	// inputs are shortened until the prompt fits.
+	PromptTokenBudget int
+
+	// EmbeddingModel is the model used for text embeddings on the same
+	// backend, such as nomic-embed-text for Ollama.
+	EmbeddingModel string
+}
+
+// InsightsConfig holds configuration for generated insights.
+type InsightsConfig struct {
+	TTL time.Duration // How long insights stay on the dashboard
+
+	// SimilarityThreshold is the word overlap, from 0 to 1, above which a
+	// new insight counts as a duplicate of a recent one and isn't saved.
+	SimilarityThreshold float64
+
+	// Comment classification
+	CommentClassifier string // "llm", falling back to "lexicon" when the LLM fails, or "lexicon"
+	CommentBatchSize  int    // Comments classified per LLM request
+
+	// Topic clustering
+	TopicCount int // Maximum number of topics content and comments are grouped into
+
+	// Timezone is the account's timezone, used to bucket posts by the
+	// weekday and hour they were published.
+	Timezone *time.Location
 }
 
 // SchedulerConfig holds scheduler configuration.
 type SchedulerConfig struct {
-	FetchInterval   time.Duration
-	InsightInterval time.Duration
+	FetchInterval    time.Duration
+	InsightInterval  time.Duration
+	InsightCron      string        // Cron expression in local time; overrides InsightInterval when set
+	ClassifyInterval time.Duration // Time between comment classification runs
+	TopicInterval    time.Duration // Time between embedding and topic clustering runs
+	AnomalyInterval  time.Duration // Time between metric anomaly detection runs
+	BestTimeInterval time.Duration // Time between best-time-to-post analysis runs
+	Jitter           time.Duration // Default maximum random delay before each task run
+	TaskTimeout      time.Duration // Default limit on each task attempt
+
+	// Retry defaults for failed task runs
+	MaxAttempts     int
+	RetryBackoff    time.Duration // Delay before the first retry, doubling after each
+	RetryMaxBackoff time.Duration
+
+	// Circuit breaker settings for pausing a platform's tasks
+	BreakerThreshold int           // Consecutive failed runs before pausing
+	BreakerCooldown  time.Duration // Pause before a trial run
+
+	// Leader election between replicas sharing a database
+	InstanceID   string        // Identifies this replica in the lease
+	AdvertiseURL string        // Base URL other replicas use to forward manual runs here
+	LeaseTTL     time.Duration // Leadership lapses if not renewed within this time; 0 disables election
+}
+
+// BackfillConfig holds historical backfill configuration.
+type BackfillConfig struct {
+	Interval    time.Duration // Time between backfill runs
+	PageSize    int           // Items requested per page
+	UnitsPerRun int           // API quota units each job may use per run
 }
 
 // Load loads configuration from environment variables.
@@ -112,19 +181,83 @@ func Load() (*Config, error) {
 			PersonURN:    os.Getenv("LINKEDIN_PERSON_URN"),
 		},
 		LLM: LLMConfig{
-			Endpoint: getEnv("LLM_ENDPOINT", "http://localhost:11434"),
-			Model:    getEnv("LLM_MODEL", "llama3"),
-			Timeout:  time.Duration(getEnvInt("LLM_TIMEOUT_SECONDS", 30)) * time.Second,
+			Backend:      getEnv("LLM_BACKEND", "ollama"),
+			Endpoint:     getEnv("LLM_ENDPOINT", "http://localhost:11434"),
+			Model:        getEnv("LLM_MODEL", "llama3"),
+			APIKey:       os.Getenv("LLM_API_KEY"),
+			Timeout:      time.Duration(getEnvInt("LLM_TIMEOUT_SECONDS", 30)) * time.Second,
+			Temperature:  getEnvFloat("LLM_TEMPERATURE", -1),
+			MaxTokens:    getEnvInt("LLM_MAX_TOKENS", 0),
+			SystemPrompt: os.Getenv("LLM_SYSTEM_PROMPT"),
+
+			PromptTokenBudget: getEnvInt("LLM_PROMPT_TOKEN_BUDGET", 3000),
+			EmbeddingModel:    getEnv("LLM_EMBEDDING_MODEL", "nomic-embed-text"),
+		},
+		Insights: InsightsConfig{
+			TTL:                 time.Duration(getEnvInt("INSIGHT_TTL_HOURS", 168)) * time.Hour,
+			SimilarityThreshold: getEnvFloat("INSIGHT_SIMILARITY_THRESHOLD", 0.6),
+			CommentClassifier:   getEnv("COMMENT_CLASSIFIER", "llm"),
+			CommentBatchSize:    getEnvInt("COMMENT_BATCH_SIZE", 20),
+			TopicCount:          getEnvInt("TOPIC_COUNT", 8),
 		},
 		Scheduler: SchedulerConfig{
-			FetchInterval:   time.Duration(getEnvInt("FETCH_INTERVAL_MINUTES", 60)) * time.Minute,
-			InsightInterval: time.Duration(getEnvInt("INSIGHT_INTERVAL_HOURS", 24)) * time.Hour,
+			FetchInterval:    time.Duration(getEnvInt("FETCH_INTERVAL_MINUTES", 60)) * time.Minute,
+			InsightInterval:  time.Duration(getEnvInt("INSIGHT_INTERVAL_HOURS", 24)) * time.Hour,
+			InsightCron:      os.Getenv("INSIGHT_CRON"),
+			ClassifyInterval: time.Duration(getEnvInt("COMMENT_CLASSIFY_INTERVAL_MINUTES", 30)) * time.Minute,
+			TopicInterval:    time.Duration(getEnvInt("TOPIC_INTERVAL_HOURS", 24)) * time.Hour,
+			AnomalyInterval:  time.Duration(getEnvInt("ANOMALY_INTERVAL_HOURS", 6)) * time.Hour,
+			BestTimeInterval: time.Duration(getEnvInt("BEST_TIME_INTERVAL_HOURS", 24)) * time.Hour,
+			Jitter:           time.Duration(getEnvInt("SCHEDULER_JITTER_SECONDS", 30)) * time.Second,
+			TaskTimeout:      time.Duration(getEnvInt("TASK_TIMEOUT_MINUTES", 10)) * time.Minute,
+			MaxAttempts:      getEnvInt("TASK_MAX_ATTEMPTS", 3),
+			RetryBackoff:     time.Duration(getEnvInt("TASK_RETRY_BACKOFF_SECONDS", 10)) * time.Second,
+			RetryMaxBackoff:  time.Duration(getEnvInt("TASK_RETRY_MAX_BACKOFF_SECONDS", 300)) * time.Second,
+			BreakerThreshold: getEnvInt("CIRCUIT_BREAKER_THRESHOLD", 5),
+			BreakerCooldown:  time.Duration(getEnvInt("CIRCUIT_BREAKER_COOLDOWN_MINUTES", 30)) * time.Minute,
+			InstanceID:       getEnv("INSTANCE_ID", defaultInstanceID()),
+			LeaseTTL:         time.Duration(getEnvInt("LEADER_LEASE_TTL_SECONDS", 30)) * time.Second,
 		},
+		Backfill: BackfillConfig{
+			Interval:    time.Duration(getEnvInt("BACKFILL_INTERVAL_MINUTES", 15)) * time.Minute,
+			PageSize:    getEnvInt("BACKFILL_PAGE_SIZE", 50),
+			UnitsPerRun: getEnvInt("BACKFILL_UNITS_PER_RUN", 100),
+		},
+	}
+
+	cfg.Scheduler.AdvertiseURL = getEnv("ADVERTISE_URL",
+		fmt.Sprintf("http://%s:%d", cfg.Server.Host, cfg.Server.Port))
+
+	timezone, err := time.LoadLocation(getEnv("ACCOUNT_TIMEZONE", "Local"))
+	if err != nil {
+		return nil, fmt.Errorf("loading ACCOUNT_TIMEZONE: %w", err)
 	}
+	cfg.Insights.Timezone = timezone
 
 	return cfg, nil
 }
 
+// getEnvFloat returns the float value of an environment variable or a default value.
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		var result float64
+		if _, err := fmt.Sscanf(value, "%g", &result); err == nil {
+			return result
+		}
+	}
+	return defaultValue
+}
+
+// defaultInstanceID returns the hostname and process ID, which is unique
+// across containers and restarts.
+func defaultInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "omnipulse"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
 // getEnv returns the value of an environment variable or a default value.
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,43 @@
+// Package data provides types for detected metric anomalies.
+package data
+
+import "time"
+
+// Anomaly detection methods.
+const (
+	AnomalyMethodMAD         = "mad"         // Rolling median and median absolute deviation
+	AnomalyMethodSTL         = "stl"         // Weekly seasonal decomposition, then MAD on the remainder
+	AnomalyMethodChangePoint = "changepoint" // Sustained level shifts
+)
+
+// Anomaly types.
+const (
+	AnomalySpike      = "spike"
+	AnomalyDrop       = "drop"
+	AnomalyLevelShift = "level_shift"
+)
+
+// Anomaly is an unusual value of a platform metric on a day. For level
+// shifts, Value is the new level and Expected the level before it.
+type Anomaly struct {
+	ID          int64     `json:"id"`
+	Platform    Platform  `json:"platform"`
+	Metric      string    `json:"metric"`
+	Method      string    `json:"method"`
+	Timestamp   time.Time `json:"timestamp"`
+	Value       float64   `json:"value"`
+	Expected    float64   `json:"expected"`
+	ExpectedMin float64   `json:"expected_min"`
+	ExpectedMax float64   `json:"expected_max"`
+	Type        string    `json:"type"`     // "spike", "drop" or "level_shift"
+	Severity    string    `json:"severity"` // "low", "medium" or "high"
+	DetectedAt  time.Time `json:"detected_at"`
+}
+
+// AnomalyQuery filters stored anomalies.
+type AnomalyQuery struct {
+	Platform  Platform // Empty for all platforms
+	Metric    string   // Empty for all metrics
+	DateRange DateRange
+	Limit     int
+}
@@ -0,0 +1,63 @@
+// Package data provides types for historical backfill jobs.
+package data
+
+import "time"
+
+// BackfillStatus is the state of a backfill job.
+type BackfillStatus string
+
+// Backfill job states.
+const (
+	// BackfillPending jobs are waiting for their next run, either because
+	// they have just been started or because their quota budget ran out.
+	BackfillPending   BackfillStatus = "pending"
+	BackfillRunning   BackfillStatus = "running"
+	BackfillCompleted BackfillStatus = "completed"
+	BackfillFailed    BackfillStatus = "failed"
+)
+
+// Active reports whether a job in this state still has work to do.
+func (s BackfillStatus) Active() bool {
+	return s == BackfillPending || s == BackfillRunning
+}
+
+// BackfillJob walks a platform account's full history page by page. Its
+// cursor is checkpointed after every page so it can resume after a restart.
+type BackfillJob struct {
+	ID          int64          `json:"id"`
+	Platform    Platform       `json:"platform"`
+	Status      BackfillStatus `json:"status"`
+	Cursor      string         `json:"cursor,omitempty"` // Platform page token for the next page
+	Pages       int            `json:"pages"`
+	Items       int            `json:"items"`
+	Total       int            `json:"total,omitempty"` // Estimated total items, 0 if unknown
+	UnitsUsed   int            `json:"units_used"`
+	Error       string         `json:"error,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	CompletedAt time.Time      `json:"completed_at,omitempty"`
+}
+
+// Progress returns the fraction of items fetched, 0-1, or -1 if the total
+// is unknown.
+func (j *BackfillJob) Progress() float64 {
+	if j.Status == BackfillCompleted {
+		return 1
+	}
+	if j.Total <= 0 {
+		return -1
+	}
+	if j.Items >= j.Total {
+		return 0.99
+	}
+	return float64(j.Items) / float64(j.Total)
+}
+
+// ProgressPercent returns Progress as a percentage, or -1 if unknown.
+func (j *BackfillJob) ProgressPercent() int {
+	p := j.Progress()
+	if p < 0 {
+		return -1
+	}
+	return int(p * 100)
+}
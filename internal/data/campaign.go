@@ -0,0 +1,98 @@
+// Package data provides types for campaigns of related content across
+// platforms.
+package data
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CampaignSource is how a content item came to be in a campaign.
+type CampaignSource string
+
+// Supported campaign sources.
+const (
+	CampaignSourceManual  CampaignSource = "manual"
+	CampaignSourceLink    CampaignSource = "link"    // Shares a link with the campaign's other content
+	CampaignSourceUTM     CampaignSource = "utm"     // Links carry the same utm_campaign tag
+	CampaignSourceSimilar CampaignSource = "similar" // Text is similar to the campaign's other content
+)
+
+// Valid reports whether s is a supported campaign source.
+func (s CampaignSource) Valid() bool {
+	switch s {
+	case CampaignSourceManual, CampaignSourceLink, CampaignSourceUTM, CampaignSourceSimilar:
+		return true
+	}
+	return false
+}
+
+// Campaign groups content items published across platforms as one piece
+// of work, such as a video with the X thread and LinkedIn post promoting
+// it.
+type Campaign struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Note  string `json:"note,omitempty"`
+	Items int    `json:"items"` // Content items in the campaign
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CampaignMember is a content item's membership of a campaign.
+type CampaignMember struct {
+	CampaignID int64          `json:"campaign_id"`
+	Platform   Platform       `json:"platform"`
+	ContentID  string         `json:"content_id"`
+	Source     CampaignSource `json:"source"`
+	AddedAt    time.Time      `json:"added_at"`
+}
+
+// CampaignItem is a content item in a campaign.
+type CampaignItem struct {
+	*ContentItem
+	Source  CampaignSource `json:"source"`
+	AddedAt time.Time      `json:"added_at"`
+}
+
+// ParseContentURL returns the platform and ID of the content a link points
+// to, for links to YouTube videos, X posts and LinkedIn feed updates in the
+// forms ContentItem.URL and the platforms' share buttons produce. ok is
+// false for other links.
+func ParseContentURL(rawURL string) (platform Platform, id string, ok bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", "", false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	host = strings.TrimPrefix(host, "m.")
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	switch host {
+	case "youtube.com":
+		if segments[0] == "watch" {
+			id = u.Query().Get("v")
+		} else if len(segments) == 2 && (segments[0] == "shorts" || segments[0] == "live") {
+			id = segments[1]
+		}
+		return PlatformYouTube, id, id != ""
+	case "youtu.be":
+		return PlatformYouTube, segments[0], segments[0] != ""
+	case "x.com", "twitter.com":
+		// x.com/{user}/status/{id} or x.com/i/web/status/{id}
+		for i, s := range segments[:len(segments)-1] {
+			if s == "status" {
+				id = segments[i+1]
+			}
+		}
+		return PlatformX, id, id != ""
+	case "linkedin.com":
+		if len(segments) == 3 && segments[0] == "feed" && segments[1] == "update" {
+			id, _ = url.PathUnescape(segments[2])
+		}
+		return PlatformLinkedIn, id, strings.HasPrefix(id, "urn:li:")
+	}
+	return "", "", false
+}
@@ -0,0 +1,53 @@
+// Package data provides types for analytics chat conversations.
+package data
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Chat message roles.
+const (
+	ChatRoleUser      = "user"
+	ChatRoleAssistant = "assistant"
+)
+
+// Conversation is a chat about the analytics data.
+type Conversation struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"` // The first question, shortened
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ChatMessage is a question or answer in a conversation. Answers record
+// the queries run to produce them and the rows they cite.
+type ChatMessage struct {
+	ID             int64           `json:"id"`
+	ConversationID string          `json:"conversation_id"`
+	Role           string          `json:"role"`
+	Content        string          `json:"content"`
+	ToolCalls      []*ChatToolCall `json:"tool_calls,omitempty"`
+	Citations      []*ChatRow      `json:"citations,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// ChatToolCall is a data query the model made while answering.
+type ChatToolCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+	Rows      []*ChatRow      `json:"rows,omitempty"`
+	Error     string          `json:"error,omitempty"` // Why the query was rejected, if it was
+}
+
+// ChatRow is a row returned by a chat query, such as a content item or a
+// metric. Ref identifies it for citation, e.g. "content:youtube:abc123".
+type ChatRow struct {
+	Ref      string            `json:"ref"`
+	Kind     string            `json:"kind"` // "content", "comment", "metric" or "trend"
+	Platform Platform          `json:"platform,omitempty"`
+	Title    string            `json:"title"`
+	Date     time.Time         `json:"date,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Link     string            `json:"link,omitempty"` // Dashboard page for the row, if any
+}
@@ -0,0 +1,68 @@
+// Package data provides types for comment classification.
+package data
+
+import "time"
+
+// Comment intents.
+const (
+	IntentQuestion  = "question"
+	IntentPraise    = "praise"
+	IntentComplaint = "complaint"
+	IntentRequest   = "request"
+	IntentSpam      = "spam"
+	IntentOther     = "other"
+)
+
+// Intents lists the valid comment intents.
+var Intents = []string{IntentQuestion, IntentPraise, IntentComplaint, IntentRequest, IntentSpam, IntentOther}
+
+// ValidIntent reports whether intent is one of Intents.
+func ValidIntent(intent string) bool {
+	for _, valid := range Intents {
+		if intent == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// LanguageUnknown is the language of comments that couldn't be identified.
+const LanguageUnknown = "und"
+
+// Sources of comment labels.
+const (
+	LabelSourceLLM     = "llm"
+	LabelSourceLexicon = "lexicon"
+)
+
+// CommentLabel is the classification of a comment.
+type CommentLabel struct {
+	CommentID string    `json:"comment_id"`
+	Sentiment string    `json:"sentiment"`
+	Score     float64   `json:"score"` // -1 (most negative) to 1 (most positive)
+	Intent    string    `json:"intent"`
+	Language  string    `json:"language"` // ISO 639-1 code or LanguageUnknown
+	Source    string    `json:"source"`
+	LabeledAt time.Time `json:"labeled_at"`
+}
+
+// SentimentQuery selects comments for a sentiment trend.
+type SentimentQuery struct {
+	Platform  Platform // Empty for all platforms
+	ContentID string   // Empty for all content
+	DateRange DateRange
+}
+
+// SentimentPoint counts a day's labeled comments by sentiment.
+type SentimentPoint struct {
+	Date     time.Time `json:"date"`
+	Positive int       `json:"positive"`
+	Neutral  int       `json:"neutral"`
+	Negative int       `json:"negative"`
+	Score    float64   `json:"score"` // Mean score of the day's comments
+}
+
+// Total returns the number of labeled comments on the day.
+func (p *SentimentPoint) Total() int {
+	return p.Positive + p.Neutral + p.Negative
+}
@@ -0,0 +1,77 @@
+// Package data provides platform-independent content types.
+package data
+
+import "time"
+
+// ContentItem is a platform-independent view of a video, tweet or post,
+// used for listing, sorting and ranking content across an account.
+type ContentItem struct {
+	Platform       Platform  `json:"platform"`
+	ID             string    `json:"id"`
+	Title          string    `json:"title"`
+	Body           string    `json:"body"`
+	PublishedAt    time.Time `json:"published_at"`
+	Views          int64     `json:"views"` // Views on YouTube, impressions elsewhere
+	Likes          int64     `json:"likes"`
+	Comments       int64     `json:"comments"`
+	Shares         int64     `json:"shares"`
+	EngagementRate float64   `json:"engagement_rate"` // Engagements per view, 0-1
+}
+
+// Engagements returns the total number of likes, comments and shares.
+func (c *ContentItem) Engagements() int64 {
+	return c.Likes + c.Comments + c.Shares
+}
+
+// URL returns a link to the content on its platform.
+func (c *ContentItem) URL() string {
+	switch c.Platform {
+	case PlatformYouTube:
+		return "https://www.youtube.com/watch?v=" + c.ID
+	case PlatformX:
+		return "https://x.com/i/web/status/" + c.ID
+	case PlatformLinkedIn:
+		return "https://www.linkedin.com/feed/update/" + c.ID
+	}
+	return ""
+}
+
+// ContentSort is a field content lists can be sorted by.
+type ContentSort string
+
+// Supported content sort fields.
+const (
+	SortByDate       ContentSort = "date"
+	SortByViews      ContentSort = "views"
+	SortByEngagement ContentSort = "engagement"
+)
+
+// Valid reports whether s is a supported sort field.
+func (s ContentSort) Valid() bool {
+	switch s {
+	case SortByDate, SortByViews, SortByEngagement:
+		return true
+	}
+	return false
+}
+
+// ContentQuery filters, sorts and paginates a content listing.
+type ContentQuery struct {
+	Platform  Platform
+	DateRange DateRange // Zero value means no date filter
+	Sort      ContentSort
+	Ascending bool
+	Limit     int
+	Offset    int
+}
+
+// ContentSnapshot records a content item's metrics at a point in time.
+type ContentSnapshot struct {
+	Platform   Platform  `json:"platform"`
+	ContentID  string    `json:"content_id"`
+	Views      int64     `json:"views"`
+	Likes      int64     `json:"likes"`
+	Comments   int64     `json:"comments"`
+	Shares     int64     `json:"shares"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
@@ -0,0 +1,184 @@
+// Package data provides types for the hashtags, mentions and links in
+// content, and their extraction from text.
+package data
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// EntityType is a kind of entity found in content.
+type EntityType string
+
+// Supported entity types.
+const (
+	EntityHashtag EntityType = "hashtag"
+	EntityMention EntityType = "mention"
+	EntityDomain  EntityType = "domain" // The host of a link
+)
+
+// EntityTypes lists the supported entity types.
+var EntityTypes = []EntityType{EntityHashtag, EntityMention, EntityDomain}
+
+// Valid reports whether t is a supported entity type.
+func (t EntityType) Valid() bool {
+	switch t {
+	case EntityHashtag, EntityMention, EntityDomain:
+		return true
+	}
+	return false
+}
+
+// ContentEntity is a hashtag, mention or link in a content item.
+type ContentEntity struct {
+	Platform  Platform   `json:"platform"`
+	ContentID string     `json:"content_id"`
+	Type      EntityType `json:"type"`
+
+	// Value identifies the entity across content: a lowercased hashtag or
+	// username without the # or @, a LinkedIn member or organization URN,
+	// or a link's lowercased host without "www.".
+	Value string `json:"value"`
+	Text  string `json:"text"` // As written, or the link's full URL
+}
+
+// NewContentEntity normalizes an entity's value from its text. ok is false
+// if the text isn't a valid entity of the type, such as a link without a
+// host.
+func NewContentEntity(platform Platform, contentID string, entityType EntityType, text string) (entity ContentEntity, ok bool) {
+	entity = ContentEntity{Platform: platform, ContentID: contentID, Type: entityType, Text: text}
+	switch entityType {
+	case EntityHashtag:
+		entity.Value = strings.ToLower(strings.TrimLeft(text, "#＃"))
+	case EntityMention:
+		entity.Value = strings.ToLower(strings.TrimLeft(text, "@＠"))
+	case EntityDomain:
+		u, err := url.Parse(text)
+		if err != nil {
+			return entity, false
+		}
+		entity.Value = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	}
+	return entity, entity.Value != ""
+}
+
+var (
+	// hashtagPattern matches hashtags that aren't part of a word or URL
+	// and contain at least one non-digit, as X and LinkedIn do.
+	hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/])([#＃][\p{L}\p{N}_]*[\p{L}_][\p{L}\p{N}_]*)`)
+
+	// mentionPattern matches @usernames that aren't part of an email
+	// address.
+	mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.@])([@＠][A-Za-z0-9_]{1,30})`)
+
+	linkPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+
+	// LinkedIn's little text format marks hashtags as {hashtag|\#|tag} and
+	// mentions as @[Name](urn:li:person:id).
+	linkedInHashtagPattern = regexp.MustCompile(`\{hashtag\|\\?[#＃]\|([^}]+)\}`)
+	linkedInMentionPattern = regexp.MustCompile(`@\[([^\]]+)\]\((urn:li:[^)]+)\)`)
+)
+
+// shortLinkHosts are link shorteners whose target can't be known from the
+// text alone. X wraps every link in t.co, so the expanded URLs in X's
+// entity data are needed to attribute X links to their domains.
+var shortLinkHosts = map[string]bool{"t.co": true, "lnkd.in": true}
+
+// ExtractEntities finds the hashtags, mentions and link domains in a
+// content item's text. It is the fallback for content without platform
+// entity data, except that LinkedIn's little text markup for hashtags and
+// mentions is read where present. Each entity is returned once.
+func ExtractEntities(platform Platform, contentID, text string) []ContentEntity {
+	var entities []ContentEntity
+	seen := make(map[ContentEntity]bool)
+	add := func(entityType EntityType, value, written string) {
+		entity, ok := NewContentEntity(platform, contentID, entityType, written)
+		if value != "" {
+			entity.Value = value
+		}
+		key := ContentEntity{Type: entity.Type, Value: entity.Value}
+		if !ok || seen[key] {
+			return
+		}
+		seen[key] = true
+		entities = append(entities, entity)
+	}
+
+	if platform == PlatformLinkedIn {
+		for _, m := range linkedInHashtagPattern.FindAllStringSubmatch(text, -1) {
+			add(EntityHashtag, "", "#"+m[1])
+		}
+		for _, m := range linkedInMentionPattern.FindAllStringSubmatch(text, -1) {
+			add(EntityMention, m[2], "@"+m[1])
+		}
+		text = linkedInHashtagPattern.ReplaceAllString(text, " ")
+		text = linkedInMentionPattern.ReplaceAllString(text, " ")
+	}
+
+	// Links go first, and are then removed so that fragments like
+	// example.com/#top and @ in paths aren't taken for hashtags or mentions.
+	for _, link := range linkPattern.FindAllString(text, -1) {
+		link = strings.TrimRight(link, ".,;:!?)]}")
+		if u, err := url.Parse(link); err == nil && !shortLinkHosts[strings.ToLower(u.Hostname())] {
+			add(EntityDomain, "", link)
+		}
+	}
+	text = linkPattern.ReplaceAllString(text, " ")
+
+	for _, m := range hashtagPattern.FindAllStringSubmatch(text, -1) {
+		add(EntityHashtag, "", m[1])
+	}
+	for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		add(EntityMention, "", m[1])
+	}
+	return entities
+}
+
+// EntitySort is a field entity reports can be ranked by.
+type EntitySort string
+
+// Supported entity sort fields.
+const (
+	EntitySortEngagement EntitySort = "engagement" // Average engagement rate
+	EntitySortReach      EntitySort = "reach"      // Average views or impressions
+)
+
+// Valid reports whether s is a supported sort field.
+func (s EntitySort) Valid() bool {
+	return s == EntitySortEngagement || s == EntitySortReach
+}
+
+// EntityQuery selects the entities of one type to rank.
+type EntityQuery struct {
+	Type      EntityType
+	Platform  Platform  // Empty for all platforms
+	DateRange DateRange // Content publication dates; zero value means no filter
+	MinPosts  int       // Entities in fewer content items are left out
+	Sort      EntitySort
+	Limit     int
+}
+
+// EntityStats is the performance of the content items an entity appears
+// in.
+type EntityStats struct {
+	Type              EntityType `json:"type"`
+	Value             string     `json:"value"`
+	Text              string     `json:"text"` // One way it was written
+	Posts             int        `json:"posts"`
+	AvgEngagementRate float64    `json:"avg_engagement_rate"` // 0-1
+	AvgViews          float64    `json:"avg_views"`           // Views on YouTube, impressions elsewhere
+	TotalViews        int64      `json:"total_views"`
+}
+
+// Label returns the entity as it's usually shown: a hashtag with its #, a
+// mention as written and a domain by itself.
+func (s *EntityStats) Label() string {
+	switch s.Type {
+	case EntityHashtag:
+		return "#" + s.Value
+	case EntityMention:
+		return s.Text
+	}
+	return s.Value
+}
@@ -0,0 +1,23 @@
+// Package data provides types for metric goals.
+package data
+
+import "time"
+
+// Goal is a target for a platform metric to reach by a deadline, e.g.
+// 50,000 YouTube subscribers by December 31. Stock metrics such as
+// subscribers must reach Target, flow metrics such as views must total
+// Target between Start and Deadline, and rate metrics such as engagement
+// rate must average Target.
+type Goal struct {
+	ID       int64     `json:"id"`
+	Platform Platform  `json:"platform"`
+	Metric   string    `json:"metric"`
+	Target   float64   `json:"target"`
+	Baseline float64   `json:"baseline"` // The metric's value at Start; 0 for flows
+	Start    time.Time `json:"start"`
+	Deadline time.Time `json:"deadline"` // The last day counted towards the goal
+	Note     string    `json:"note,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
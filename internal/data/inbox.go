@@ -0,0 +1,46 @@
+// Package data provides types for the cross-platform comment inbox.
+package data
+
+// InboxComment is a comment together with its triage state and labels,
+// as shown in the comment inbox.
+type InboxComment struct {
+	Comment
+	ParentID     string          `json:"parent_id,omitempty"`
+	ContentTitle string          `json:"content_title"`
+	Read         bool            `json:"read"`
+	Archived     bool            `json:"archived"`
+	Flagged      bool            `json:"flagged"`
+	Sentiment    string          `json:"sentiment,omitempty"` // Empty until classified
+	Intent       string          `json:"intent,omitempty"`    // Empty until classified
+	Matched      bool            `json:"matched"`             // False for thread context that didn't match the filter
+	Replies      []*InboxComment `json:"replies,omitempty"`
+}
+
+// Sentiment labels assigned to comments.
+const (
+	SentimentPositive = "positive"
+	SentimentNeutral  = "neutral"
+	SentimentNegative = "negative"
+)
+
+// InboxFilter selects comments for the inbox.
+type InboxFilter struct {
+	Platform        Platform
+	ContentID       string
+	UnreadOnly      bool
+	FlaggedOnly     bool
+	QuestionsOnly   bool
+	Sentiment       string
+	Intent          string
+	IncludeArchived bool
+	Limit           int
+	Offset          int
+}
+
+// CommentStateUpdate changes a comment's triage state. Nil fields are
+// left unchanged.
+type CommentStateUpdate struct {
+	Read     *bool
+	Archived *bool
+	Flagged  *bool
+}
@@ -0,0 +1,52 @@
+// Package data provides types for the structured detail of generated insights.
+package data
+
+// Insight types, matching the insights table's CHECK constraint.
+const (
+	InsightTypeTrend          = "trend"
+	InsightTypeRecommendation = "recommendation"
+	InsightTypeAlert          = "alert"
+	InsightTypeSummary        = "summary"
+)
+
+// InsightTypes lists the valid insight types.
+var InsightTypes = []string{InsightTypeTrend, InsightTypeRecommendation, InsightTypeAlert, InsightTypeSummary}
+
+// ValidInsightType reports whether t is one of InsightTypes.
+func ValidInsightType(t string) bool {
+	for _, valid := range InsightTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// InsightEvidence is a metric an insight cites in its support.
+type InsightEvidence struct {
+	Metric    string   `json:"metric"`
+	Platform  Platform `json:"platform,omitempty"`
+	Value     float64  `json:"value"`
+	ChangePct *float64 `json:"change_pct,omitempty"` // Change from the previous period, if cited
+}
+
+// HasChange reports whether the evidence cites a change.
+func (e InsightEvidence) HasChange() bool {
+	return e.ChangePct != nil
+}
+
+// Change returns the cited change in percent, or 0 if none was cited.
+func (e InsightEvidence) Change() float64 {
+	if e.ChangePct == nil {
+		return 0
+	}
+	return *e.ChangePct
+}
+
+// InsightDetail is the structured support for an insight: the metrics it
+// is based on and the actions it suggests.
+type InsightDetail struct {
+	InsightID string            `json:"insight_id"`
+	Evidence  []InsightEvidence `json:"evidence"`
+	Actions   []string          `json:"actions"`
+}
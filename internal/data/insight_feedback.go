@@ -0,0 +1,34 @@
+// Package data provides types for feedback on generated insights.
+package data
+
+import "time"
+
+// Insight ratings.
+const (
+	RatingDown = -1
+	RatingNone = 0
+	RatingUp   = 1
+)
+
+// InsightFeedback is the team's response to an insight.
+type InsightFeedback struct {
+	InsightID string    `json:"insight_id"`
+	Rating    int       `json:"rating"` // RatingDown, RatingNone or RatingUp
+	Dismissed bool      `json:"dismissed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// InsightFeedbackUpdate changes the feedback on an insight. Nil fields are
+// left unchanged.
+type InsightFeedbackUpdate struct {
+	Rating    *int
+	Dismissed *bool
+}
+
+// InsightFilter selects insights to list.
+type InsightFilter struct {
+	Platform         Platform  // Empty for all platforms
+	Since            time.Time // Zero value means no age limit
+	IncludeDismissed bool
+	Limit            int // Zero means no limit
+}
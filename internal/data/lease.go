@@ -0,0 +1,21 @@
+// Package data provides types for leader election leases.
+package data
+
+import "time"
+
+// Lease grants one replica exclusive ownership of a named role, such as
+// running the scheduler, until it expires.
+type Lease struct {
+	Name       string    `json:"name"`
+	Holder     string    `json:"holder"`            // Instance ID of the owner
+	Address    string    `json:"address,omitempty"` // Base URL other replicas use to reach the owner
+	Token      int64     `json:"token"`             // Fencing token, incremented with each new term
+	AcquiredAt time.Time `json:"acquired_at"`
+	RenewedAt  time.Time `json:"renewed_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// HeldBy reports whether holder owns the lease at time now.
+func (l *Lease) HeldBy(holder string, now time.Time) bool {
+	return l != nil && l.Holder == holder && now.Before(l.ExpiresAt)
+}
@@ -0,0 +1,45 @@
+// Package data provides types for full-text search.
+package data
+
+import "time"
+
+// SearchKind identifies what a search result refers to.
+type SearchKind string
+
+// Searchable record kinds.
+const (
+	SearchKindContent SearchKind = "content"
+	SearchKindComment SearchKind = "comment"
+	SearchKindInsight SearchKind = "insight"
+)
+
+// Valid reports whether k is a supported search kind.
+func (k SearchKind) Valid() bool {
+	switch k {
+	case SearchKindContent, SearchKindComment, SearchKindInsight:
+		return true
+	}
+	return false
+}
+
+// SearchQuery is a full-text search request. Text supports "quoted
+// phrases" and trailing * for prefix matches; all terms must match.
+type SearchQuery struct {
+	Text     string
+	Platform Platform   // Empty searches all platforms
+	Kind     SearchKind // Empty searches all kinds
+	Limit    int
+	Offset   int
+}
+
+// SearchResult is a single full-text search match.
+type SearchResult struct {
+	Kind      SearchKind `json:"kind"`
+	Platform  Platform   `json:"platform"`
+	ID        string     `json:"id"`
+	ContentID string     `json:"content_id"` // The content a comment belongs to; equal to ID for content
+	Title     string     `json:"title"`
+	Snippet   string     `json:"snippet"` // HTML-escaped, with matched terms wrapped in <mark>
+	Date      time.Time  `json:"date"`
+	Rank      float64    `json:"rank"` // bm25 score; lower is more relevant
+}
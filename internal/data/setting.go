@@ -0,0 +1,11 @@
+// Package data provides the type for application settings.
+package data
+
+import "time"
+
+// Setting is a user-editable value stored in the settings table.
+type Setting struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
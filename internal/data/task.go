@@ -0,0 +1,38 @@
+// Package data provides types for background task run history.
+package data
+
+import "time"
+
+// TaskRunStatus is the outcome of a task run.
+type TaskRunStatus string
+
+// Task run statuses.
+const (
+	TaskRunRunning TaskRunStatus = "running"
+	TaskRunSuccess TaskRunStatus = "success"
+	TaskRunFailed  TaskRunStatus = "failed"
+	// TaskRunCancelled means the run was interrupted, such as by shutdown.
+	TaskRunCancelled TaskRunStatus = "cancelled"
+)
+
+// TaskRun records a single run of a scheduled task.
+type TaskRun struct {
+	ID           int64         `json:"id"`
+	Task         string        `json:"task"`
+	Status       TaskRunStatus `json:"status"`
+	Manual       bool          `json:"manual"` // Started with RunTaskNow rather than by schedule
+	StartedAt    time.Time     `json:"started_at"`
+	FinishedAt   time.Time     `json:"finished_at,omitempty"` // Zero while running
+	Attempts     int           `json:"attempts"`
+	Error        string        `json:"error,omitempty"`
+	ItemsFetched int           `json:"items_fetched"`
+	APIUnits     int           `json:"api_units"` // Quota units or requests used, as reported by the task
+}
+
+// Duration returns how long the run took, or has taken so far.
+func (r *TaskRun) Duration() time.Duration {
+	if r.FinishedAt.IsZero() {
+		return time.Since(r.StartedAt)
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
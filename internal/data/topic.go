@@ -0,0 +1,80 @@
+// Package data provides types for embeddings and topics.
+package data
+
+import "time"
+
+// Kinds of embedded items.
+const (
+	EmbeddingKindContent = "content"
+	EmbeddingKindComment = "comment"
+)
+
+// EmbeddingSource is a content item or comment and the text to embed for
+// it.
+type EmbeddingSource struct {
+	Kind     string   `json:"kind"`
+	Platform Platform `json:"platform"`
+	ItemID   string   `json:"item_id"`
+	Text     string   `json:"text"`
+}
+
+// Embedding is the vector of a content item or comment.
+type Embedding struct {
+	EmbeddingSource
+	Model     string    `json:"model"`
+	Vector    []float32 `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Topic is a group of content and comments with similar meaning.
+type Topic struct {
+	ID        int64          `json:"id"`
+	Label     string         `json:"label"`
+	Keywords  []string       `json:"keywords"`
+	Size      int            `json:"size"`
+	CreatedAt time.Time      `json:"created_at"`
+	Members   []*TopicMember `json:"-"` // Set when saving topics
+}
+
+// TopicMember is a content item or comment in a topic.
+type TopicMember struct {
+	Kind       string   `json:"kind"`
+	Platform   Platform `json:"platform"`
+	ItemID     string   `json:"item_id"`
+	Similarity float64  `json:"similarity"` // Cosine similarity to the topic's centre
+}
+
+// TopicExample is a representative member of a topic.
+type TopicExample struct {
+	TopicMember
+	Text string `json:"text"`
+}
+
+// TopicStats is the activity in a topic over a date range. Engagements
+// count the likes, comments and shares of the topic's content published in
+// the range plus the topic's comments posted in the range and their likes.
+type TopicStats struct {
+	Topic          *Topic          `json:"topic"`
+	Content        int             `json:"content"`  // Content items published in the range
+	Comments       int             `json:"comments"` // Comments posted in the range
+	Views          int64           `json:"views"`
+	Engagements    int64           `json:"engagements"`
+	EngagementRate float64         `json:"engagement_rate"` // Content engagements per view
+	Previous       int64           `json:"previous"`        // Engagements in the preceding range of the same length
+	Examples       []*TopicExample `json:"examples"`
+}
+
+// HasGrowth reports whether there were engagements in the previous range
+// to compare with.
+func (s *TopicStats) HasGrowth() bool {
+	return s.Previous > 0
+}
+
+// Growth returns the change in engagements from the previous range as a
+// percentage, or 0 if HasGrowth is false.
+func (s *TopicStats) Growth() float64 {
+	if s.Previous == 0 {
+		return 0
+	}
+	return float64(s.Engagements-s.Previous) / float64(s.Previous) * 100
+}
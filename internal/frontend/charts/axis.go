@@ -0,0 +1,284 @@
+// Package charts provides scales, ticks and axes shared by all chart types.
+package charts
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Plot margins, leaving room for tick labels and the legend.
+const (
+	marginTop    = 12.0
+	marginRight  = 16.0
+	marginBottom = 28.0
+	marginLeft   = 48.0
+	legendHeight = 20.0
+	yTickCount   = 5
+	xTickCount   = 6
+)
+
+// plot holds the computed plotting area and scales for a chart.
+type plot struct {
+	opts   Options
+	left   float64
+	top    float64
+	right  float64
+	bottom float64
+	minX   time.Time
+	maxX   time.Time
+	minY   float64
+	maxY   float64
+	yTicks []float64
+}
+
+// newPlot computes the plotting area and value domain for the series and
+// any bands. The Y domain always includes zero so bars and areas have a
+// baseline.
+func newPlot(series []Series, opts Options) *plot {
+	p := &plot{
+		opts:   opts,
+		left:   marginLeft,
+		top:    marginTop,
+		right:  float64(opts.Width) - marginRight,
+		bottom: float64(opts.Height) - marginBottom,
+	}
+	if opts.ShowLegend {
+		p.top += legendHeight
+	}
+
+	first := true
+	for _, s := range series {
+		for _, pt := range s.Points {
+			if first {
+				p.minX, p.maxX = pt.X, pt.X
+				p.minY, p.maxY = pt.Y, pt.Y
+				first = false
+				continue
+			}
+			if pt.X.Before(p.minX) {
+				p.minX = pt.X
+			}
+			if pt.X.After(p.maxX) {
+				p.maxX = pt.X
+			}
+			p.minY = math.Min(p.minY, pt.Y)
+			p.maxY = math.Max(p.maxY, pt.Y)
+		}
+	}
+	for _, band := range opts.Bands {
+		for _, pt := range band.Points {
+			if first {
+				p.minX, p.maxX = pt.X, pt.X
+				p.minY, p.maxY = pt.Lower, pt.Upper
+				first = false
+				continue
+			}
+			if pt.X.Before(p.minX) {
+				p.minX = pt.X
+			}
+			if pt.X.After(p.maxX) {
+				p.maxX = pt.X
+			}
+			p.minY = math.Min(p.minY, pt.Lower)
+			p.maxY = math.Max(p.maxY, pt.Upper)
+		}
+	}
+	p.minY = math.Min(p.minY, 0)
+	p.maxY = math.Max(p.maxY, 0)
+
+	p.yTicks = niceTicks(p.minY, p.maxY, yTickCount)
+	if len(p.yTicks) > 0 {
+		p.minY = p.yTicks[0]
+		p.maxY = p.yTicks[len(p.yTicks)-1]
+	}
+	return p
+}
+
+// xAt maps a timestamp to an X coordinate.
+func (p *plot) xAt(t time.Time) float64 {
+	span := p.maxX.Sub(p.minX)
+	if span <= 0 {
+		return (p.left + p.right) / 2
+	}
+	return p.left + float64(t.Sub(p.minX))/float64(span)*(p.right-p.left)
+}
+
+// yAt maps a value to a Y coordinate.
+func (p *plot) yAt(v float64) float64 {
+	span := p.maxY - p.minY
+	if span == 0 {
+		return p.bottom
+	}
+	return p.bottom - (v-p.minY)/span*(p.bottom-p.top)
+}
+
+// writeYAxis draws horizontal grid lines and Y tick labels.
+func (p *plot) writeYAxis(b *strings.Builder) {
+	b.WriteString(`<g class="y-axis" font-size="10">`)
+	for _, tick := range p.yTicks {
+		y := p.yAt(tick)
+		fmt.Fprintf(b, `<line x1="%s" x2="%s" y1="%s" y2="%s" stroke="#e5e7eb"/>`,
+			f(p.left), f(p.right), f(y), f(y))
+		fmt.Fprintf(b, `<text x="%s" y="%s" text-anchor="end" dominant-baseline="middle" fill="#6b7280">%s</text>`,
+			f(p.left-6), f(y), esc(p.opts.FormatValue(tick)))
+	}
+	b.WriteString(`</g>`)
+}
+
+// writeTimeAxis draws the X axis baseline and time tick labels.
+func (p *plot) writeTimeAxis(b *strings.Builder) {
+	b.WriteString(`<g class="x-axis" font-size="10">`)
+	fmt.Fprintf(b, `<line x1="%s" x2="%s" y1="%s" y2="%s" stroke="#9ca3af"/>`,
+		f(p.left), f(p.right), f(p.bottom), f(p.bottom))
+	for _, tick := range timeTicks(p.minX, p.maxX, xTickCount) {
+		x := p.xAt(tick)
+		fmt.Fprintf(b, `<line x1="%s" x2="%s" y1="%s" y2="%s" stroke="#9ca3af"/>`,
+			f(x), f(x), f(p.bottom), f(p.bottom+4))
+		fmt.Fprintf(b, `<text x="%s" y="%s" text-anchor="middle" fill="#6b7280">%s</text>`,
+			f(x), f(p.bottom+16), esc(tick.Format(p.opts.TimeFormat)))
+	}
+	b.WriteString(`</g>`)
+}
+
+// writeAnnotations draws a vertical marker for each annotation in range.
+func (p *plot) writeAnnotations(b *strings.Builder, xFor func(time.Time) (float64, bool)) {
+	if len(p.opts.Annotations) == 0 {
+		return
+	}
+	b.WriteString(`<g class="annotations">`)
+	for _, a := range p.opts.Annotations {
+		x, ok := xFor(a.X)
+		if !ok {
+			continue
+		}
+		color, dash := "#9ca3af", "2 3"
+		if a.Kind == AnnotationAnomaly {
+			color, dash = "#f59e0b", "4 2"
+		}
+		label := a.Label
+		if label == "" {
+			label = string(a.Kind)
+		}
+		fmt.Fprintf(b, `<g><title>%s · %s</title>`, esc(a.X.Format("Jan 2, 2006")), esc(label))
+		fmt.Fprintf(b, `<line x1="%s" x2="%s" y1="%s" y2="%s" stroke="%s" stroke-dasharray="%s"/>`,
+			f(x), f(x), f(p.top), f(p.bottom), color, dash)
+		if a.Kind == AnnotationAnomaly {
+			fmt.Fprintf(b, `<path d="M%s %s l4 7 h-8 z" fill="%s"/>`, f(x), f(p.top), color)
+		} else {
+			fmt.Fprintf(b, `<circle cx="%s" cy="%s" r="3" fill="%s"/>`, f(x), f(p.top+3), color)
+		}
+		b.WriteString(`</g>`)
+	}
+	b.WriteString(`</g>`)
+}
+
+// inTimeRange maps an annotation time to an X coordinate if it falls
+// within the plotted time span.
+func (p *plot) inTimeRange(t time.Time) (float64, bool) {
+	if t.Before(p.minX) || t.After(p.maxX) {
+		return 0, false
+	}
+	return p.xAt(t), true
+}
+
+// writeLegend draws a swatch and name for each series above the plot.
+func (p *plot) writeLegend(b *strings.Builder, series []Series) {
+	if !p.opts.ShowLegend {
+		return
+	}
+	b.WriteString(`<g class="legend" font-size="11">`)
+	x := p.left
+	for i, s := range series {
+		fmt.Fprintf(b, `<rect x="%s" y="4" width="10" height="10" rx="2" fill="%s"/>`, f(x), seriesColor(s, i))
+		fmt.Fprintf(b, `<text x="%s" y="13" fill="#374151">%s</text>`, f(x+14), esc(s.Name))
+		x += 24 + float64(len(s.Name))*6
+	}
+	b.WriteString(`</g>`)
+}
+
+// niceTicks returns evenly spaced, human-friendly tick values covering
+// [min, max], using the "nice numbers" algorithm.
+func niceTicks(min, max float64, count int) []float64 {
+	if min == max {
+		if min == 0 {
+			return []float64{0, 1}
+		}
+		min, max = min-math.Abs(min)/2, max+math.Abs(max)/2
+	}
+	span := niceNum(max-min, false)
+	step := niceNum(span/float64(count-1), true)
+	lo := math.Floor(min/step) * step
+	hi := math.Ceil(max/step) * step
+
+	var ticks []float64
+	for v := lo; v <= hi+step/2; v += step {
+		// Round away floating point drift (e.g. 0.30000000000000004).
+		ticks = append(ticks, math.Round(v/step)*step)
+	}
+	return ticks
+}
+
+// niceNum finds a "nice" number approximately equal to x, rounding if
+// round is true and taking the ceiling otherwise.
+func niceNum(x float64, round bool) float64 {
+	exp := math.Floor(math.Log10(x))
+	frac := x / math.Pow(10, exp)
+
+	var nice float64
+	if round {
+		switch {
+		case frac < 1.5:
+			nice = 1
+		case frac < 3:
+			nice = 2
+		case frac < 7:
+			nice = 5
+		default:
+			nice = 10
+		}
+	} else {
+		switch {
+		case frac <= 1:
+			nice = 1
+		case frac <= 2:
+			nice = 2
+		case frac <= 5:
+			nice = 5
+		default:
+			nice = 10
+		}
+	}
+	return nice * math.Pow(10, exp)
+}
+
+// timeTicks returns up to count ticks spread across [min, max], aligned
+// to midnight when the span is at least a few days.
+func timeTicks(min, max time.Time, count int) []time.Time {
+	span := max.Sub(min)
+	if span <= 0 {
+		return []time.Time{min}
+	}
+
+	step := span / time.Duration(count-1)
+	if span >= 72*time.Hour {
+		days := int(math.Ceil(step.Hours() / 24))
+		step = time.Duration(days) * 24 * time.Hour
+		start := time.Date(min.Year(), min.Month(), min.Day(), 0, 0, 0, 0, min.Location())
+		if start.Before(min) {
+			start = start.AddDate(0, 0, 1)
+		}
+		var ticks []time.Time
+		for t := start; !t.After(max); t = t.Add(step) {
+			ticks = append(ticks, t)
+		}
+		return ticks
+	}
+
+	ticks := make([]time.Time, 0, count)
+	for i := 0; i < count; i++ {
+		ticks = append(ticks, min.Add(step*time.Duration(i)))
+	}
+	return ticks
+}
@@ -0,0 +1,98 @@
+// Package charts provides bar chart rendering.
+package charts
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// Bar renders one or more series as a grouped bar chart.
+//
+// Categories are taken from the first series, in order; the remaining
+// series are expected to share the same categories. A point's Label is
+// used as its category name, falling back to its formatted timestamp.
+func Bar(series []Series, opts Options) template.HTML {
+	opts = opts.withDefaults()
+	if !hasPoints(series) {
+		return emptyChart(opts)
+	}
+
+	p := newPlot(series, opts)
+	categories := series[0].Points
+	band := (p.right - p.left) / float64(len(categories))
+	groupWidth := band * 0.8
+	barWidth := groupWidth / float64(len(series))
+
+	var b strings.Builder
+	openSVG(&b, opts)
+	p.writeLegend(&b, series)
+	p.writeYAxis(&b)
+
+	// Category axis
+	b.WriteString(`<g class="x-axis" font-size="10">`)
+	fmt.Fprintf(&b, `<line x1="%s" x2="%s" y1="%s" y2="%s" stroke="#9ca3af"/>`,
+		f(p.left), f(p.right), f(p.yAt(0)), f(p.yAt(0)))
+	labelEvery := len(categories)/xTickCount + 1
+	for i, c := range categories {
+		if i%labelEvery != 0 {
+			continue
+		}
+		fmt.Fprintf(&b, `<text x="%s" y="%s" text-anchor="middle" fill="#6b7280">%s</text>`,
+			f(p.left+band*(float64(i)+0.5)), f(p.bottom+16), esc(categoryLabel(c, opts)))
+	}
+	b.WriteString(`</g>`)
+
+	// Annotations snap to the closest category.
+	p.writeAnnotations(&b, func(t time.Time) (float64, bool) {
+		best, bestDist := -1, time.Duration(0)
+		for i, c := range categories {
+			d := c.X.Sub(t)
+			if d < 0 {
+				d = -d
+			}
+			if best < 0 || d < bestDist {
+				best, bestDist = i, d
+			}
+		}
+		if best < 0 || bestDist > 24*time.Hour {
+			return 0, false
+		}
+		return p.left + band*(float64(best)+0.5), true
+	})
+
+	zero := p.yAt(0)
+	for si, s := range series {
+		color := seriesColor(s, si)
+		b.WriteString(`<g class="series">`)
+		for i, pt := range s.Points {
+			if i >= len(categories) {
+				break
+			}
+			x := p.left + band*float64(i) + (band-groupWidth)/2 + barWidth*float64(si)
+			y := p.yAt(pt.Y)
+			top, height := y, zero-y
+			if height < 0 {
+				top, height = zero, -height
+			}
+			if pt.Label == "" {
+				pt.Label = categoryLabel(categories[i], opts)
+			}
+			fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s" rx="1" fill="%s"><title>%s</title></rect>`,
+				f(x), f(top), f(barWidth-1), f(height), color, esc(tooltip(s, pt, opts)))
+		}
+		b.WriteString(`</g>`)
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// categoryLabel returns the display name of a bar category.
+func categoryLabel(pt Point, opts Options) string {
+	if pt.Label != "" {
+		return pt.Label
+	}
+	return pt.X.Format(opts.TimeFormat)
+}
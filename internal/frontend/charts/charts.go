@@ -0,0 +1,210 @@
+// Package charts renders server-side SVG charts for the HTMX frontend.
+//
+// Charts are returned as template.HTML so they can be embedded directly in
+// page templates without any client-side charting library.
+package charts
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// Default chart dimensions (in SVG user units).
+const (
+	DefaultWidth  = 640
+	DefaultHeight = 240
+)
+
+// Palette is the default set of series colors, used in order when a
+// series does not specify its own color.
+var Palette = []string{
+	"#3b82f6", // blue-500
+	"#ef4444", // red-500
+	"#10b981", // green-500
+	"#8b5cf6", // purple-500
+	"#f59e0b", // amber-500
+	"#6b7280", // gray-500
+}
+
+// PlatformColors maps each platform to the color used on its cards.
+var PlatformColors = map[data.Platform]string{
+	data.PlatformYouTube:  "#dc2626", // red-600
+	data.PlatformX:        "#1f2937", // gray-800
+	data.PlatformLinkedIn: "#1d4ed8", // blue-700
+}
+
+// Point is a single value on a chart.
+type Point struct {
+	X     time.Time
+	Y     float64
+	Label string // Optional; used as the bar category and in tooltips
+}
+
+// Series is a named sequence of points drawn in a single color.
+type Series struct {
+	Name   string
+	Color  string
+	Points []Point
+	Dashed bool // Drawn as a dashed line without fill, e.g. for projections
+}
+
+// BandPoint is the lower and upper bound of a band at a point in time.
+type BandPoint struct {
+	X     time.Time
+	Lower float64
+	Upper float64
+}
+
+// Band is a shaded range over time, such as a forecast's prediction
+// interval, drawn behind the series.
+type Band struct {
+	Name   string
+	Color  string
+	Points []BandPoint
+}
+
+// AnnotationKind identifies what an annotation marks on a chart.
+type AnnotationKind string
+
+// Supported annotation kinds.
+const (
+	AnnotationAnomaly AnnotationKind = "anomaly"
+	AnnotationPublish AnnotationKind = "publish"
+)
+
+// Annotation marks a point in time on a chart, such as a detected anomaly
+// or the publication of a piece of content.
+type Annotation struct {
+	X     time.Time
+	Kind  AnnotationKind
+	Label string
+}
+
+// Options controls how a chart is rendered.
+type Options struct {
+	Width       int
+	Height      int
+	Title       string
+	ShowLegend  bool
+	Annotations []Annotation
+	Bands       []Band // Drawn on line and area charts
+
+	// FormatValue formats Y values for ticks and tooltips.
+	// Defaults to FormatCompact.
+	FormatValue func(float64) string
+
+	// TimeFormat is the layout used for X axis labels. Defaults to "Jan 2".
+	TimeFormat string
+}
+
+// withDefaults returns a copy of the options with zero values filled in.
+func (o Options) withDefaults() Options {
+	if o.Width <= 0 {
+		o.Width = DefaultWidth
+	}
+	if o.Height <= 0 {
+		o.Height = DefaultHeight
+	}
+	if o.FormatValue == nil {
+		o.FormatValue = FormatCompact
+	}
+	if o.TimeFormat == "" {
+		o.TimeFormat = "Jan 2"
+	}
+	return o
+}
+
+// FromTrend converts trend data into a chart series.
+func FromTrend(trend *data.TrendData, name, color string) Series {
+	s := Series{Name: name, Color: color}
+	if trend == nil {
+		return s
+	}
+	s.Points = make([]Point, len(trend.Points))
+	for i, p := range trend.Points {
+		s.Points[i] = Point{X: p.Timestamp, Y: p.Value}
+	}
+	return s
+}
+
+// FormatCompact formats a number using k/M/B suffixes (e.g. 12.3k).
+func FormatCompact(v float64) string {
+	abs := math.Abs(v)
+	switch {
+	case abs >= 1e9:
+		return trimZero(fmt.Sprintf("%.1f", v/1e9)) + "B"
+	case abs >= 1e6:
+		return trimZero(fmt.Sprintf("%.1f", v/1e6)) + "M"
+	case abs >= 1e3:
+		return trimZero(fmt.Sprintf("%.1f", v/1e3)) + "k"
+	case abs == math.Trunc(abs):
+		return fmt.Sprintf("%.0f", v)
+	default:
+		return trimZero(fmt.Sprintf("%.2f", v))
+	}
+}
+
+// trimZero removes a trailing ".0" or ".00" from a formatted number.
+func trimZero(s string) string {
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	return s
+}
+
+// seriesColor returns the series color or a palette color by index.
+func seriesColor(s Series, i int) string {
+	if s.Color != "" {
+		return s.Color
+	}
+	return Palette[i%len(Palette)]
+}
+
+// hasPoints reports whether any series has at least one point.
+func hasPoints(series []Series) bool {
+	for _, s := range series {
+		if len(s.Points) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// emptyChart renders a placeholder for charts without data.
+func emptyChart(opts Options) template.HTML {
+	var b strings.Builder
+	openSVG(&b, opts)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle" class="fill-gray-400" font-size="12">No data available</text>`,
+		opts.Width/2, opts.Height/2)
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// openSVG writes the root svg element, including an accessible title.
+func openSVG(b *strings.Builder, opts Options) {
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" class="w-full h-auto" role="img" font-family="ui-sans-serif, system-ui, sans-serif"`,
+		opts.Width, opts.Height)
+	if opts.Title != "" {
+		fmt.Fprintf(b, ` aria-label="%s"`, esc(opts.Title))
+	}
+	b.WriteString(`>`)
+	if opts.Title != "" {
+		fmt.Fprintf(b, `<title>%s</title>`, esc(opts.Title))
+	}
+}
+
+// esc escapes text for use in SVG content and attributes.
+func esc(s string) string {
+	return template.HTMLEscapeString(s)
+}
+
+// f formats a coordinate with limited precision to keep markup small.
+func f(v float64) string {
+	return trimZero(fmt.Sprintf("%.1f", v))
+}
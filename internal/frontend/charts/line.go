@@ -0,0 +1,129 @@
+// Package charts provides line and area chart rendering.
+package charts
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+// Line renders one or more series as a line chart over time.
+func Line(series []Series, opts Options) template.HTML {
+	return renderTimeSeries(series, opts.withDefaults(), false)
+}
+
+// Area renders one or more series as a filled area chart over time.
+// Areas are drawn semi-transparent so overlapping series remain visible.
+func Area(series []Series, opts Options) template.HTML {
+	return renderTimeSeries(series, opts.withDefaults(), true)
+}
+
+// renderTimeSeries draws line or area charts sharing a time X axis.
+func renderTimeSeries(series []Series, opts Options, fill bool) template.HTML {
+	if !hasPoints(series) {
+		return emptyChart(opts)
+	}
+
+	p := newPlot(series, opts)
+
+	var b strings.Builder
+	openSVG(&b, opts)
+	p.writeLegend(&b, series)
+	p.writeYAxis(&b)
+	p.writeTimeAxis(&b)
+	p.writeAnnotations(&b, p.inTimeRange)
+	for i, band := range opts.Bands {
+		writeBand(&b, p, band, i)
+	}
+
+	for i, s := range series {
+		if len(s.Points) == 0 {
+			continue
+		}
+		color := seriesColor(s, i)
+
+		points := make([]Point, len(s.Points))
+		copy(points, s.Points)
+		sort.Slice(points, func(a, b int) bool { return points[a].X.Before(points[b].X) })
+
+		var path strings.Builder
+		for j, pt := range points {
+			cmd := "L"
+			if j == 0 {
+				cmd = "M"
+			}
+			fmt.Fprintf(&path, "%s%s %s ", cmd, f(p.xAt(pt.X)), f(p.yAt(pt.Y)))
+		}
+
+		dash := ""
+		if s.Dashed {
+			dash = ` stroke-dasharray="6 4"`
+		}
+
+		fmt.Fprintf(&b, `<g class="series">`)
+		if fill && !s.Dashed {
+			baseline := f(p.yAt(0))
+			fmt.Fprintf(&b, `<path d="%sL%s %s L%s %s Z" fill="%s" fill-opacity="0.15" stroke="none"/>`,
+				path.String(), f(p.xAt(points[len(points)-1].X)), baseline, f(p.xAt(points[0].X)), baseline, color)
+		}
+		fmt.Fprintf(&b, `<path d="%s" fill="none" stroke="%s" stroke-width="2" stroke-linejoin="round" stroke-linecap="round"%s/>`,
+			strings.TrimSpace(path.String()), color, dash)
+
+		// Markers double as hover targets for the native tooltip.
+		for _, pt := range points {
+			fmt.Fprintf(&b, `<circle cx="%s" cy="%s" r="3" fill="%s"><title>%s</title></circle>`,
+				f(p.xAt(pt.X)), f(p.yAt(pt.Y)), color, esc(tooltip(s, pt, opts)))
+		}
+		b.WriteString(`</g>`)
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// writeBand draws a band as a translucent polygon along its upper bound
+// and back along its lower bound.
+func writeBand(b *strings.Builder, p *plot, band Band, i int) {
+	if len(band.Points) == 0 {
+		return
+	}
+	points := make([]BandPoint, len(band.Points))
+	copy(points, band.Points)
+	sort.Slice(points, func(a, b int) bool { return points[a].X.Before(points[b].X) })
+
+	color := band.Color
+	if color == "" {
+		color = Palette[i%len(Palette)]
+	}
+
+	var path strings.Builder
+	for j, pt := range points {
+		cmd := "L"
+		if j == 0 {
+			cmd = "M"
+		}
+		fmt.Fprintf(&path, "%s%s %s ", cmd, f(p.xAt(pt.X)), f(p.yAt(pt.Upper)))
+	}
+	for j := len(points) - 1; j >= 0; j-- {
+		fmt.Fprintf(&path, "L%s %s ", f(p.xAt(points[j].X)), f(p.yAt(points[j].Lower)))
+	}
+
+	fmt.Fprintf(b, `<path class="band" d="%sZ" fill="%s" fill-opacity="0.12" stroke="none">`, path.String(), color)
+	if band.Name != "" {
+		fmt.Fprintf(b, `<title>%s</title>`, esc(band.Name))
+	}
+	b.WriteString(`</path>`)
+}
+
+// tooltip builds the hover text for a single point.
+func tooltip(s Series, pt Point, opts Options) string {
+	label := pt.Label
+	if label == "" {
+		label = pt.X.Format("Jan 2, 2006")
+	}
+	if s.Name != "" {
+		return fmt.Sprintf("%s · %s: %s", s.Name, label, opts.FormatValue(pt.Y))
+	}
+	return fmt.Sprintf("%s: %s", label, opts.FormatValue(pt.Y))
+}
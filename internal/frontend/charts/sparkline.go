@@ -0,0 +1,103 @@
+// Package charts provides compact sparkline rendering for summary cards.
+package charts
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"sort"
+	"strings"
+)
+
+// SparklineOptions controls how a sparkline is rendered.
+type SparklineOptions struct {
+	Width  int
+	Height int
+	Color  string
+	Fill   bool
+	Title  string
+}
+
+// Sparkline renders a small axis-less line showing the shape of a series.
+// The last point is highlighted and the tooltip summarizes the range.
+func Sparkline(points []Point, opts SparklineOptions) template.HTML {
+	if opts.Width <= 0 {
+		opts.Width = 120
+	}
+	if opts.Height <= 0 {
+		opts.Height = 32
+	}
+	if opts.Color == "" {
+		opts.Color = Palette[0]
+	}
+	if len(points) < 2 {
+		return ""
+	}
+
+	sorted := make([]Point, len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a].X.Before(sorted[b].X) })
+
+	minY, maxY := sorted[0].Y, sorted[0].Y
+	for _, pt := range sorted {
+		minY = math.Min(minY, pt.Y)
+		maxY = math.Max(maxY, pt.Y)
+	}
+
+	const pad = 2.0
+	w, h := float64(opts.Width)-2*pad, float64(opts.Height)-2*pad
+	span := sorted[len(sorted)-1].X.Sub(sorted[0].X)
+	xAt := func(i int) float64 {
+		if span <= 0 {
+			return pad + w*float64(i)/float64(len(sorted)-1)
+		}
+		return pad + float64(sorted[i].X.Sub(sorted[0].X))/float64(span)*w
+	}
+	yAt := func(v float64) float64 {
+		if maxY == minY {
+			return pad + h/2
+		}
+		return pad + h - (v-minY)/(maxY-minY)*h
+	}
+
+	var line strings.Builder
+	for i, pt := range sorted {
+		if i > 0 {
+			line.WriteString(" ")
+		}
+		fmt.Fprintf(&line, "%s,%s", f(xAt(i)), f(yAt(pt.Y)))
+	}
+
+	last := sorted[len(sorted)-1]
+	summary := fmt.Sprintf("Low %s · High %s · Latest %s",
+		FormatCompact(minY), FormatCompact(maxY), FormatCompact(last.Y))
+	if opts.Title != "" {
+		summary = opts.Title + ": " + summary
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" role="img" aria-label="%s">`,
+		opts.Width, opts.Height, opts.Width, opts.Height, esc(summary))
+	fmt.Fprintf(&b, `<title>%s</title>`, esc(summary))
+	if opts.Fill {
+		fmt.Fprintf(&b, `<polygon points="%s,%s %s %s,%s" fill="%s" fill-opacity="0.15"/>`,
+			f(xAt(0)), f(pad+h), line.String(), f(xAt(len(sorted)-1)), f(pad+h), opts.Color)
+	}
+	fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5" stroke-linejoin="round" stroke-linecap="round"/>`,
+		line.String(), opts.Color)
+	fmt.Fprintf(&b, `<circle cx="%s" cy="%s" r="2" fill="%s"/>`, f(xAt(len(sorted)-1)), f(yAt(last.Y)), opts.Color)
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// SparklineFromSeries renders a series as a sparkline, using its name and
+// color unless the options override them.
+func SparklineFromSeries(s Series, opts SparklineOptions) template.HTML {
+	if opts.Color == "" {
+		opts.Color = s.Color
+	}
+	if opts.Title == "" {
+		opts.Title = s.Name
+	}
+	return Sparkline(s.Points, opts)
+}
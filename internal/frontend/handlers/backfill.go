@@ -0,0 +1,163 @@
+// Package handlers provides HTTP handlers for historical backfill jobs.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/omnipulse/omnipulse/internal/backfill"
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/scheduler"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// backfillJobsLimit is the number of backfill jobs shown on the status page.
+const backfillJobsLimit = 20
+
+// BackfillHandler handles backfill job requests.
+type BackfillHandler struct {
+	backfiller *backfill.Backfiller
+	scheduler  *scheduler.Scheduler
+	store      storage.Store
+	templates  *template.Template
+}
+
+// NewBackfillHandler creates a new BackfillHandler. The scheduler may be
+// nil, in which case started jobs wait for the next scheduled run.
+func NewBackfillHandler(backfiller *backfill.Backfiller, sched *scheduler.Scheduler, store storage.Store, templates *template.Template) *BackfillHandler {
+	return &BackfillHandler{
+		backfiller: backfiller,
+		scheduler:  sched,
+		store:      store,
+		templates:  templates,
+	}
+}
+
+// Jobs handles HTMX requests for the backfill jobs table.
+func (h *BackfillHandler) Jobs(w http.ResponseWriter, r *http.Request) {
+	view, err := h.loadView(r.Context())
+	if err != nil {
+		log.Printf("error getting backfill jobs: %v", err)
+		http.Error(w, "Failed to get backfill jobs", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "backfill_jobs", view); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// Start handles HTMX requests to start a platform backfill and returns the
+// refreshed jobs table.
+func (h *BackfillHandler) Start(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.start(r.Context(), data.Platform(r.PathValue("platform"))); err != nil {
+		switch {
+		case errors.Is(err, backfill.ErrNoSource):
+			http.NotFound(w, r)
+			return
+		case errors.Is(err, backfill.ErrJobActive):
+			// Already running; show its progress.
+		default:
+			log.Printf("error starting backfill: %v", err)
+			http.Error(w, "Failed to start backfill", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	h.Jobs(w, r)
+}
+
+// APIJobs serves backfill jobs as JSON at /api/v1/backfill.
+func (h *BackfillHandler) APIJobs(w http.ResponseWriter, r *http.Request) {
+	view, err := h.loadView(r.Context())
+	if err != nil {
+		log.Printf("error getting backfill jobs: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to get backfill jobs")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, view)
+}
+
+// APIStart starts a platform backfill at /api/v1/backfill/{platform}.
+func (h *BackfillHandler) APIStart(w http.ResponseWriter, r *http.Request) {
+	job, err := h.start(r.Context(), data.Platform(r.PathValue("platform")))
+	if err != nil {
+		switch {
+		case errors.Is(err, backfill.ErrNoSource):
+			writeJSONError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, backfill.ErrJobActive):
+			writeJSONError(w, http.StatusConflict, err.Error())
+		default:
+			log.Printf("error starting backfill: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, "failed to start backfill")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// start creates a backfill job and triggers the backfill task so it begins
+// without waiting for the next scheduled run.
+func (h *BackfillHandler) start(ctx context.Context, platform data.Platform) (*data.BackfillJob, error) {
+	if h.backfiller == nil {
+		return nil, backfill.ErrNoSource
+	}
+
+	job, err := h.backfiller.Start(ctx, platform)
+	if err != nil {
+		return job, err
+	}
+
+	if h.scheduler != nil {
+		err := h.scheduler.TriggerTask(backfill.TaskName)
+		if errors.Is(err, scheduler.ErrNotLeader) {
+			err = forwardTaskRun(ctx, h.scheduler.LeaderStatus(), backfill.TaskName)
+		}
+		if err != nil {
+			log.Printf("error triggering backfill: %v", err)
+		}
+	}
+	return job, nil
+}
+
+// backfillView is the backfill state shown on the status page and returned
+// by the backfill API.
+type backfillView struct {
+	Platforms []data.Platform     `json:"platforms"`
+	Jobs      []*data.BackfillJob `json:"jobs"`
+}
+
+// Active reports whether any job is pending or running, so the table keeps
+// polling for progress.
+func (v *backfillView) Active() bool {
+	for _, job := range v.Jobs {
+		if job.Status.Active() {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *BackfillHandler) loadView(ctx context.Context) (*backfillView, error) {
+	jobs, err := h.store.GetBackfillJobs(ctx, backfillJobsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &backfillView{
+		Platforms: []data.Platform{},
+		Jobs:      jobs,
+	}
+	if view.Jobs == nil {
+		view.Jobs = []*data.BackfillJob{}
+	}
+	if h.backfiller != nil {
+		view.Platforms = h.backfiller.Platforms()
+	}
+	return view, nil
+}
@@ -0,0 +1,109 @@
+// Package handlers provides HTTP handlers for best-time-to-post analysis.
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/insights"
+)
+
+// BestTimeHandler serves engagement by the weekday and hour posts were
+// published, for the selected date range.
+type BestTimeHandler struct {
+	analyzer  *insights.PostTimeAnalyzer
+	templates *template.Template
+}
+
+// NewBestTimeHandler creates a new BestTimeHandler.
+func NewBestTimeHandler(analyzer *insights.PostTimeAnalyzer, templates *template.Template) *BestTimeHandler {
+	return &BestTimeHandler{
+		analyzer:  analyzer,
+		templates: templates,
+	}
+}
+
+// analyze analyzes the posts published in the selected date range on
+// platform, or on every platform if it is empty.
+func (h *BestTimeHandler) analyze(r *http.Request, platform data.Platform) ([]*insights.PostTimeAnalysis, error) {
+	platforms := []data.Platform{data.PlatformYouTube, data.PlatformX, data.PlatformLinkedIn}
+	if platform != "" {
+		platforms = []data.Platform{platform}
+	}
+	dateRange := periodFrom(r).Current
+	var analyses []*insights.PostTimeAnalysis
+	for _, p := range platforms {
+		analysis, err := h.analyzer.Analyze(r.Context(), p, dateRange)
+		if err != nil {
+			return nil, err
+		}
+		analyses = append(analyses, analysis)
+	}
+	return analyses, nil
+}
+
+// Index serves the best time to post page.
+func (h *BestTimeHandler) Index(w http.ResponseWriter, r *http.Request) {
+	isHTMX := r.Header.Get("HX-Request") == "true"
+
+	analyses, err := h.analyze(r, "")
+	if err != nil {
+		log.Printf("error analyzing post times: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	templateName := "base"
+	if isHTMX {
+		templateName = "best_time"
+	}
+
+	if err := h.templates.ExecuteTemplate(w, templateName, map[string]interface{}{
+		"Page":     "best_time",
+		"Title":    "Best Time to Post",
+		"Analyses": analyses,
+		"Range":    dateRangeFrom(r),
+	}); err != nil {
+		log.Printf("error rendering template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// SaveInsights saves each platform's recommended posting windows over the
+// last six months as an insight.
+func (h *BestTimeHandler) SaveInsights(w http.ResponseWriter, r *http.Request) {
+	if err := h.analyzer.Run(r.Context()); err != nil {
+		log.Printf("error saving best time insights: %v", err)
+		http.Error(w, "Failed to save insights", http.StatusInternalServerError)
+		return
+	}
+	if err := h.templates.ExecuteTemplate(w, "best_time_saved", nil); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// API returns engagement by weekday and hour for the selected date range
+// as JSON, optionally for only the platform parameter.
+func (h *BestTimeHandler) API(w http.ResponseWriter, r *http.Request) {
+	platform := data.Platform(r.URL.Query().Get("platform"))
+	if platform != "" && !validPlatform(platform) {
+		writeJSONError(w, http.StatusBadRequest, "invalid platform")
+		return
+	}
+
+	analyses, err := h.analyze(r, platform)
+	if err != nil {
+		log.Printf("error analyzing post times: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to analyze post times")
+		return
+	}
+
+	dateRange := periodFrom(r).Current
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"start":     dateRange.Start,
+		"end":       dateRange.End,
+		"platforms": analyses,
+	})
+}
@@ -0,0 +1,322 @@
+// Package handlers provides HTTP handlers for campaigns of related content
+// across platforms.
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/insights"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// CampaignsHandler serves campaigns, their combined performance and
+// suggestions for new ones.
+type CampaignsHandler struct {
+	store      storage.Store
+	aggregator *insights.Aggregator
+	templates  *template.Template
+}
+
+// NewCampaignsHandler creates a new CampaignsHandler.
+func NewCampaignsHandler(store storage.Store, aggregator *insights.Aggregator, templates *template.Template) *CampaignsHandler {
+	return &CampaignsHandler{
+		store:      store,
+		aggregator: aggregator,
+		templates:  templates,
+	}
+}
+
+// Index serves the campaigns page, with suggestions from content published
+// in the selected date range.
+func (h *CampaignsHandler) Index(w http.ResponseWriter, r *http.Request) {
+	templateName := "base"
+	if r.Header.Get("HX-Request") == "true" {
+		templateName = "campaigns"
+	}
+	h.renderIndex(w, r, templateName, dateRangeFrom(r))
+}
+
+// renderIndex renders the campaigns page with the campaigns and the
+// suggestions for selection.
+func (h *CampaignsHandler) renderIndex(w http.ResponseWriter, r *http.Request, templateName string, selection DateRange) {
+	reports, err := h.aggregator.ListCampaignReports(r.Context())
+	if err != nil {
+		log.Printf("error listing campaigns: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	suggestions, err := h.aggregator.SuggestCampaigns(r.Context(), selection.Period().Current)
+	if err != nil {
+		log.Printf("error suggesting campaigns: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.templates.ExecuteTemplate(w, templateName, map[string]interface{}{
+		"Page":        "campaigns",
+		"Title":       "Campaigns",
+		"Campaigns":   reports,
+		"Suggestions": suggestions,
+		"Range":       selection,
+	}); err != nil {
+		log.Printf("error rendering template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// Detail serves the page for the campaign named in the request path.
+func (h *CampaignsHandler) Detail(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+
+	templateName := "base"
+	if r.Header.Get("HX-Request") == "true" {
+		templateName = "campaign"
+	}
+	h.renderCampaign(w, r, templateName, report)
+}
+
+// renderCampaign renders a campaign's page.
+func (h *CampaignsHandler) renderCampaign(w http.ResponseWriter, r *http.Request, templateName string, report *insights.CampaignReport) {
+	if err := h.templates.ExecuteTemplate(w, templateName, map[string]interface{}{
+		"Page":   "campaign",
+		"Title":  report.Campaign.Name,
+		"Report": report,
+		"Range":  dateRangeFrom(r),
+	}); err != nil {
+		log.Printf("error rendering template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// report gets the report for the campaign named in the request path. If
+// the campaign doesn't exist or can't be read it writes an error response
+// and returns false.
+func (h *CampaignsHandler) report(w http.ResponseWriter, r *http.Request) (*insights.CampaignReport, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Campaign not found", http.StatusNotFound)
+		return nil, false
+	}
+	report, err := h.aggregator.GetCampaignReport(r.Context(), id)
+	if err != nil {
+		log.Printf("error getting campaign: %v", err)
+		http.Error(w, "Failed to get campaign", http.StatusInternalServerError)
+		return nil, false
+	}
+	if report == nil {
+		http.Error(w, "Campaign not found", http.StatusNotFound)
+		return nil, false
+	}
+	return report, true
+}
+
+// Create saves a campaign from the name and note form values, with the
+// content in its item values ("platform:id") added from the source form
+// value, and shows the new campaign's page.
+func (h *CampaignsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	source := data.CampaignSource(r.FormValue("source"))
+	if source == "" {
+		source = data.CampaignSourceManual
+	}
+	campaign := &data.Campaign{
+		Name: r.FormValue("name"),
+		Note: strings.TrimSpace(r.FormValue("note")),
+	}
+	var members []*data.CampaignMember
+	for _, item := range r.Form["item"] {
+		platform, id, ok := strings.Cut(item, ":")
+		if !ok || !validPlatform(data.Platform(platform)) {
+			http.Error(w, "Invalid content item", http.StatusBadRequest)
+			return
+		}
+		members = append(members, &data.CampaignMember{
+			Platform:  data.Platform(platform),
+			ContentID: id,
+			Source:    source,
+		})
+	}
+
+	if err := h.aggregator.CreateCampaign(r.Context(), campaign, members); err != nil {
+		if errors.Is(err, insights.ErrInvalidCampaign) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("error creating campaign: %v", err)
+		http.Error(w, "Failed to create campaign", http.StatusInternalServerError)
+		return
+	}
+
+	report, err := h.aggregator.GetCampaignReport(r.Context(), campaign.ID)
+	if err != nil || report == nil {
+		log.Printf("error getting campaign: %v", err)
+		http.Error(w, "Failed to get campaign", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("HX-Push-Url", "/campaigns/"+strconv.FormatInt(campaign.ID, 10))
+	h.renderCampaign(w, r, "campaign", report)
+}
+
+// AddContent adds the content item in the content form value, a link to
+// it or "platform:id", to the campaign named in the request path and
+// returns the updated report.
+func (h *CampaignsHandler) AddContent(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+	platform, id, ok := parseContentRef(r.FormValue("content"))
+	if !ok {
+		http.Error(w, "Enter a YouTube, X or LinkedIn link, or platform:id", http.StatusBadRequest)
+		return
+	}
+
+	err := h.aggregator.AddCampaignContent(r.Context(), []*data.CampaignMember{{
+		CampaignID: report.Campaign.ID,
+		Platform:   platform,
+		ContentID:  id,
+		Source:     data.CampaignSourceManual,
+	}})
+	if err != nil {
+		if errors.Is(err, insights.ErrInvalidCampaign) {
+			http.Error(w, "That content hasn't been fetched yet", http.StatusBadRequest)
+			return
+		}
+		log.Printf("error adding campaign content: %v", err)
+		http.Error(w, "Failed to add content", http.StatusInternalServerError)
+		return
+	}
+	h.renderReport(w, r, report.Campaign.ID)
+}
+
+// RemoveContent removes the content item named in the request path from
+// the campaign and returns the updated report.
+func (h *CampaignsHandler) RemoveContent(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+	platform := data.Platform(r.PathValue("platform"))
+	if !validPlatform(platform) {
+		http.Error(w, "Invalid platform", http.StatusBadRequest)
+		return
+	}
+	if err := h.store.RemoveCampaignContent(r.Context(), report.Campaign.ID, platform, r.PathValue("content")); err != nil {
+		log.Printf("error removing campaign content: %v", err)
+		http.Error(w, "Failed to remove content", http.StatusInternalServerError)
+		return
+	}
+	h.renderReport(w, r, report.Campaign.ID)
+}
+
+// renderReport renders a campaign's report.
+func (h *CampaignsHandler) renderReport(w http.ResponseWriter, r *http.Request, id int64) {
+	report, err := h.aggregator.GetCampaignReport(r.Context(), id)
+	if err != nil || report == nil {
+		log.Printf("error getting campaign: %v", err)
+		http.Error(w, "Failed to get campaign", http.StatusInternalServerError)
+		return
+	}
+	if err := h.templates.ExecuteTemplate(w, "campaign_report", report); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// Delete deletes the campaign named in the request path and shows the
+// campaigns page.
+func (h *CampaignsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.DeleteCampaign(r.Context(), report.Campaign.ID); err != nil {
+		log.Printf("error deleting campaign: %v", err)
+		http.Error(w, "Failed to delete campaign", http.StatusInternalServerError)
+		return
+	}
+
+	selection := dateRangeFrom(r)
+	selection.Path = "/campaigns"
+	w.Header().Set("HX-Push-Url", "/campaigns?"+string(selection.Query()))
+	h.renderIndex(w, r, "campaigns", selection)
+}
+
+// parseContentRef reads a content item from a link to it or from
+// "platform:id".
+func parseContentRef(ref string) (data.Platform, string, bool) {
+	ref = strings.TrimSpace(ref)
+	if platform, id, ok := data.ParseContentURL(ref); ok {
+		return platform, id, true
+	}
+	platform, id, ok := strings.Cut(ref, ":")
+	if !ok || id == "" || !validPlatform(data.Platform(platform)) {
+		return "", "", false
+	}
+	return data.Platform(platform), id, true
+}
+
+// APIList returns all campaigns with their combined performance as JSON.
+func (h *CampaignsHandler) APIList(w http.ResponseWriter, r *http.Request) {
+	reports, err := h.aggregator.ListCampaignReports(r.Context())
+	if err != nil {
+		log.Printf("error listing campaigns: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to list campaigns")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"campaigns": reports,
+	})
+}
+
+// APIDetail returns the campaign named in the request path with its
+// content and combined performance as JSON.
+func (h *CampaignsHandler) APIDetail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, "campaign not found")
+		return
+	}
+	report, err := h.aggregator.GetCampaignReport(r.Context(), id)
+	if err != nil {
+		log.Printf("error getting campaign: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to get campaign")
+		return
+	}
+	if report == nil {
+		writeJSONError(w, http.StatusNotFound, "campaign not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// APISuggestions returns suggested campaigns from content published in the
+// selected date range as JSON.
+func (h *CampaignsHandler) APISuggestions(w http.ResponseWriter, r *http.Request) {
+	dateRange := periodFrom(r).Current
+	suggestions, err := h.aggregator.SuggestCampaigns(r.Context(), dateRange)
+	if err != nil {
+		log.Printf("error suggesting campaigns: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to suggest campaigns")
+		return
+	}
+	if suggestions == nil {
+		suggestions = []*insights.CampaignSuggestion{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"start":       dateRange.Start,
+		"end":         dateRange.End,
+		"suggestions": suggestions,
+	})
+}
@@ -0,0 +1,230 @@
+// Package handlers provides chart rendering helpers for HTTP handlers.
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/frontend/charts"
+	"github.com/omnipulse/omnipulse/internal/insights"
+)
+
+// dashboardView wraps dashboard data with pre-rendered summary sparklines.
+type dashboardView struct {
+	*insights.DashboardData
+	Sparklines map[string]template.HTML
+}
+
+// newDashboardView builds the dashboard view model, rendering a sparkline
+// for each platform trend that has data.
+func newDashboardView(d *insights.DashboardData) *dashboardView {
+	if d == nil {
+		d = &insights.DashboardData{}
+	}
+
+	view := &dashboardView{
+		DashboardData: d,
+		Sparklines:    make(map[string]template.HTML),
+	}
+
+	trends := map[data.Platform]*data.TrendData{
+		data.PlatformYouTube:  d.YouTubeTrend,
+		data.PlatformX:        d.XTrend,
+		data.PlatformLinkedIn: d.LinkedInTrend,
+	}
+	for platform, trend := range trends {
+		if trend == nil {
+			continue
+		}
+		series := charts.FromTrend(trend, metricTitle(trend.Metric), charts.PlatformColors[platform])
+		view.Sparklines[string(platform)] = charts.SparklineFromSeries(series, charts.SparklineOptions{Fill: true})
+	}
+	return view
+}
+
+// trendChart is a rendered chart for a single metric.
+type trendChart struct {
+	Metric string
+	Title  string
+	SVG    template.HTML
+}
+
+// platformTrendCharts renders an area chart for each platform trend,
+// annotated with detected anomalies and content publish dates and
+// followed by the trend's forecast, if any.
+func platformTrendCharts(platform data.Platform, analytics *insights.PlatformAnalytics) []trendChart {
+	if analytics == nil {
+		return nil
+	}
+
+	var publishes []charts.Annotation
+	for _, p := range analytics.Published {
+		publishes = append(publishes, charts.Annotation{
+			X:     p.PublishedAt,
+			Kind:  charts.AnnotationPublish,
+			Label: "Published: " + p.Title,
+		})
+	}
+
+	result := make([]trendChart, 0, len(analytics.Trends))
+	for _, trend := range analytics.Trends {
+		if trend == nil {
+			continue
+		}
+
+		annotations := append([]charts.Annotation(nil), publishes...)
+		for _, a := range analytics.Anomalies[trend.Metric] {
+			annotations = append(annotations, charts.Annotation{
+				X:     a.Timestamp,
+				Kind:  charts.AnnotationAnomaly,
+				Label: a.Severity + " " + strings.ReplaceAll(a.Type, "_", " "),
+			})
+		}
+
+		title := metricTitle(trend.Metric)
+		series := []charts.Series{charts.FromTrend(trend, title, charts.PlatformColors[platform])}
+		opts := charts.Options{Title: title, Annotations: annotations}
+		if forecast := analytics.Forecasts[trend.Metric]; forecast != nil {
+			projection, band := forecastSeries(series[0], forecast)
+			series = append(series, projection)
+			opts.Bands = append(opts.Bands, band)
+		}
+		result = append(result, trendChart{
+			Metric: trend.Metric,
+			Title:  title,
+			SVG:    charts.Area(series, opts),
+		})
+	}
+	return result
+}
+
+// metricSeriesChart renders a metric's daily series as an area chart,
+// annotated with its stored anomalies and followed by its forecast, if
+// any.
+func metricSeriesChart(platform data.Platform, metric, title string, points []data.DataPoint, anomalies []*data.Anomaly, forecast *insights.Forecast) template.HTML {
+	series := charts.Series{Name: title, Color: charts.PlatformColors[platform]}
+	for _, p := range points {
+		series.Points = append(series.Points, charts.Point{X: p.Timestamp, Y: p.Value})
+	}
+
+	opts := charts.Options{Title: title}
+	chartSeries := []charts.Series{series}
+	if forecast != nil {
+		projection, band := forecastSeries(series, forecast)
+		chartSeries = append(chartSeries, projection)
+		opts.Bands = append(opts.Bands, band)
+	}
+	if metric == "engagement_rate" {
+		opts.FormatValue = func(v float64) string { return fmt.Sprintf("%.2f%%", v) }
+	}
+	format := opts.FormatValue
+	if format == nil {
+		format = charts.FormatCompact
+	}
+	for _, a := range anomalies {
+		label := fmt.Sprintf("%s %s: %s, expected %s", a.Severity, a.Type, format(a.Value), format(a.Expected))
+		if a.Type == data.AnomalyLevelShift {
+			label = fmt.Sprintf("%s level shift: %s from %s", a.Severity, format(a.Value), format(a.Expected))
+		}
+		if insights.AggregationFor(metric) == insights.AggregateLast {
+			label += " per day"
+		}
+		opts.Annotations = append(opts.Annotations, charts.Annotation{
+			X:     a.Timestamp,
+			Kind:  charts.AnnotationAnomaly,
+			Label: label,
+		})
+	}
+	return charts.Area(chartSeries, opts)
+}
+
+// forecastSeries returns a forecast as a dashed series continuing from
+// the last point of actual, and its prediction interval as a band.
+func forecastSeries(actual charts.Series, forecast *insights.Forecast) (charts.Series, charts.Band) {
+	projection := charts.Series{
+		Name:   "Forecast (" + forecast.Model.Label() + ")",
+		Color:  actual.Color,
+		Dashed: true,
+	}
+	band := charts.Band{
+		Name:  fmt.Sprintf("%.0f%% prediction interval", forecast.Interval*100),
+		Color: actual.Color,
+	}
+	if n := len(actual.Points); n > 0 {
+		last := actual.Points[n-1]
+		projection.Points = append(projection.Points, last)
+		band.Points = append(band.Points, charts.BandPoint{X: last.X, Lower: last.Y, Upper: last.Y})
+	}
+	for _, p := range forecast.Points {
+		projection.Points = append(projection.Points, charts.Point{X: p.Timestamp, Y: p.Value})
+		band.Points = append(band.Points, charts.BandPoint{X: p.Timestamp, Lower: p.Lower, Upper: p.Upper})
+	}
+	return projection, band
+}
+
+// metricTitle converts a metric name such as "view_count" to "View count".
+func metricTitle(metric string) string {
+	if metric == "" {
+		return ""
+	}
+	title := strings.ReplaceAll(metric, "_", " ")
+	return strings.ToUpper(title[:1]) + title[1:]
+}
+
+// contentHistoryChart renders a content item's metric history as a
+// multi-series line chart.
+func contentHistoryChart(platform data.Platform, detail *insights.ContentDetail) template.HTML {
+	viewsLabel := "Impressions"
+	if platform == data.PlatformYouTube {
+		viewsLabel = "Views"
+	}
+
+	series := []charts.Series{
+		{Name: viewsLabel, Color: charts.PlatformColors[platform]},
+		{Name: "Likes"},
+		{Name: "Comments"},
+	}
+	if platform != data.PlatformYouTube {
+		series = append(series, charts.Series{Name: "Shares"})
+	}
+
+	for _, snap := range detail.History {
+		values := []int64{snap.Views, snap.Likes, snap.Comments, snap.Shares}
+		for i := range series {
+			series[i].Points = append(series[i].Points, charts.Point{X: snap.RecordedAt, Y: float64(values[i])})
+		}
+	}
+
+	return charts.Line(series, charts.Options{
+		Title:      "Metric history",
+		ShowLegend: true,
+		Annotations: []charts.Annotation{{
+			X:     detail.Item.PublishedAt,
+			Kind:  charts.AnnotationPublish,
+			Label: "Published",
+		}},
+	})
+}
+
+// sentimentChart renders the daily count of comments by sentiment as a
+// multi-series line chart.
+func sentimentChart(points []*data.SentimentPoint) template.HTML {
+	series := []charts.Series{
+		{Name: "Positive", Color: charts.Palette[2]},
+		{Name: "Neutral", Color: charts.Palette[5]},
+		{Name: "Negative", Color: charts.Palette[1]},
+	}
+	for _, p := range points {
+		counts := []int{p.Positive, p.Neutral, p.Negative}
+		for i := range series {
+			series[i].Points = append(series[i].Points, charts.Point{X: p.Date, Y: float64(counts[i])})
+		}
+	}
+
+	return charts.Line(series, charts.Options{
+		Title:      "Comment sentiment",
+		ShowLegend: true,
+	})
+}
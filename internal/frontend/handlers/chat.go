@@ -0,0 +1,128 @@
+// Package handlers provides HTTP handlers for the analytics chat.
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/insights"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// chatConversationLimit is the number of recent conversations listed.
+const chatConversationLimit = 30
+
+// ChatHandler serves the ask-your-analytics chat.
+type ChatHandler struct {
+	store     storage.Store
+	analyst   *insights.Analyst
+	templates *template.Template
+}
+
+// NewChatHandler creates a new ChatHandler.
+func NewChatHandler(store storage.Store, analyst *insights.Analyst, templates *template.Template) *ChatHandler {
+	return &ChatHandler{
+		store:     store,
+		analyst:   analyst,
+		templates: templates,
+	}
+}
+
+// chatExchange is the template data for a question and its answer.
+type chatExchange struct {
+	Conversation *data.Conversation
+	Messages     []*data.ChatMessage
+	New          bool // Whether the conversation was started by this question
+}
+
+// Index serves the chat page, showing the conversation with the id path
+// value if set or an empty conversation otherwise.
+func (h *ChatHandler) Index(w http.ResponseWriter, r *http.Request) {
+	isHTMX := r.Header.Get("HX-Request") == "true"
+
+	conversations, err := h.store.ListConversations(r.Context(), chatConversationLimit)
+	if err != nil {
+		log.Printf("error listing conversations: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var conversation *data.Conversation
+	var messages []*data.ChatMessage
+	if id := r.PathValue("id"); id != "" {
+		conversation, err = h.store.GetConversation(r.Context(), id)
+		if err != nil {
+			log.Printf("error getting conversation: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if conversation == nil {
+			http.Error(w, "Conversation not found", http.StatusNotFound)
+			return
+		}
+		if messages, err = h.store.GetChatMessages(r.Context(), id); err != nil {
+			log.Printf("error getting chat messages: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	templateName := "base"
+	if isHTMX {
+		templateName = "chat"
+	}
+
+	if err := h.templates.ExecuteTemplate(w, templateName, map[string]interface{}{
+		"Page":          "chat",
+		"Title":         "Ask Your Analytics",
+		"Conversations": conversations,
+		"Conversation":  conversation,
+		"Messages":      messages,
+		"Range":         dateRangeFrom(r),
+	}); err != nil {
+		log.Printf("error rendering template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// Ask answers the question form value in the conversation_id conversation,
+// starting a new conversation if it's empty, and returns the question and
+// answer as HTML. The URL is updated when a conversation is started.
+func (h *ChatHandler) Ask(w http.ResponseWriter, r *http.Request) {
+	question := strings.TrimSpace(r.FormValue("question"))
+	if question == "" {
+		http.Error(w, "Question is required", http.StatusBadRequest)
+		return
+	}
+	conversationID := r.FormValue("conversation_id")
+
+	conversation, messages, err := h.analyst.Ask(r.Context(), conversationID, question)
+	if err != nil {
+		log.Printf("error answering question: %v", err)
+		if errors.Is(err, insights.ErrConversationNotFound) {
+			http.Error(w, "Conversation not found", http.StatusNotFound)
+			return
+		}
+		message, status := generateError(err)
+		if status == http.StatusInternalServerError {
+			message = "Failed to answer the question"
+		}
+		http.Error(w, message, status)
+		return
+	}
+
+	if conversationID == "" {
+		w.Header().Set("HX-Push-Url", "/chat/"+conversation.ID)
+	}
+	if err := h.templates.ExecuteTemplate(w, "chat_exchange", chatExchange{
+		Conversation: conversation,
+		Messages:     messages,
+		New:          conversationID == "",
+	}); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
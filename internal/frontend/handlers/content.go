@@ -0,0 +1,65 @@
+// Package handlers provides HTTP handlers for content detail pages.
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/insights"
+)
+
+// ContentHandler handles requests for individual content items.
+type ContentHandler struct {
+	aggregator *insights.Aggregator
+	templates  *template.Template
+}
+
+// NewContentHandler creates a new ContentHandler.
+func NewContentHandler(aggregator *insights.Aggregator, templates *template.Template) *ContentHandler {
+	return &ContentHandler{
+		aggregator: aggregator,
+		templates:  templates,
+	}
+}
+
+// Detail serves the page for a single video, tweet or LinkedIn post at
+// /content/{platform}/{id}.
+func (h *ContentHandler) Detail(w http.ResponseWriter, r *http.Request) {
+	isHTMX := r.Header.Get("HX-Request") == "true"
+
+	platform := data.Platform(r.PathValue("platform"))
+	if !validPlatform(platform) {
+		http.NotFound(w, r)
+		return
+	}
+
+	detail, err := h.aggregator.GetContentDetail(r.Context(), platform, r.PathValue("id"), periodFrom(r))
+	if err != nil {
+		log.Printf("error getting content detail: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if detail == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	templateName := "base"
+	if isHTMX {
+		templateName = "content_detail"
+	}
+
+	if err := h.templates.ExecuteTemplate(w, templateName, map[string]interface{}{
+		"Page":     "content",
+		"Title":    detail.Item.Title,
+		"Platform": platform,
+		"Detail":   detail,
+		"Chart":    contentHistoryChart(platform, detail),
+		"Range":    dateRangeFrom(r),
+	}); err != nil {
+		log.Printf("error rendering template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/omnipulse/omnipulse/internal/insights"
+	"github.com/omnipulse/omnipulse/internal/scheduler"
 	"github.com/omnipulse/omnipulse/internal/storage"
 )
 
@@ -14,14 +15,17 @@ import (
 type DashboardHandler struct {
 	store      storage.Store
 	aggregator *insights.Aggregator
+	scheduler  *scheduler.Scheduler
 	templates  *template.Template
 }
 
-// NewDashboardHandler creates a new DashboardHandler.
-func NewDashboardHandler(store storage.Store, aggregator *insights.Aggregator, templates *template.Template) *DashboardHandler {
+// NewDashboardHandler creates a new DashboardHandler. The scheduler may be
+// nil when background tasks are not running.
+func NewDashboardHandler(store storage.Store, aggregator *insights.Aggregator, sched *scheduler.Scheduler, templates *template.Template) *DashboardHandler {
 	return &DashboardHandler{
 		store:      store,
 		aggregator: aggregator,
+		scheduler:  sched,
 		templates:  templates,
 	}
 }
@@ -32,7 +36,7 @@ func (h *DashboardHandler) Index(w http.ResponseWriter, r *http.Request) {
 	// For HTMX requests, only render the content partial
 	isHTMX := r.Header.Get("HX-Request") == "true"
 
-	data, err := h.aggregator.GetDashboardData(r.Context(), 7) // Last 7 days
+	data, err := h.aggregator.GetDashboardData(r.Context(), periodFrom(r))
 	if err != nil {
 		log.Printf("error getting dashboard data: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -47,7 +51,8 @@ func (h *DashboardHandler) Index(w http.ResponseWriter, r *http.Request) {
 	if err := h.templates.ExecuteTemplate(w, templateName, map[string]interface{}{
 		"Page":       "dashboard",
 		"Title":      "Dashboard",
-		"Data":       data,
+		"Data":       newDashboardView(data),
+		"Range":      dateRangeFrom(r),
 	}); err != nil {
 		log.Printf("error rendering template: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -56,19 +61,14 @@ func (h *DashboardHandler) Index(w http.ResponseWriter, r *http.Request) {
 
 // Refresh handles HTMX requests to refresh dashboard data.
 func (h *DashboardHandler) Refresh(w http.ResponseWriter, r *http.Request) {
-	days := 7
-	if d := r.URL.Query().Get("days"); d != "" {
-		// TODO: Parse days parameter
-	}
-
-	data, err := h.aggregator.GetDashboardData(r.Context(), days)
+	data, err := h.aggregator.GetDashboardData(r.Context(), periodFrom(r))
 	if err != nil {
 		log.Printf("error getting dashboard data: %v", err)
 		http.Error(w, "Failed to refresh data", http.StatusInternalServerError)
 		return
 	}
 
-	if err := h.templates.ExecuteTemplate(w, "dashboard_content", data); err != nil {
+	if err := h.templates.ExecuteTemplate(w, "dashboard_content", newDashboardView(data)); err != nil {
 		log.Printf("error rendering template: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
@@ -76,14 +76,31 @@ func (h *DashboardHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 
 // Summary handles requests for the analytics summary card.
 func (h *DashboardHandler) Summary(w http.ResponseWriter, r *http.Request) {
-	data, err := h.aggregator.GetDashboardData(r.Context(), 7)
+	data, err := h.aggregator.GetDashboardData(r.Context(), periodFrom(r))
 	if err != nil {
 		log.Printf("error getting summary: %v", err)
 		http.Error(w, "Failed to get summary", http.StatusInternalServerError)
 		return
 	}
 
-	if err := h.templates.ExecuteTemplate(w, "summary_card", data.Summary); err != nil {
+	if err := h.templates.ExecuteTemplate(w, "summary_card", newDashboardView(data)); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// Health handles requests for the task health banner, which lists
+// platforms whose tasks are paused by an open circuit breaker.
+func (h *DashboardHandler) Health(w http.ResponseWriter, r *http.Request) {
+	var paused []scheduler.BreakerStatus
+	if h.scheduler != nil {
+		for _, status := range h.scheduler.GetBreakers() {
+			if status.IsOpen() {
+				paused = append(paused, status)
+			}
+		}
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "task_health", paused); err != nil {
 		log.Printf("error rendering template: %v", err)
 	}
 }
@@ -0,0 +1,214 @@
+// Package handlers provides date range parsing shared by all HTTP handlers.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/insights"
+)
+
+// dateLayout is the format used for custom start/end query parameters.
+const dateLayout = "2006-01-02"
+
+// defaultRangePreset is used when the request does not specify a range.
+const defaultRangePreset = "30d"
+
+// maxRangeDays limits the "Nd" presets, including the legacy days=N
+// parameter.
+const maxRangeDays = 365
+
+// rangePreset is a selectable option in the date range picker.
+type rangePreset struct {
+	Value string
+	Label string
+}
+
+// rangePresets lists the picker options in display order.
+var rangePresets = []rangePreset{
+	{Value: "7d", Label: "Last 7 days"},
+	{Value: "30d", Label: "Last 30 days"},
+	{Value: "90d", Label: "Last 90 days"},
+	{Value: "mtd", Label: "Month to date"},
+	{Value: "ytd", Label: "Year to date"},
+	{Value: "custom", Label: "Custom range"},
+}
+
+// DateRange is the date range selected for a request, as parsed from the
+// URL by DateRangeMiddleware.
+type DateRange struct {
+	Path    string // Request path, used as the picker's target
+	Preset  string
+	Start   time.Time // Inclusive, midnight local time
+	End     time.Time // Exclusive, midnight after the last day
+	Compare bool
+}
+
+// Period converts the selection into an insights.Period.
+func (d DateRange) Period() insights.Period {
+	return insights.NewPeriod(d.Start, d.End, d.Compare)
+}
+
+// Query encodes the selection as URL query parameters so links and
+// HTMX requests can carry it along.
+func (d DateRange) Query() template.URL {
+	q := url.Values{}
+	q.Set("range", d.Preset)
+	if d.Preset == "custom" {
+		q.Set("start", d.StartValue())
+		q.Set("end", d.EndValue())
+	}
+	if d.Compare {
+		q.Set("compare", "1")
+	}
+	return template.URL(q.Encode())
+}
+
+// StartValue returns the start date formatted for a date input.
+func (d DateRange) StartValue() string {
+	return d.Start.Format(dateLayout)
+}
+
+// EndValue returns the last included day formatted for a date input.
+func (d DateRange) EndValue() string {
+	return d.End.AddDate(0, 0, -1).Format(dateLayout)
+}
+
+// Presets returns the picker options.
+func (d DateRange) Presets() []rangePreset {
+	return rangePresets
+}
+
+// dateRangeKey is the context key for the parsed DateRange.
+type dateRangeKey struct{}
+
+// DateRangeMiddleware parses the range, start, end and compare query
+// parameters once per request and stores the result in the request context.
+//
+// HTMX sub-requests (cards, lists, refreshes) usually don't carry the
+// parameters themselves, so they fall back to the page URL HTMX reports in
+// the HX-Current-URL header. The legacy days=N parameter is also accepted.
+//
+// An invalid range in a GET request's own URL is rejected with 400 Bad
+// Request. Other requests, and ranges taken from HX-Current-URL, fall back
+// to the default preset instead, so a bad page URL can't break form posts
+// or the partials loaded into the page. Static assets are passed through.
+func DateRangeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/static/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		query := r.URL.Query()
+		fromPage := false
+		if !hasRangeParams(query) {
+			if current, err := url.Parse(r.Header.Get("HX-Current-URL")); err == nil && hasRangeParams(current.Query()) {
+				query = current.Query()
+				fromPage = true
+			}
+		}
+
+		now := time.Now()
+		selection, err := parseDateRange(query, now)
+		if err != nil {
+			if !fromPage && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			selection, _ = parseDateRange(url.Values{}, now)
+		}
+		selection.Path = r.URL.Path
+
+		ctx := context.WithValue(r.Context(), dateRangeKey{}, selection)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// dateRangeFrom returns the DateRange for a request, falling back to the
+// default preset if the middleware did not run.
+func dateRangeFrom(r *http.Request) DateRange {
+	if selection, ok := r.Context().Value(dateRangeKey{}).(DateRange); ok {
+		return selection
+	}
+	selection, _ := parseDateRange(url.Values{}, time.Now())
+	selection.Path = r.URL.Path
+	return selection
+}
+
+// periodFrom returns the analysis period selected for a request.
+func periodFrom(r *http.Request) insights.Period {
+	return dateRangeFrom(r).Period()
+}
+
+// hasRangeParams reports whether the query specifies a date range.
+func hasRangeParams(q url.Values) bool {
+	for _, key := range []string{"range", "start", "end", "days", "compare"} {
+		if q.Has(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDateRange parses date range query parameters relative to now.
+func parseDateRange(q url.Values, now time.Time) (DateRange, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	tomorrow := today.AddDate(0, 0, 1)
+
+	selection := DateRange{
+		Preset:  q.Get("range"),
+		End:     tomorrow,
+		Compare: q.Get("compare") == "1" || q.Get("compare") == "true" || q.Get("compare") == "on",
+	}
+
+	if selection.Preset == "" {
+		selection.Preset = defaultRangePreset
+		if d := q.Get("days"); d != "" {
+			days, err := strconv.Atoi(d)
+			if err != nil || days < 1 {
+				return DateRange{}, fmt.Errorf("invalid days parameter: %q", d)
+			}
+			selection.Preset = fmt.Sprintf("%dd", days)
+		}
+		if q.Get("start") != "" || q.Get("end") != "" {
+			selection.Preset = "custom"
+		}
+	}
+
+	switch selection.Preset {
+	case "mtd":
+		selection.Start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	case "ytd":
+		selection.Start = time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+	case "custom":
+		start, err := time.ParseInLocation(dateLayout, q.Get("start"), now.Location())
+		if err != nil {
+			return DateRange{}, fmt.Errorf("invalid start date: %q", q.Get("start"))
+		}
+		end, err := time.ParseInLocation(dateLayout, q.Get("end"), now.Location())
+		if err != nil {
+			return DateRange{}, fmt.Errorf("invalid end date: %q", q.Get("end"))
+		}
+		if end.Before(start) {
+			return DateRange{}, fmt.Errorf("end date is before start date")
+		}
+		selection.Start = start
+		selection.End = end.AddDate(0, 0, 1)
+	default:
+		n, ok := strings.CutSuffix(selection.Preset, "d")
+		days, err := strconv.Atoi(n)
+		if !ok || err != nil || days < 1 || days > maxRangeDays {
+			return DateRange{}, fmt.Errorf("invalid range: %q", selection.Preset)
+		}
+		selection.Start = today.AddDate(0, 0, -(days - 1))
+	}
+
+	return selection, nil
+}
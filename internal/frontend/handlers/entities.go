@@ -0,0 +1,211 @@
+// Package handlers provides HTTP handlers for hashtag, mention and link
+// analytics.
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/omnipulse/omnipulse/internal/backfill"
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+const (
+	// defaultEntityMinPosts is the fewest content items an entity must
+	// appear in to be ranked, unless the min_posts parameter says otherwise.
+	defaultEntityMinPosts = 3
+
+	entityReportLimit = 15 // Entities ranked per type on the page
+	maxEntityLimit    = 100
+)
+
+// entityTypeTitles are the report's section titles.
+var entityTypeTitles = map[data.EntityType]string{
+	data.EntityHashtag: "Hashtags",
+	data.EntityMention: "Mentions",
+	data.EntityDomain:  "Link Domains",
+}
+
+// EntitiesHandler serves rankings of the hashtags, mentions and link
+// domains in content by the performance of the content they appear in.
+type EntitiesHandler struct {
+	store     storage.Store
+	templates *template.Template
+}
+
+// NewEntitiesHandler creates a new EntitiesHandler.
+func NewEntitiesHandler(store storage.Store, templates *template.Template) *EntitiesHandler {
+	return &EntitiesHandler{
+		store:     store,
+		templates: templates,
+	}
+}
+
+// entityRanking is one entity type's section of the report.
+type entityRanking struct {
+	Type  data.EntityType
+	Title string
+	Stats []*data.EntityStats
+}
+
+// entityReport is the entity rankings for the selected filters.
+type entityReport struct {
+	Platform data.Platform
+	Sort     data.EntitySort
+	MinPosts int
+	Rankings []entityRanking
+	Range    DateRange
+}
+
+// entityQuery builds a query from the platform, sort and min_posts query
+// or form values and the selected date range. ok is false if the platform
+// or sort is invalid.
+func entityQuery(r *http.Request) (query data.EntityQuery, ok bool) {
+	query = data.EntityQuery{
+		Platform:  data.Platform(r.FormValue("platform")),
+		DateRange: periodFrom(r).Current,
+		MinPosts:  defaultEntityMinPosts,
+		Sort:      data.EntitySort(r.FormValue("sort")),
+	}
+	if minPosts, err := strconv.Atoi(r.FormValue("min_posts")); err == nil {
+		query.MinPosts = max(minPosts, 1)
+	}
+	if query.Sort == "" {
+		query.Sort = data.EntitySortEngagement
+	}
+	return query, (query.Platform == "" || validPlatform(query.Platform)) && query.Sort.Valid()
+}
+
+// report ranks each entity type for the query.
+func (h *EntitiesHandler) report(r *http.Request, query data.EntityQuery) (*entityReport, error) {
+	report := &entityReport{
+		Platform: query.Platform,
+		Sort:     query.Sort,
+		MinPosts: query.MinPosts,
+		Range:    dateRangeFrom(r),
+	}
+	query.Limit = entityReportLimit
+	for _, entityType := range data.EntityTypes {
+		query.Type = entityType
+		stats, err := h.store.GetEntityStats(r.Context(), query)
+		if err != nil {
+			return nil, err
+		}
+		report.Rankings = append(report.Rankings, entityRanking{
+			Type:  entityType,
+			Title: entityTypeTitles[entityType],
+			Stats: stats,
+		})
+	}
+	return report, nil
+}
+
+// Index serves the hashtags, mentions and links page.
+func (h *EntitiesHandler) Index(w http.ResponseWriter, r *http.Request) {
+	isHTMX := r.Header.Get("HX-Request") == "true"
+
+	query, ok := entityQuery(r)
+	if !ok {
+		http.Error(w, "Invalid platform or sort", http.StatusBadRequest)
+		return
+	}
+	report, err := h.report(r, query)
+	if err != nil {
+		log.Printf("error getting entity stats: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	templateName := "base"
+	if isHTMX {
+		templateName = "entities"
+	}
+
+	if err := h.templates.ExecuteTemplate(w, templateName, map[string]interface{}{
+		"Page":   "entities",
+		"Title":  "Hashtags, Mentions & Links",
+		"Report": report,
+		"Range":  report.Range,
+	}); err != nil {
+		log.Printf("error rendering template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// Report returns the entity rankings for the filter parameters.
+func (h *EntitiesHandler) Report(w http.ResponseWriter, r *http.Request) {
+	query, ok := entityQuery(r)
+	if !ok {
+		http.Error(w, "Invalid platform or sort", http.StatusBadRequest)
+		return
+	}
+	h.renderReport(w, r, query)
+}
+
+// Scan extracts entities from saved content that hasn't been scanned, such
+// as content saved before entities were extracted on ingest, and returns
+// the updated rankings.
+func (h *EntitiesHandler) Scan(w http.ResponseWriter, r *http.Request) {
+	query, ok := entityQuery(r)
+	if !ok {
+		http.Error(w, "Invalid platform or sort", http.StatusBadRequest)
+		return
+	}
+	if _, err := backfill.ScanEntities(r.Context(), h.store); err != nil {
+		log.Printf("error scanning entities: %v", err)
+		http.Error(w, "Failed to scan content", http.StatusInternalServerError)
+		return
+	}
+	h.renderReport(w, r, query)
+}
+
+// renderReport renders the entity rankings for query.
+func (h *EntitiesHandler) renderReport(w http.ResponseWriter, r *http.Request, query data.EntityQuery) {
+	report, err := h.report(r, query)
+	if err != nil {
+		log.Printf("error getting entity stats: %v", err)
+		http.Error(w, "Failed to get entity stats", http.StatusInternalServerError)
+		return
+	}
+	if err := h.templates.ExecuteTemplate(w, "entities_report", report); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// API returns the entities of the type parameter ranked for the platform,
+// sort, min_posts and limit parameters and the selected date range as
+// JSON.
+func (h *EntitiesHandler) API(w http.ResponseWriter, r *http.Request) {
+	query, ok := entityQuery(r)
+	if !ok {
+		writeJSONError(w, http.StatusBadRequest, "invalid platform or sort")
+		return
+	}
+	query.Type = data.EntityType(r.URL.Query().Get("type"))
+	if !query.Type.Valid() {
+		writeJSONError(w, http.StatusBadRequest, "type must be hashtag, mention or domain")
+		return
+	}
+	query.Limit = queryInt(r, "limit", entityReportLimit, 1, maxEntityLimit)
+
+	stats, err := h.store.GetEntityStats(r.Context(), query)
+	if err != nil {
+		log.Printf("error getting entity stats: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to get entity stats")
+		return
+	}
+	if stats == nil {
+		stats = []*data.EntityStats{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"start":     query.DateRange.Start,
+		"end":       query.DateRange.End,
+		"type":      query.Type,
+		"min_posts": query.MinPosts,
+		"entities":  stats,
+	})
+}
@@ -0,0 +1,192 @@
+// Package handlers provides HTTP handlers for metric goals.
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/insights"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// GoalsHandler serves metric goals and their progress.
+type GoalsHandler struct {
+	store     storage.Store
+	analyzer  *insights.TrendAnalyzer
+	templates *template.Template
+}
+
+// NewGoalsHandler creates a new GoalsHandler.
+func NewGoalsHandler(store storage.Store, analyzer *insights.TrendAnalyzer, templates *template.Template) *GoalsHandler {
+	return &GoalsHandler{
+		store:     store,
+		analyzer:  analyzer,
+		templates: templates,
+	}
+}
+
+// goalMetricOption is a platform metric a goal can be set for.
+type goalMetricOption struct {
+	Value string // "platform.metric"
+	Label string
+}
+
+// goalMetricGroup lists a platform's goal metrics.
+type goalMetricGroup struct {
+	Platform data.Platform
+	Options  []goalMetricOption
+}
+
+// goalMetricGroups lists the metrics goals can be set for, by platform.
+func goalMetricGroups() []goalMetricGroup {
+	var groups []goalMetricGroup
+	for _, platform := range []data.Platform{data.PlatformYouTube, data.PlatformX, data.PlatformLinkedIn} {
+		group := goalMetricGroup{Platform: platform}
+		for _, metric := range insights.PlatformMetrics[platform] {
+			group.Options = append(group.Options, goalMetricOption{
+				Value: string(platform) + "." + metric,
+				Label: metricTitle(metric),
+			})
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// Index serves the goals page.
+func (h *GoalsHandler) Index(w http.ResponseWriter, r *http.Request) {
+	isHTMX := r.Header.Get("HX-Request") == "true"
+
+	progress, err := h.analyzer.AllGoalProgress(r.Context(), "")
+	if err != nil {
+		log.Printf("error getting goal progress: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	templateName := "base"
+	if isHTMX {
+		templateName = "goals"
+	}
+
+	if err := h.templates.ExecuteTemplate(w, templateName, map[string]interface{}{
+		"Page":    "goals",
+		"Title":   "Goals",
+		"Goals":   progress,
+		"Metrics": goalMetricGroups(),
+		"Today":   time.Now().Format("2006-01-02"),
+		"Range":   dateRangeFrom(r),
+	}); err != nil {
+		log.Printf("error rendering template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// Create saves a goal from the metric ("platform.metric"), target,
+// deadline and optional start (both YYYY-MM-DD) and note form values and
+// returns the updated goal list.
+func (h *GoalsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	platform, metric, _ := strings.Cut(r.FormValue("metric"), ".")
+	goal := &data.Goal{
+		Platform: data.Platform(platform),
+		Metric:   metric,
+		Note:     strings.TrimSpace(r.FormValue("note")),
+	}
+	if !validPlatform(goal.Platform) {
+		http.Error(w, "Invalid platform", http.StatusBadRequest)
+		return
+	}
+	target, err := strconv.ParseFloat(r.FormValue("target"), 64)
+	if err != nil {
+		http.Error(w, "Target must be a number", http.StatusBadRequest)
+		return
+	}
+	goal.Target = target
+	if goal.Deadline, err = time.ParseInLocation("2006-01-02", r.FormValue("deadline"), time.Local); err != nil {
+		http.Error(w, "Deadline must be a date", http.StatusBadRequest)
+		return
+	}
+	if start := r.FormValue("start"); start != "" {
+		if goal.Start, err = time.ParseInLocation("2006-01-02", start, time.Local); err != nil {
+			http.Error(w, "Start must be a date", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.analyzer.CreateGoal(r.Context(), goal); err != nil {
+		if errors.Is(err, insights.ErrInvalidGoal) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("error creating goal: %v", err)
+		http.Error(w, "Failed to create goal", http.StatusInternalServerError)
+		return
+	}
+	h.list(w, r)
+}
+
+// Delete deletes the goal named in the request path and returns the
+// updated goal list.
+func (h *GoalsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Goal not found", http.StatusNotFound)
+		return
+	}
+	goal, err := h.store.GetGoal(r.Context(), id)
+	if err != nil {
+		log.Printf("error getting goal: %v", err)
+		http.Error(w, "Failed to get goal", http.StatusInternalServerError)
+		return
+	}
+	if goal == nil {
+		http.Error(w, "Goal not found", http.StatusNotFound)
+		return
+	}
+	if err := h.store.DeleteGoal(r.Context(), id); err != nil {
+		log.Printf("error deleting goal: %v", err)
+		http.Error(w, "Failed to delete goal", http.StatusInternalServerError)
+		return
+	}
+	h.list(w, r)
+}
+
+// list renders the goal list.
+func (h *GoalsHandler) list(w http.ResponseWriter, r *http.Request) {
+	progress, err := h.analyzer.AllGoalProgress(r.Context(), "")
+	if err != nil {
+		log.Printf("error getting goal progress: %v", err)
+		http.Error(w, "Failed to get goals", http.StatusInternalServerError)
+		return
+	}
+	if err := h.templates.ExecuteTemplate(w, "goals_list", progress); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// API returns the goals with their progress as JSON, optionally filtered
+// by the platform parameter.
+func (h *GoalsHandler) API(w http.ResponseWriter, r *http.Request) {
+	platform := data.Platform(r.URL.Query().Get("platform"))
+	if platform != "" && !validPlatform(platform) {
+		writeJSONError(w, http.StatusBadRequest, "invalid platform")
+		return
+	}
+
+	progress, err := h.analyzer.AllGoalProgress(r.Context(), platform)
+	if err != nil {
+		log.Printf("error getting goal progress: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to get goals")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"goals": progress,
+	})
+}
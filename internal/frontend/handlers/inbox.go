@@ -0,0 +1,212 @@
+// Package handlers provides HTTP handlers for the unified comment inbox.
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// inboxPageSize is the number of matching comments shown per page.
+const inboxPageSize = 25
+
+// InboxHandler handles comment inbox HTTP requests.
+type InboxHandler struct {
+	store     storage.Store
+	templates *template.Template
+}
+
+// NewInboxHandler creates a new InboxHandler.
+func NewInboxHandler(store storage.Store, templates *template.Template) *InboxHandler {
+	return &InboxHandler{
+		store:     store,
+		templates: templates,
+	}
+}
+
+// Index serves the inbox page.
+func (h *InboxHandler) Index(w http.ResponseWriter, r *http.Request) {
+	isHTMX := r.Header.Get("HX-Request") == "true"
+
+	view, err := h.loadInbox(r)
+	if err != nil {
+		log.Printf("error getting inbox comments: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	templateName := "base"
+	if isHTMX {
+		templateName = "inbox"
+	}
+
+	if err := h.templates.ExecuteTemplate(w, templateName, map[string]interface{}{
+		"Page":  "inbox",
+		"Title": "Comment Inbox",
+		"Inbox": view,
+		"Range": dateRangeFrom(r),
+	}); err != nil {
+		log.Printf("error rendering template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// List handles HTMX requests for a page of inbox threads.
+func (h *InboxHandler) List(w http.ResponseWriter, r *http.Request) {
+	view, err := h.loadInbox(r)
+	if err != nil {
+		log.Printf("error getting inbox comments: %v", err)
+		http.Error(w, "Failed to get comments", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "inbox_list", view); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// UpdateState handles triage actions on a single comment and returns the
+// re-rendered comment. The action is one of read, unread, archive,
+// unarchive, flag or unflag.
+func (h *InboxHandler) UpdateState(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	yes, no := true, false
+
+	var update data.CommentStateUpdate
+	switch r.PathValue("action") {
+	case "read":
+		update.Read = &yes
+	case "unread":
+		update.Read = &no
+	case "archive":
+		update.Archived = &yes
+		update.Read = &yes
+	case "unarchive":
+		update.Archived = &no
+	case "flag":
+		update.Flagged = &yes
+	case "unflag":
+		update.Flagged = &no
+	default:
+		http.Error(w, "Invalid action", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateCommentState(r.Context(), id, update); err != nil {
+		log.Printf("error updating comment state: %v", err)
+		http.Error(w, "Failed to update comment", http.StatusInternalServerError)
+		return
+	}
+
+	comment, err := h.store.GetInboxComment(r.Context(), id)
+	if err != nil {
+		log.Printf("error getting comment: %v", err)
+		http.Error(w, "Failed to get comment", http.StatusInternalServerError)
+		return
+	}
+	if comment == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "inbox_comment", comment); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// loadInbox parses inbox filters from the request and loads the matching
+// page of threads.
+func (h *InboxHandler) loadInbox(r *http.Request) (*inboxView, error) {
+	q := r.URL.Query()
+	view := &inboxView{
+		Filter: data.InboxFilter{
+			Platform:        data.Platform(q.Get("platform")),
+			ContentID:       q.Get("content"),
+			UnreadOnly:      q.Get("unread") == "1",
+			FlaggedOnly:     q.Get("flagged") == "1",
+			QuestionsOnly:   q.Get("questions") == "1",
+			Sentiment:       q.Get("sentiment"),
+			Intent:          q.Get("intent"),
+			IncludeArchived: q.Get("archived") == "1",
+			Limit:           inboxPageSize,
+		},
+		Page: queryInt(r, "page", 1, 1, 0),
+	}
+	if view.Filter.Platform != "" && !validPlatform(view.Filter.Platform) {
+		view.Filter.Platform = ""
+	}
+	if view.Filter.Intent != "" && !data.ValidIntent(view.Filter.Intent) {
+		view.Filter.Intent = ""
+	}
+	view.Filter.Offset = (view.Page - 1) * inboxPageSize
+
+	threads, total, err := h.store.ListInboxComments(r.Context(), view.Filter)
+	if err != nil {
+		return nil, err
+	}
+	view.Threads = threads
+	view.Total = total
+	return view, nil
+}
+
+// inboxView is the template data for the inbox.
+type inboxView struct {
+	Filter  data.InboxFilter
+	Threads []*data.InboxComment
+	Total   int
+	Page    int
+}
+
+// HasNext reports whether there are more matching comments.
+func (v *inboxView) HasNext() bool {
+	return v.Page*inboxPageSize < v.Total
+}
+
+// HasPrev reports whether there is a previous page.
+func (v *inboxView) HasPrev() bool {
+	return v.Page > 1
+}
+
+// PrevQuery returns the query string for the previous page.
+func (v *inboxView) PrevQuery() template.URL {
+	return v.pageQuery(v.Page - 1)
+}
+
+// NextQuery returns the query string for the next page.
+func (v *inboxView) NextQuery() template.URL {
+	return v.pageQuery(v.Page + 1)
+}
+
+// pageQuery encodes the current filters with the given page number.
+func (v *inboxView) pageQuery(page int) template.URL {
+	q := url.Values{}
+	if v.Filter.Platform != "" {
+		q.Set("platform", string(v.Filter.Platform))
+	}
+	if v.Filter.ContentID != "" {
+		q.Set("content", v.Filter.ContentID)
+	}
+	if v.Filter.Sentiment != "" {
+		q.Set("sentiment", v.Filter.Sentiment)
+	}
+	if v.Filter.Intent != "" {
+		q.Set("intent", v.Filter.Intent)
+	}
+	for key, on := range map[string]bool{
+		"unread":    v.Filter.UnreadOnly,
+		"flagged":   v.Filter.FlaggedOnly,
+		"questions": v.Filter.QuestionsOnly,
+		"archived":  v.Filter.IncludeArchived,
+	} {
+		if on {
+			q.Set(key, "1")
+		}
+	}
+	q.Set("page", strconv.Itoa(page))
+	return template.URL(q.Encode())
+}
@@ -2,9 +2,14 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/omnipulse/omnipulse/internal/data"
 	"github.com/omnipulse/omnipulse/internal/insights"
@@ -15,28 +20,76 @@ import (
 type InsightsHandler struct {
 	store     storage.Store
 	llm       *insights.LLMClient
+	prompts   *insights.PromptBuilder
+	curator   *insights.Curator
 	templates *template.Template
 }
 
 // NewInsightsHandler creates a new InsightsHandler.
-func NewInsightsHandler(store storage.Store, llm *insights.LLMClient, templates *template.Template) *InsightsHandler {
+func NewInsightsHandler(store storage.Store, llm *insights.LLMClient, prompts *insights.PromptBuilder, curator *insights.Curator, templates *template.Template) *InsightsHandler {
 	return &InsightsHandler{
 		store:     store,
 		llm:       llm,
+		prompts:   prompts,
+		curator:   curator,
 		templates: templates,
 	}
 }
 
+// insightView is an insight with its structured detail and feedback, if
+// it has them.
+type insightView struct {
+	Insight  *data.Insight
+	Detail   *data.InsightDetail
+	Feedback *data.InsightFeedback
+}
+
+// Rating returns the insight's rating, or data.RatingNone if unrated.
+func (v insightView) Rating() int {
+	if v.Feedback == nil {
+		return data.RatingNone
+	}
+	return v.Feedback.Rating
+}
+
+// insightViews pairs insights with their details and feedback.
+func (h *InsightsHandler) insightViews(r *http.Request, insightsList []*data.Insight) ([]insightView, error) {
+	ids := make([]string, len(insightsList))
+	for i, insight := range insightsList {
+		ids[i] = insight.ID
+	}
+	details, err := h.store.GetInsightDetails(r.Context(), ids)
+	if err != nil {
+		return nil, err
+	}
+	feedback, err := h.store.GetInsightFeedback(r.Context(), ids)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]insightView, len(insightsList))
+	for i, insight := range insightsList {
+		views[i] = insightView{Insight: insight, Detail: details[insight.ID], Feedback: feedback[insight.ID]}
+	}
+	return views, nil
+}
+
 // Index serves the insights page.
 func (h *InsightsHandler) Index(w http.ResponseWriter, r *http.Request) {
 	isHTMX := r.Header.Get("HX-Request") == "true"
 
-	insightsList, err := h.store.GetRecentInsights(r.Context(), 20)
+	insightsList, err := h.curator.Active(r.Context(), "", 20)
 	if err != nil {
 		log.Printf("error getting insights: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	views, err := h.insightViews(r, insightsList)
+	if err != nil {
+		log.Printf("error getting insight details: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
 
 	templateName := "base"
 	if isHTMX {
@@ -46,7 +99,8 @@ func (h *InsightsHandler) Index(w http.ResponseWriter, r *http.Request) {
 	if err := h.templates.ExecuteTemplate(w, templateName, map[string]interface{}{
 		"Page":     "insights",
 		"Title":    "AI Insights",
-		"Insights": insightsList,
+		"Insights": views,
+		"Range":    dateRangeFrom(r),
 	}); err != nil {
 		log.Printf("error rendering template: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -60,33 +114,103 @@ func (h *InsightsHandler) Generate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get current analytics summary
-	dateRange := data.DateRange{} // TODO: Set appropriate date range
-	summary, err := h.store.GetAnalyticsSummary(r.Context(), dateRange)
+	// Build the prompt from analytics for the selected date range
+	prompt, err := h.prompts.Build(r.Context(), insights.PromptAnalytics, "", periodFrom(r))
 	if err != nil {
-		log.Printf("error getting analytics summary: %v", err)
+		log.Printf("error building prompt: %v", err)
 		http.Error(w, "Failed to get analytics data", http.StatusInternalServerError)
 		return
 	}
 
-	// Generate new insight
-	insight, err := h.llm.GenerateAnalyticsInsight(r.Context(), summary)
+	// Generate new insights
+	generated, err := h.llm.GenerateAnalyticsInsights(r.Context(), prompt.Text)
 	if err != nil {
-		log.Printf("error generating insight: %v", err)
-		http.Error(w, "Failed to generate insight", http.StatusInternalServerError)
+		log.Printf("error generating insights: %v", err)
+		message, status := generateError(err)
+		http.Error(w, message, status)
 		return
 	}
 
-	// Save the insight
-	if err := h.store.SaveInsight(r.Context(), insight); err != nil {
-		log.Printf("error saving insight: %v", err)
-		// Continue - we can still return the insight even if save fails
+	// Return the new insights as HTML
+	views := h.saveGenerated(r.Context(), generated)
+	if err := h.templates.ExecuteTemplate(w, "insights_generated", views); err != nil {
+		log.Printf("error rendering template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
+}
 
-	// Return the new insight as HTML
-	if err := h.templates.ExecuteTemplate(w, "insight_card", insight); err != nil {
+// Stream generates new insights like Generate, streaming the model's output
+// as server-sent "token" events while it is generated. The saved insights
+// are sent as HTML in a final "done" event, or the failure in an "error"
+// event. Closing the connection cancels generation.
+func (h *InsightsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	prompt, err := h.prompts.Build(r.Context(), insights.PromptAnalytics, "", periodFrom(r))
+	if err != nil {
+		log.Printf("error building prompt: %v", err)
+		http.Error(w, "Failed to get analytics data", http.StatusInternalServerError)
+		return
+	}
+
+	events, err := newSSEWriter(w)
+	if err != nil {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	generated, err := h.llm.StreamAnalyticsInsights(r.Context(), prompt.Text, func(token string) error {
+		return events.Send("token", token)
+	}, func(attempt int, problem error) error {
+		// The client clears the text streamed so far on a retry event.
+		log.Printf("retrying insight generation (attempt %d): %v", attempt, problem)
+		return events.Send("retry", fmt.Sprintf("The response couldn't be used, retrying (attempt %d)\u2026", attempt))
+	})
+	if err != nil {
+		if r.Context().Err() != nil {
+			return // The client went away
+		}
+		log.Printf("error streaming insights: %v", err)
+		message, _ := generateError(err)
+		events.Send("error", message)
+		return
+	}
+
+	// Save even if the client leaves now, since generation finished.
+	views := h.saveGenerated(context.WithoutCancel(r.Context()), generated)
+	var html bytes.Buffer
+	if err := h.templates.ExecuteTemplate(&html, "insights_generated", views); err != nil {
 		log.Printf("error rendering template: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		events.Send("error", "Internal server error")
+		return
+	}
+	events.Send("done", html.String())
+}
+
+// saveGenerated saves the generated insights that don't repeat recent
+// ones, returning views of them. Save failures are logged - the insights
+// can still be shown even if saving fails.
+func (h *InsightsHandler) saveGenerated(ctx context.Context, generated []*insights.GeneratedInsight) []insightView {
+	saved, err := h.curator.Save(ctx, generated)
+	if err != nil {
+		log.Printf("error saving insights: %v", err)
+		saved = generated
+	}
+	views := make([]insightView, len(saved))
+	for i, g := range saved {
+		views[i] = insightView{Insight: g.Insight, Detail: g.Detail}
+	}
+	return views
+}
+
+// generateError returns the message and status code to report for an
+// insight generation error.
+func generateError(err error) (string, int) {
+	switch {
+	case errors.Is(err, insights.ErrTimeout):
+		return "The language model took too long to respond", http.StatusGatewayTimeout
+	case errors.Is(err, insights.ErrModelNotFound):
+		return "The configured language model is not available", http.StatusBadGateway
+	default:
+		return "Failed to generate insight", http.StatusInternalServerError
 	}
 }
 
@@ -95,26 +219,85 @@ func (h *InsightsHandler) List(w http.ResponseWriter, r *http.Request) {
 	platformStr := r.URL.Query().Get("platform")
 	limit := 10 // Default
 
-	var insightsList []*data.Insight
-	var err error
-
-	if platformStr != "" {
-		insightsList, err = h.store.GetInsights(r.Context(), data.Platform(platformStr), limit)
-	} else {
-		insightsList, err = h.store.GetRecentInsights(r.Context(), limit)
-	}
-
+	insightsList, err := h.curator.Active(r.Context(), data.Platform(platformStr), limit)
 	if err != nil {
 		log.Printf("error getting insights: %v", err)
 		http.Error(w, "Failed to get insights", http.StatusInternalServerError)
 		return
 	}
+	views, err := h.insightViews(r, insightsList)
+	if err != nil {
+		log.Printf("error getting insight details: %v", err)
+		http.Error(w, "Failed to get insights", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "insight_views", views); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// Feedback rates an insight up (1), down (-1) or clears the rating (0),
+// returning the updated insight.
+func (h *InsightsHandler) Feedback(w http.ResponseWriter, r *http.Request) {
+	rating, err := strconv.Atoi(r.FormValue("rating"))
+	if err != nil || rating < data.RatingDown || rating > data.RatingUp {
+		http.Error(w, "Rating must be -1, 0 or 1", http.StatusBadRequest)
+		return
+	}
+	insight, ok := h.feedbackInsight(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.UpdateInsightFeedback(r.Context(), insight.ID, data.InsightFeedbackUpdate{Rating: &rating}); err != nil {
+		log.Printf("error saving insight feedback: %v", err)
+		http.Error(w, "Failed to save feedback", http.StatusInternalServerError)
+		return
+	}
 
-	if err := h.templates.ExecuteTemplate(w, "insights_list", insightsList); err != nil {
+	views, err := h.insightViews(r, []*data.Insight{insight})
+	if err != nil {
+		log.Printf("error getting insight details: %v", err)
+		http.Error(w, "Failed to get insight", http.StatusInternalServerError)
+		return
+	}
+	if err := h.templates.ExecuteTemplate(w, "insight_view", views[0]); err != nil {
 		log.Printf("error rendering template: %v", err)
 	}
 }
 
+// Dismiss hides an insight from the insights page. It returns an empty
+// body, which removes the insight's card.
+func (h *InsightsHandler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	insight, ok := h.feedbackInsight(w, r)
+	if !ok {
+		return
+	}
+	dismissed := true
+	if err := h.store.UpdateInsightFeedback(r.Context(), insight.ID, data.InsightFeedbackUpdate{Dismissed: &dismissed}); err != nil {
+		log.Printf("error dismissing insight: %v", err)
+		http.Error(w, "Failed to dismiss insight", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// feedbackInsight returns the insight named in the request path, writing
+// an error response and returning false if there isn't one.
+func (h *InsightsHandler) feedbackInsight(w http.ResponseWriter, r *http.Request) (*data.Insight, bool) {
+	insight, err := h.store.GetInsight(r.Context(), r.PathValue("id"))
+	if err != nil {
+		log.Printf("error getting insight: %v", err)
+		http.Error(w, "Failed to get insight", http.StatusInternalServerError)
+		return nil, false
+	}
+	if insight == nil {
+		http.Error(w, "Insight not found", http.StatusNotFound)
+		return nil, false
+	}
+	return insight, true
+}
+
 // Suggestions handles requests for content suggestions.
 func (h *InsightsHandler) Suggestions(w http.ResponseWriter, r *http.Request) {
 	platformStr := r.URL.Query().Get("platform")
@@ -123,8 +306,14 @@ func (h *InsightsHandler) Suggestions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: Get trend data and generate suggestions
-	suggestions, err := h.llm.GenerateContentSuggestions(r.Context(), data.Platform(platformStr), nil)
+	prompt, err := h.prompts.Build(r.Context(), insights.PromptSuggestions, data.Platform(platformStr), periodFrom(r))
+	if err != nil {
+		log.Printf("error building prompt: %v", err)
+		http.Error(w, "Failed to get analytics data", http.StatusInternalServerError)
+		return
+	}
+
+	suggestions, err := h.llm.GenerateContentSuggestions(r.Context(), prompt.Text)
 	if err != nil {
 		log.Printf("error generating suggestions: %v", err)
 		http.Error(w, "Failed to generate suggestions", http.StatusInternalServerError)
@@ -5,6 +5,7 @@ import (
 	"html/template"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/omnipulse/omnipulse/internal/data"
 	"github.com/omnipulse/omnipulse/internal/insights"
@@ -46,7 +47,7 @@ func (h *PlatformHandler) LinkedIn(w http.ResponseWriter, r *http.Request) {
 func (h *PlatformHandler) renderPlatformPage(w http.ResponseWriter, r *http.Request, platform data.Platform, title string) {
 	isHTMX := r.Header.Get("HX-Request") == "true"
 
-	analytics, err := h.aggregator.GetPlatformAnalytics(r.Context(), platform, 30)
+	analytics, err := h.aggregator.GetPlatformAnalytics(r.Context(), platform, periodFrom(r))
 	if err != nil {
 		log.Printf("error getting %s analytics: %v", platform, err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -63,6 +64,8 @@ func (h *PlatformHandler) renderPlatformPage(w http.ResponseWriter, r *http.Requ
 		"Title":     title,
 		"Platform":  platform,
 		"Analytics": analytics,
+		"Charts":    platformTrendCharts(platform, analytics),
+		"Range":     dateRangeFrom(r),
 	}); err != nil {
 		log.Printf("error rendering template: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -78,7 +81,11 @@ func (h *PlatformHandler) PlatformCard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	platform := data.Platform(platformStr)
-	analytics, err := h.aggregator.GetPlatformAnalytics(r.Context(), platform, 7)
+	if !validPlatform(platform) {
+		http.Error(w, "Invalid platform", http.StatusBadRequest)
+		return
+	}
+	analytics, err := h.aggregator.GetPlatformAnalytics(r.Context(), platform, periodFrom(r))
 	if err != nil {
 		log.Printf("error getting platform card data: %v", err)
 		http.Error(w, "Failed to get platform data", http.StatusInternalServerError)
@@ -94,6 +101,8 @@ func (h *PlatformHandler) PlatformCard(w http.ResponseWriter, r *http.Request) {
 }
 
 // Content handles HTMX requests for platform content lists.
+// Supports sort (date, views, engagement), order (asc, desc), page and
+// per_page query parameters.
 func (h *PlatformHandler) Content(w http.ResponseWriter, r *http.Request) {
 	platformStr := r.URL.Query().Get("platform")
 	if platformStr == "" {
@@ -102,36 +111,111 @@ func (h *PlatformHandler) Content(w http.ResponseWriter, r *http.Request) {
 	}
 
 	platform := data.Platform(platformStr)
-	limit := 10  // Default limit
-	offset := 0  // Default offset
-
-	// TODO: Parse limit and offset from query parameters
-
-	var content interface{}
-	var err error
-
-	switch platform {
-	case data.PlatformYouTube:
-		content, err = h.store.GetVideos(r.Context(), limit, offset)
-	case data.PlatformX:
-		content, err = h.store.GetTweets(r.Context(), limit, offset)
-	case data.PlatformLinkedIn:
-		content, err = h.store.GetLinkedInPosts(r.Context(), limit, offset)
-	default:
+	if !validPlatform(platform) {
 		http.Error(w, "Invalid platform", http.StatusBadRequest)
 		return
 	}
 
+	view := contentListView{
+		Platform:  platform,
+		Sort:      data.ContentSort(r.URL.Query().Get("sort")),
+		Ascending: r.URL.Query().Get("order") == "asc",
+		Page:      queryInt(r, "page", 1, 1, 0),
+		PerPage:   queryInt(r, "per_page", 10, 1, 100),
+	}
+	if !view.Sort.Valid() {
+		view.Sort = data.SortByDate
+	}
+
+	items, total, err := h.store.ListContent(r.Context(), data.ContentQuery{
+		Platform:  platform,
+		DateRange: periodFrom(r).Current,
+		Sort:      view.Sort,
+		Ascending: view.Ascending,
+		Limit:     view.PerPage,
+		Offset:    (view.Page - 1) * view.PerPage,
+	})
 	if err != nil {
 		log.Printf("error getting content: %v", err)
 		http.Error(w, "Failed to get content", http.StatusInternalServerError)
 		return
 	}
+	view.Items = items
+	view.Total = total
 
-	if err := h.templates.ExecuteTemplate(w, "content_list", map[string]interface{}{
-		"Platform": platform,
-		"Content":  content,
-	}); err != nil {
+	if err := h.templates.ExecuteTemplate(w, "content_list", view); err != nil {
 		log.Printf("error rendering template: %v", err)
 	}
 }
+
+// contentListView is the template data for a page of content.
+type contentListView struct {
+	Platform  data.Platform
+	Items     []*data.ContentItem
+	Sort      data.ContentSort
+	Ascending bool
+	Page      int
+	PerPage   int
+	Total     int
+}
+
+// Pages returns the total number of pages.
+func (v contentListView) Pages() int {
+	if v.Total == 0 {
+		return 1
+	}
+	return (v.Total + v.PerPage - 1) / v.PerPage
+}
+
+// HasPrev reports whether there is a previous page.
+func (v contentListView) HasPrev() bool {
+	return v.Page > 1
+}
+
+// HasNext reports whether there is a next page.
+func (v contentListView) HasNext() bool {
+	return v.Page < v.Pages()
+}
+
+// PrevPage returns the previous page number.
+func (v contentListView) PrevPage() int {
+	return v.Page - 1
+}
+
+// NextPage returns the next page number.
+func (v contentListView) NextPage() int {
+	return v.Page + 1
+}
+
+// Order returns the sort order query value.
+func (v contentListView) Order() string {
+	if v.Ascending {
+		return "asc"
+	}
+	return "desc"
+}
+
+// validPlatform reports whether p is a supported platform.
+func validPlatform(p data.Platform) bool {
+	switch p {
+	case data.PlatformYouTube, data.PlatformX, data.PlatformLinkedIn:
+		return true
+	}
+	return false
+}
+
+// queryInt parses an integer query parameter, returning def if it is
+// missing or invalid and clamping it to [min, max] (max <= 0 means no max).
+func queryInt(r *http.Request, key string, def, min, max int) int {
+	v, err := strconv.Atoi(r.URL.Query().Get(key))
+	if err != nil {
+		return def
+	}
+	if v < min {
+		return min
+	}
+	if max > 0 && v > max {
+		return max
+	}
+	return v
+}
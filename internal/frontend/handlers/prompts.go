@@ -0,0 +1,177 @@
+// Package handlers provides HTTP handlers for editing and previewing prompt
+// templates.
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/insights"
+)
+
+// promptEditorView is the data for the prompt template editor.
+type promptEditorView struct {
+	Names    []string
+	Name     string
+	Platform data.Platform // Preview data platform for suggestion prompts
+	Template string
+	Custom   bool // Whether the template has been edited
+	Preview  *insights.Prompt
+	Message  string
+	Error    string
+	Range    DateRange
+}
+
+// Suggestions reports whether the editor shows a suggestions prompt, which
+// is rendered per platform.
+func (v *promptEditorView) Suggestions() bool {
+	return v.Name == insights.PromptSuggestions
+}
+
+// Prompts serves the prompt template editor, previewing the prompt that
+// would be sent for the selected date range.
+func (h *InsightsHandler) Prompts(w http.ResponseWriter, r *http.Request) {
+	view, ok := h.promptEditor(w, r, r.URL.Query().Get("name"))
+	if !ok {
+		return
+	}
+	if view.Template, view.Custom, ok = h.currentTemplate(w, r, view.Name); !ok {
+		return
+	}
+	h.renderPreview(r, view, view.Template)
+
+	templateName := "base"
+	if r.Header.Get("HX-Request") == "true" {
+		templateName = "insight_prompts"
+	}
+	if err := h.templates.ExecuteTemplate(w, templateName, map[string]interface{}{
+		"Page":   "insight_prompts",
+		"Title":  "Prompt Templates",
+		"Editor": view,
+		"Range":  view.Range,
+	}); err != nil {
+		log.Printf("error rendering template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// PreviewPrompt renders the submitted template without saving it.
+func (h *InsightsHandler) PreviewPrompt(w http.ResponseWriter, r *http.Request) {
+	view, ok := h.promptEditor(w, r, r.PathValue("name"))
+	if !ok {
+		return
+	}
+	view.Template = r.FormValue("template")
+	if _, view.Custom, ok = h.currentTemplate(w, r, view.Name); !ok {
+		return
+	}
+	h.renderPreview(r, view, view.Template)
+	h.renderPromptEditor(w, view)
+}
+
+// SavePrompt saves the submitted template if it renders.
+func (h *InsightsHandler) SavePrompt(w http.ResponseWriter, r *http.Request) {
+	view, ok := h.promptEditor(w, r, r.PathValue("name"))
+	if !ok {
+		return
+	}
+	view.Template = r.FormValue("template")
+
+	err := h.prompts.SaveTemplate(r.Context(), view.Name, view.Template)
+	switch {
+	case errors.Is(err, insights.ErrInvalidTemplate):
+		view.Error = err.Error()
+		_, view.Custom, _ = h.prompts.Template(r.Context(), view.Name)
+	case err != nil:
+		log.Printf("error saving prompt template: %v", err)
+		http.Error(w, "Failed to save template", http.StatusInternalServerError)
+		return
+	default:
+		view.Custom = true
+		view.Message = "Template saved."
+		h.renderPreview(r, view, view.Template)
+	}
+	h.renderPromptEditor(w, view)
+}
+
+// ResetPrompt restores the built-in template.
+func (h *InsightsHandler) ResetPrompt(w http.ResponseWriter, r *http.Request) {
+	view, ok := h.promptEditor(w, r, r.PathValue("name"))
+	if !ok {
+		return
+	}
+	if err := h.prompts.ResetTemplate(r.Context(), view.Name); err != nil {
+		log.Printf("error resetting prompt template: %v", err)
+		http.Error(w, "Failed to reset template", http.StatusInternalServerError)
+		return
+	}
+	view.Template, _ = h.prompts.DefaultTemplate(view.Name)
+	view.Message = "Template reset to the default."
+	h.renderPreview(r, view, view.Template)
+	h.renderPromptEditor(w, view)
+}
+
+// promptEditor creates the editor view for the named template, writing an
+// error response and returning false if the request is invalid.
+func (h *InsightsHandler) promptEditor(w http.ResponseWriter, r *http.Request, name string) (*promptEditorView, bool) {
+	if name == "" {
+		name = insights.PromptAnalytics
+	}
+	if _, err := h.prompts.DefaultTemplate(name); err != nil {
+		http.Error(w, "Unknown prompt template", http.StatusNotFound)
+		return nil, false
+	}
+
+	view := &promptEditorView{
+		Names: insights.PromptNames,
+		Name:  name,
+		Range: dateRangeFrom(r),
+	}
+	if view.Suggestions() {
+		view.Platform = data.Platform(r.FormValue("platform"))
+		if view.Platform == "" {
+			view.Platform = data.PlatformYouTube
+		}
+		if !validPlatform(view.Platform) {
+			http.Error(w, "Invalid platform", http.StatusBadRequest)
+			return nil, false
+		}
+	}
+	return view, true
+}
+
+// currentTemplate returns the saved template for name, writing an error
+// response and returning false if it can't be loaded.
+func (h *InsightsHandler) currentTemplate(w http.ResponseWriter, r *http.Request, name string) (string, bool, bool) {
+	text, custom, err := h.prompts.Template(r.Context(), name)
+	if err != nil {
+		log.Printf("error getting prompt template: %v", err)
+		http.Error(w, "Failed to load template", http.StatusInternalServerError)
+		return "", false, false
+	}
+	return text, custom, true
+}
+
+// renderPreview renders text with the selected period's data into
+// view.Preview, or sets view.Error if it fails.
+func (h *InsightsHandler) renderPreview(r *http.Request, view *promptEditorView, text string) {
+	promptData, err := h.prompts.Data(r.Context(), view.Platform, view.Range.Period())
+	if err != nil {
+		log.Printf("error getting prompt data: %v", err)
+		view.Error = "Failed to get analytics data for the preview."
+		return
+	}
+	if view.Preview, err = h.prompts.Render(view.Name, text, promptData); err != nil {
+		view.Error = err.Error()
+	}
+}
+
+// renderPromptEditor writes the editor partial.
+func (h *InsightsHandler) renderPromptEditor(w http.ResponseWriter, view *promptEditorView) {
+	if err := h.templates.ExecuteTemplate(w, "prompt_editor", view); err != nil {
+		log.Printf("error rendering template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,114 @@
+// Package handlers provides HTTP route registration for the HTMX frontend.
+package handlers
+
+import (
+	"net/http"
+)
+
+// Handlers groups the HTTP handlers served by the dashboard.
+type Handlers struct {
+	Dashboard *DashboardHandler
+	Platform  *PlatformHandler
+	Content   *ContentHandler
+	Inbox     *InboxHandler
+	Insights  *InsightsHandler
+	Sentiment *SentimentHandler
+	Topics    *TopicsHandler
+	Trends    *TrendsHandler
+	Goals     *GoalsHandler
+	BestTime  *BestTimeHandler
+	Entities  *EntitiesHandler
+	Campaigns *CampaignsHandler
+	Chat      *ChatHandler
+	Search    *SearchHandler
+	Status    *StatusHandler
+	Backfill  *BackfillHandler
+}
+
+// NewRouter registers all dashboard routes and wraps them in the shared
+// middleware. Static assets are served from staticDir under /static/.
+func NewRouter(h Handlers, staticDir string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.Dir(staticDir))))
+
+	// Pages
+	mux.HandleFunc("GET /{$}", h.Dashboard.Index)
+	mux.HandleFunc("GET /youtube", h.Platform.YouTube)
+	mux.HandleFunc("GET /x", h.Platform.X)
+	mux.HandleFunc("GET /linkedin", h.Platform.LinkedIn)
+	mux.HandleFunc("GET /content/{platform}/{id}", h.Content.Detail)
+	mux.HandleFunc("GET /inbox", h.Inbox.Index)
+	mux.HandleFunc("GET /insights", h.Insights.Index)
+	mux.HandleFunc("GET /insights/prompts", h.Insights.Prompts)
+	mux.HandleFunc("GET /topics", h.Topics.Index)
+	mux.HandleFunc("GET /goals", h.Goals.Index)
+	mux.HandleFunc("GET /best-time", h.BestTime.Index)
+	mux.HandleFunc("GET /entities", h.Entities.Index)
+	mux.HandleFunc("GET /campaigns", h.Campaigns.Index)
+	mux.HandleFunc("GET /campaigns/{id}", h.Campaigns.Detail)
+	mux.HandleFunc("GET /chat", h.Chat.Index)
+	mux.HandleFunc("GET /chat/{id}", h.Chat.Index)
+	mux.HandleFunc("GET /search", h.Search.Index)
+	mux.HandleFunc("GET /status", h.Status.Index)
+
+	// HTMX partials
+	mux.HandleFunc("GET /api/dashboard/refresh", h.Dashboard.Refresh)
+	mux.HandleFunc("GET /api/dashboard/summary", h.Dashboard.Summary)
+	mux.HandleFunc("GET /api/dashboard/health", h.Dashboard.Health)
+	mux.HandleFunc("GET /api/dashboard/movers", h.Trends.Movers)
+	mux.HandleFunc("GET /api/platform/card", h.Platform.PlatformCard)
+	mux.HandleFunc("GET /api/platform/content", h.Platform.Content)
+	mux.HandleFunc("GET /api/platform/metrics", h.Trends.Metrics)
+	mux.HandleFunc("POST /api/anomalies/method", h.Trends.SetAnomalyMethod)
+	mux.HandleFunc("GET /api/inbox/list", h.Inbox.List)
+	mux.HandleFunc("POST /api/inbox/comments/{id}/{action}", h.Inbox.UpdateState)
+	mux.HandleFunc("GET /api/insights/list", h.Insights.List)
+	mux.HandleFunc("POST /api/insights/generate", h.Insights.Generate)
+	mux.HandleFunc("GET /api/insights/stream", h.Insights.Stream)
+	mux.HandleFunc("GET /api/insights/suggestions", h.Insights.Suggestions)
+	mux.HandleFunc("POST /api/insights/items/{id}/feedback", h.Insights.Feedback)
+	mux.HandleFunc("POST /api/insights/items/{id}/dismiss", h.Insights.Dismiss)
+	mux.HandleFunc("POST /api/insights/prompts/{name}/preview", h.Insights.PreviewPrompt)
+	mux.HandleFunc("POST /api/insights/prompts/{name}", h.Insights.SavePrompt)
+	mux.HandleFunc("POST /api/insights/prompts/{name}/reset", h.Insights.ResetPrompt)
+	mux.HandleFunc("GET /api/sentiment/chart", h.Sentiment.Chart)
+	mux.HandleFunc("POST /api/topics/refresh", h.Topics.Refresh)
+	mux.HandleFunc("POST /api/goals", h.Goals.Create)
+	mux.HandleFunc("POST /api/goals/{id}/delete", h.Goals.Delete)
+	mux.HandleFunc("POST /api/best-time/insights", h.BestTime.SaveInsights)
+	mux.HandleFunc("GET /api/entities/report", h.Entities.Report)
+	mux.HandleFunc("POST /api/entities/scan", h.Entities.Scan)
+	mux.HandleFunc("POST /api/campaigns", h.Campaigns.Create)
+	mux.HandleFunc("POST /api/campaigns/{id}/delete", h.Campaigns.Delete)
+	mux.HandleFunc("POST /api/campaigns/{id}/content", h.Campaigns.AddContent)
+	mux.HandleFunc("POST /api/campaigns/{id}/content/{platform}/{content}/delete", h.Campaigns.RemoveContent)
+	mux.HandleFunc("POST /api/chat/messages", h.Chat.Ask)
+	mux.HandleFunc("GET /api/search/results", h.Search.Results)
+	mux.HandleFunc("GET /api/status/tasks", h.Status.Tasks)
+	mux.HandleFunc("GET /api/status/runs", h.Status.Runs)
+	mux.HandleFunc("POST /api/tasks/{name}/run", h.Status.RunTask)
+	mux.HandleFunc("GET /api/backfill/jobs", h.Backfill.Jobs)
+	mux.HandleFunc("POST /api/backfill/{platform}", h.Backfill.Start)
+
+	// JSON API
+	mux.HandleFunc("GET /api/v1/search", h.Search.API)
+	mux.HandleFunc("GET /api/v1/sentiment", h.Sentiment.API)
+	mux.HandleFunc("GET /api/v1/topics", h.Topics.API)
+	mux.HandleFunc("GET /api/v1/movers", h.Trends.APIMovers)
+	mux.HandleFunc("GET /api/v1/compare", h.Trends.APICompare)
+	mux.HandleFunc("GET /api/v1/anomalies", h.Trends.APIAnomalies)
+	mux.HandleFunc("GET /api/v1/forecast", h.Trends.APIForecast)
+	mux.HandleFunc("GET /api/v1/goals", h.Goals.API)
+	mux.HandleFunc("GET /api/v1/best-time", h.BestTime.API)
+	mux.HandleFunc("GET /api/v1/entities", h.Entities.API)
+	mux.HandleFunc("GET /api/v1/campaigns", h.Campaigns.APIList)
+	mux.HandleFunc("GET /api/v1/campaigns/suggestions", h.Campaigns.APISuggestions)
+	mux.HandleFunc("GET /api/v1/campaigns/{id}", h.Campaigns.APIDetail)
+	mux.HandleFunc("GET /api/v1/tasks", h.Status.APITasks)
+	mux.HandleFunc("POST /api/v1/tasks/{name}/run", h.Status.APIRunTask)
+	mux.HandleFunc("GET /api/v1/backfill", h.Backfill.APIJobs)
+	mux.HandleFunc("POST /api/v1/backfill/{platform}", h.Backfill.APIStart)
+
+	return DateRangeMiddleware(mux)
+}
@@ -0,0 +1,174 @@
+// Package handlers provides HTTP handlers for full-text search.
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// searchPageSize is the number of results returned when no limit is given.
+const searchPageSize = 25
+
+// SearchHandler handles search requests.
+type SearchHandler struct {
+	store     storage.Store
+	templates *template.Template
+}
+
+// NewSearchHandler creates a new SearchHandler.
+func NewSearchHandler(store storage.Store, templates *template.Template) *SearchHandler {
+	return &SearchHandler{
+		store:     store,
+		templates: templates,
+	}
+}
+
+// Index serves the search page.
+func (h *SearchHandler) Index(w http.ResponseWriter, r *http.Request) {
+	isHTMX := r.Header.Get("HX-Request") == "true"
+
+	query := searchQueryFrom(r)
+	results, err := h.store.Search(r.Context(), query)
+	if err != nil {
+		log.Printf("error searching: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	templateName := "base"
+	if isHTMX {
+		templateName = "search"
+	}
+
+	if err := h.templates.ExecuteTemplate(w, templateName, map[string]interface{}{
+		"Page":    "search",
+		"Title":   "Search",
+		"Query":   query,
+		"Results": searchResultViews(results),
+		"Range":   dateRangeFrom(r),
+	}); err != nil {
+		log.Printf("error rendering template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// Results handles HTMX requests for search results as the user types.
+func (h *SearchHandler) Results(w http.ResponseWriter, r *http.Request) {
+	query := searchQueryFrom(r)
+	results, err := h.store.Search(r.Context(), query)
+	if err != nil {
+		log.Printf("error searching: %v", err)
+		http.Error(w, "Search failed", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "search_results", map[string]interface{}{
+		"Query":   query,
+		"Results": searchResultViews(results),
+	}); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// API serves search results as JSON at /api/v1/search. It accepts q,
+// platform, kind, limit and offset query parameters.
+func (h *SearchHandler) API(w http.ResponseWriter, r *http.Request) {
+	query := searchQueryFrom(r)
+	if query.Text == "" {
+		writeJSONError(w, http.StatusBadRequest, "missing q parameter")
+		return
+	}
+	if p := r.URL.Query().Get("platform"); p != "" && query.Platform == "" {
+		writeJSONError(w, http.StatusBadRequest, "invalid platform")
+		return
+	}
+	if k := r.URL.Query().Get("kind"); k != "" && query.Kind == "" {
+		writeJSONError(w, http.StatusBadRequest, "invalid kind")
+		return
+	}
+
+	results, err := h.store.Search(r.Context(), query)
+	if err != nil {
+		log.Printf("error searching: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "search failed")
+		return
+	}
+	if results == nil {
+		results = []*data.SearchResult{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"query":   query.Text,
+		"results": results,
+	})
+}
+
+// searchQueryFrom parses search parameters from the request. Invalid
+// platform and kind values are dropped.
+func searchQueryFrom(r *http.Request) data.SearchQuery {
+	q := r.URL.Query()
+	query := data.SearchQuery{
+		Text:     strings.TrimSpace(q.Get("q")),
+		Platform: data.Platform(q.Get("platform")),
+		Kind:     data.SearchKind(q.Get("kind")),
+		Limit:    queryInt(r, "limit", searchPageSize, 1, 100),
+		Offset:   queryInt(r, "offset", 0, 0, 0),
+	}
+	if query.Platform != "" && !validPlatform(query.Platform) {
+		query.Platform = ""
+	}
+	if query.Kind != "" && !query.Kind.Valid() {
+		query.Kind = ""
+	}
+	return query
+}
+
+// searchResultView wraps a search result for rendering.
+type searchResultView struct {
+	*data.SearchResult
+}
+
+// SnippetHTML returns the snippet, which the store has already escaped.
+func (v searchResultView) SnippetHTML() template.HTML {
+	return template.HTML(v.Snippet)
+}
+
+// Link returns the page for the result, or an empty string if there is
+// no page for it.
+func (v searchResultView) Link() string {
+	switch v.Kind {
+	case data.SearchKindContent, data.SearchKindComment:
+		return "/content/" + string(v.Platform) + "/" + v.ContentID
+	case data.SearchKindInsight:
+		return "/insights"
+	}
+	return ""
+}
+
+func searchResultViews(results []*data.SearchResult) []searchResultView {
+	views := make([]searchResultView, len(results))
+	for i, r := range results {
+		views[i] = searchResultView{r}
+	}
+	return views
+}
+
+// writeJSON writes v as a JSON response with the given status.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("error encoding JSON response: %v", err)
+	}
+}
+
+// writeJSONError writes a JSON error response.
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
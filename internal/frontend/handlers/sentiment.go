@@ -0,0 +1,119 @@
+// Package handlers provides HTTP handlers for comment sentiment trends.
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// SentimentHandler serves comment sentiment trends for a platform or a
+// single content item.
+type SentimentHandler struct {
+	store     storage.Store
+	templates *template.Template
+}
+
+// NewSentimentHandler creates a new SentimentHandler.
+func NewSentimentHandler(store storage.Store, templates *template.Template) *SentimentHandler {
+	return &SentimentHandler{
+		store:     store,
+		templates: templates,
+	}
+}
+
+// sentimentView is the template data for a sentiment trend chart.
+type sentimentView struct {
+	Points   []*data.SentimentPoint
+	Chart    template.HTML
+	Positive int
+	Neutral  int
+	Negative int
+}
+
+// Total returns the number of labeled comments in the period.
+func (v *sentimentView) Total() int {
+	return v.Positive + v.Neutral + v.Negative
+}
+
+// Net returns the share of positive minus the share of negative comments,
+// as a percentage from -100 to 100.
+func (v *sentimentView) Net() float64 {
+	if v.Total() == 0 {
+		return 0
+	}
+	return float64(v.Positive-v.Negative) / float64(v.Total()) * 100
+}
+
+// Chart handles HTMX requests for the sentiment trend chart of a platform
+// (platform parameter, empty for all) or content item (content_id) over
+// the selected date range.
+func (h *SentimentHandler) Chart(w http.ResponseWriter, r *http.Request) {
+	query, ok := sentimentQueryFrom(r)
+	if !ok {
+		http.Error(w, "Invalid platform", http.StatusBadRequest)
+		return
+	}
+
+	points, err := h.store.GetSentimentTrend(r.Context(), query)
+	if err != nil {
+		log.Printf("error getting sentiment trend: %v", err)
+		http.Error(w, "Failed to get sentiment", http.StatusInternalServerError)
+		return
+	}
+
+	view := &sentimentView{Points: points, Chart: sentimentChart(points)}
+	for _, p := range points {
+		view.Positive += p.Positive
+		view.Neutral += p.Neutral
+		view.Negative += p.Negative
+	}
+	if err := h.templates.ExecuteTemplate(w, "sentiment_chart", view); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// API returns the daily sentiment trend as JSON, with the same parameters
+// as Chart.
+func (h *SentimentHandler) API(w http.ResponseWriter, r *http.Request) {
+	query, ok := sentimentQueryFrom(r)
+	if !ok {
+		writeJSONError(w, http.StatusBadRequest, "invalid platform")
+		return
+	}
+
+	points, err := h.store.GetSentimentTrend(r.Context(), query)
+	if err != nil {
+		log.Printf("error getting sentiment trend: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to get sentiment")
+		return
+	}
+	if points == nil {
+		points = []*data.SentimentPoint{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"platform":   query.Platform,
+		"content_id": query.ContentID,
+		"start":      query.DateRange.Start,
+		"end":        query.DateRange.End,
+		"points":     points,
+	})
+}
+
+// sentimentQueryFrom parses sentiment trend parameters from the request,
+// returning false if the platform is invalid.
+func sentimentQueryFrom(r *http.Request) (data.SentimentQuery, bool) {
+	query := data.SentimentQuery{
+		Platform:  data.Platform(r.URL.Query().Get("platform")),
+		ContentID: r.URL.Query().Get("content_id"),
+		DateRange: periodFrom(r).Current,
+	}
+	if query.Platform != "" && !validPlatform(query.Platform) {
+		return query, false
+	}
+	return query, true
+}
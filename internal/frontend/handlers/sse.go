@@ -0,0 +1,49 @@
+// Package handlers provides a writer for server-sent event responses.
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// errStreamingUnsupported is returned when the response can't be flushed
+// incrementally.
+var errStreamingUnsupported = errors.New("streaming unsupported")
+
+// sseWriter writes server-sent events, flushing each one to the client.
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// newSSEWriter starts an event stream response.
+func newSSEWriter(w http.ResponseWriter) (*sseWriter, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errStreamingUnsupported
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &sseWriter{w: w, flusher: flusher}, nil
+}
+
+// Send writes an event. Multi-line data is sent as one data field per
+// line, which the browser joins back together with newlines.
+func (s *sseWriter) Send(event, data string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "event: %s\n", event)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	if _, err := s.w.Write([]byte(b.String())); err != nil {
+		return fmt.Errorf("writing event: %w", err)
+	}
+	s.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,203 @@
+// Package handlers provides HTTP handlers for the scheduler status page.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/scheduler"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// recentRunsLimit is the number of task runs shown on the status page.
+const recentRunsLimit = 50
+
+// forwardedHeader marks task runs forwarded from another replica, so a
+// replica that has just lost the lease doesn't forward them again.
+const forwardedHeader = "X-OmniPulse-Forwarded"
+
+// StatusHandler handles scheduler status requests.
+type StatusHandler struct {
+	scheduler *scheduler.Scheduler
+	store     storage.Store
+	templates *template.Template
+}
+
+// NewStatusHandler creates a new StatusHandler.
+func NewStatusHandler(sched *scheduler.Scheduler, store storage.Store, templates *template.Template) *StatusHandler {
+	return &StatusHandler{
+		scheduler: sched,
+		store:     store,
+		templates: templates,
+	}
+}
+
+// Index serves the status page.
+func (h *StatusHandler) Index(w http.ResponseWriter, r *http.Request) {
+	isHTMX := r.Header.Get("HX-Request") == "true"
+
+	runs, err := h.store.GetTaskRuns(r.Context(), "", recentRunsLimit)
+	if err != nil {
+		log.Printf("error getting task runs: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	templateName := "base"
+	if isHTMX {
+		templateName = "status"
+	}
+
+	if err := h.templates.ExecuteTemplate(w, templateName, map[string]interface{}{
+		"Page":   "status",
+		"Title":  "Status",
+		"Status": h.statusView(),
+		"Runs":   runs,
+		"Range":  dateRangeFrom(r),
+	}); err != nil {
+		log.Printf("error rendering template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// Tasks handles HTMX requests for the task table.
+func (h *StatusHandler) Tasks(w http.ResponseWriter, r *http.Request) {
+	if err := h.templates.ExecuteTemplate(w, "status_tasks", h.statusView()); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// Runs handles HTMX requests for the recent runs of one or all tasks.
+func (h *StatusHandler) Runs(w http.ResponseWriter, r *http.Request) {
+	runs, err := h.store.GetTaskRuns(r.Context(), r.URL.Query().Get("task"), recentRunsLimit)
+	if err != nil {
+		log.Printf("error getting task runs: %v", err)
+		http.Error(w, "Failed to get task runs", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "status_runs", runs); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// RunTask handles HTMX requests to run a task now. The task runs in the
+// background, on the leader replica, and the refreshed task table is
+// returned.
+func (h *StatusHandler) RunTask(w http.ResponseWriter, r *http.Request) {
+	if err := h.trigger(r, r.PathValue("name")); err != nil {
+		if errors.Is(err, scheduler.ErrTaskNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if errors.Is(err, scheduler.ErrNotLeader) {
+			http.Error(w, "No scheduler leader is available", http.StatusServiceUnavailable)
+			return
+		}
+		log.Printf("error running task: %v", err)
+		http.Error(w, "Failed to run task", http.StatusInternalServerError)
+		return
+	}
+
+	h.Tasks(w, r)
+}
+
+// APITasks serves task status as JSON at /api/v1/tasks.
+func (h *StatusHandler) APITasks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.statusView())
+}
+
+// APIRunTask starts a task at /api/v1/tasks/{name}/run.
+func (h *StatusHandler) APIRunTask(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := h.trigger(r, name); err != nil {
+		if errors.Is(err, scheduler.ErrTaskNotFound) {
+			writeJSONError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, scheduler.ErrNotLeader) {
+			writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		log.Printf("error running task: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to run task")
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"task": name, "status": "started"})
+}
+
+// trigger starts a task in the background, forwarding it to the leader if
+// another replica holds the scheduler lease.
+func (h *StatusHandler) trigger(r *http.Request, name string) error {
+	if h.scheduler == nil {
+		return scheduler.ErrTaskNotFound
+	}
+	err := h.scheduler.TriggerTask(name)
+	if errors.Is(err, scheduler.ErrNotLeader) && r.Header.Get(forwardedHeader) == "" {
+		return forwardTaskRun(r.Context(), h.scheduler.LeaderStatus(), name)
+	}
+	return err
+}
+
+// forwardTaskRun asks the leader replica to run a task.
+func forwardTaskRun(ctx context.Context, leader scheduler.LeaderStatus, name string) error {
+	if leader.LeaderAddress == "" || leader.IsLeader {
+		return scheduler.ErrNotLeader
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		leader.LeaderAddress+"/api/v1/tasks/"+url.PathEscape(name)+"/run", nil)
+	if err != nil {
+		return fmt.Errorf("creating forwarded request: %w", err)
+	}
+	req.Header.Set(forwardedHeader, leader.Instance)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("forwarding to leader %s: %w", leader.Leader, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: %s", scheduler.ErrTaskNotFound, name)
+	case resp.StatusCode == http.StatusServiceUnavailable:
+		return scheduler.ErrNotLeader
+	case resp.StatusCode >= 400:
+		return fmt.Errorf("leader %s returned status %d", leader.Leader, resp.StatusCode)
+	}
+	log.Printf("Forwarded task %s to leader %s", name, leader.Leader)
+	return nil
+}
+
+// statusView is the scheduler state shown on the status page and returned
+// by the tasks API.
+type statusView struct {
+	Running  bool                      `json:"running"`
+	Leader   scheduler.LeaderStatus    `json:"leader"`
+	Tasks    []scheduler.TaskInfo      `json:"tasks"`
+	Breakers []scheduler.BreakerStatus `json:"breakers"`
+}
+
+func (h *StatusHandler) statusView() *statusView {
+	view := &statusView{
+		Tasks:    []scheduler.TaskInfo{},
+		Breakers: []scheduler.BreakerStatus{},
+	}
+	if h.scheduler != nil {
+		view.Running = h.scheduler.IsRunning()
+		view.Leader = h.scheduler.LeaderStatus()
+		view.Tasks = h.scheduler.GetTasks()
+		view.Breakers = h.scheduler.GetBreakers()
+	}
+	return view
+}
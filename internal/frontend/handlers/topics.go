@@ -0,0 +1,104 @@
+// Package handlers provides HTTP handlers for topics.
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/insights"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// topicExamples is the number of representative examples shown per topic.
+const topicExamples = 3
+
+// TopicsHandler serves the topics that content and comments are grouped
+// into, with their engagement over the selected date range.
+type TopicsHandler struct {
+	store     storage.Store
+	modeler   *insights.TopicModeler
+	templates *template.Template
+}
+
+// NewTopicsHandler creates a new TopicsHandler.
+func NewTopicsHandler(store storage.Store, modeler *insights.TopicModeler, templates *template.Template) *TopicsHandler {
+	return &TopicsHandler{
+		store:     store,
+		modeler:   modeler,
+		templates: templates,
+	}
+}
+
+// Index serves the topics page.
+func (h *TopicsHandler) Index(w http.ResponseWriter, r *http.Request) {
+	isHTMX := r.Header.Get("HX-Request") == "true"
+
+	stats, err := h.store.GetTopicStats(r.Context(), periodFrom(r).Current, topicExamples)
+	if err != nil {
+		log.Printf("error getting topic stats: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	templateName := "base"
+	if isHTMX {
+		templateName = "topics"
+	}
+
+	if err := h.templates.ExecuteTemplate(w, templateName, map[string]interface{}{
+		"Page":   "topics",
+		"Title":  "Topics",
+		"Topics": stats,
+		"Range":  dateRangeFrom(r),
+	}); err != nil {
+		log.Printf("error rendering template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// Refresh embeds new content and comments, regroups them into topics and
+// returns the updated topic list.
+func (h *TopicsHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	if err := h.modeler.Run(r.Context()); err != nil {
+		log.Printf("error refreshing topics: %v", err)
+		message, status := generateError(err)
+		if status == http.StatusInternalServerError {
+			message = "Failed to refresh topics"
+		}
+		http.Error(w, message, status)
+		return
+	}
+
+	stats, err := h.store.GetTopicStats(r.Context(), periodFrom(r).Current, topicExamples)
+	if err != nil {
+		log.Printf("error getting topic stats: %v", err)
+		http.Error(w, "Failed to get topics", http.StatusInternalServerError)
+		return
+	}
+	if err := h.templates.ExecuteTemplate(w, "topics_list", stats); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// API returns the topics with their activity over the selected date range
+// as JSON.
+func (h *TopicsHandler) API(w http.ResponseWriter, r *http.Request) {
+	dateRange := periodFrom(r).Current
+	stats, err := h.store.GetTopicStats(r.Context(), dateRange, topicExamples)
+	if err != nil {
+		log.Printf("error getting topic stats: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to get topics")
+		return
+	}
+	if stats == nil {
+		stats = []*data.TopicStats{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"start":  dateRange.Start,
+		"end":    dateRange.End,
+		"topics": stats,
+	})
+}
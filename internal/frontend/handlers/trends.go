@@ -0,0 +1,335 @@
+// Package handlers provides HTTP handlers for period-over-period metric
+// comparisons and daily metric charts with their anomalies and forecasts.
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/insights"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+const (
+	// moversLimit is the number of metrics listed in the biggest movers
+	// widget.
+	moversLimit = 8
+	// chartForecastDays is how far ahead metric charts are projected.
+	chartForecastDays = 14
+	// defaultForecastDays is how far ahead the forecast API projects by
+	// default.
+	defaultForecastDays = 30
+)
+
+// TrendsHandler serves week-over-week, month-over-month and year-over-year
+// comparisons of platform metrics, and their daily charts annotated with
+// stored anomalies.
+type TrendsHandler struct {
+	store     storage.Store
+	analyzer  *insights.TrendAnalyzer
+	templates *template.Template
+}
+
+// NewTrendsHandler creates a new TrendsHandler.
+func NewTrendsHandler(store storage.Store, analyzer *insights.TrendAnalyzer, templates *template.Template) *TrendsHandler {
+	return &TrendsHandler{
+		store:     store,
+		analyzer:  analyzer,
+		templates: templates,
+	}
+}
+
+// moversView is the template data for the biggest movers widget.
+type moversView struct {
+	Comparison  insights.Comparison
+	Comparisons []insights.Comparison
+	Movers      []moverView
+}
+
+// moverView is a compared metric with its display title.
+type moverView struct {
+	*insights.PeriodComparison
+	Title string
+}
+
+// comparisonEnd returns the end of the ranges compared: midnight today, so
+// only complete days are compared.
+func comparisonEnd(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// Movers returns the biggest movers widget for the compare parameter
+// ("wow", "mom" or "yoy", defaulting to "wow").
+func (h *TrendsHandler) Movers(w http.ResponseWriter, r *http.Request) {
+	comparison, err := insights.ParseComparison(r.URL.Query().Get("compare"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	movers, err := h.analyzer.BiggestMovers(r.Context(), comparison, comparisonEnd(time.Now()), moversLimit)
+	if err != nil {
+		log.Printf("error comparing metrics: %v", err)
+		http.Error(w, "Failed to compare metrics", http.StatusInternalServerError)
+		return
+	}
+
+	view := moversView{Comparison: comparison, Comparisons: insights.Comparisons}
+	for _, m := range movers {
+		view.Movers = append(view.Movers, moverView{PeriodComparison: m, Title: metricTitle(m.Metric)})
+	}
+	if err := h.templates.ExecuteTemplate(w, "movers", view); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// APIMovers returns the biggest movers for the compare parameter as JSON.
+func (h *TrendsHandler) APIMovers(w http.ResponseWriter, r *http.Request) {
+	comparison, err := insights.ParseComparison(r.URL.Query().Get("compare"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	movers, err := h.analyzer.BiggestMovers(r.Context(), comparison, comparisonEnd(time.Now()), 0)
+	if err != nil {
+		log.Printf("error comparing metrics: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to compare metrics")
+		return
+	}
+	if movers == nil {
+		movers = []*insights.PeriodComparison{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"compare": comparison,
+		"movers":  movers,
+	})
+}
+
+// APICompare compares the metric parameter of the platform parameter over
+// the compare parameter as JSON. The comparison is null when either
+// period lacks the data to compare.
+func (h *TrendsHandler) APICompare(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	platform := data.Platform(q.Get("platform"))
+	if !validPlatform(platform) {
+		writeJSONError(w, http.StatusBadRequest, "invalid platform")
+		return
+	}
+	metric := q.Get("metric")
+	if metric == "" {
+		writeJSONError(w, http.StatusBadRequest, "metric is required")
+		return
+	}
+	comparison, err := insights.ParseComparison(q.Get("compare"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.analyzer.CompareOverPeriod(r.Context(), platform, metric, comparison, comparisonEnd(time.Now()))
+	if err != nil {
+		log.Printf("error comparing metric: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to compare metric")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"compare":    comparison,
+		"comparison": result,
+	})
+}
+
+// metricChartView is the template data for a metric's daily chart.
+type metricChartView struct {
+	Platform  data.Platform
+	Metric    string
+	Title     string
+	Method    string
+	Methods   []insights.AnomalyMethod
+	Anomalies []*data.Anomaly
+	Forecast  *insights.Forecast
+	SVG       template.HTML
+}
+
+// metricChart builds the daily chart of a platform metric over dateRange,
+// followed by its forecast when the range runs to the present.
+func (h *TrendsHandler) metricChart(r *http.Request, platform data.Platform, metric string, dateRange data.DateRange) (*metricChartView, error) {
+	method, err := h.analyzer.AnomalyMethod(r.Context(), platform, metric)
+	if err != nil {
+		return nil, err
+	}
+	points, err := h.analyzer.DailySeries(r.Context(), platform, metric, dateRange)
+	if err != nil {
+		return nil, err
+	}
+	anomalies, err := h.store.GetAnomalies(r.Context(), data.AnomalyQuery{
+		Platform:  platform,
+		Metric:    metric,
+		DateRange: dateRange,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var forecast *insights.Forecast
+	if dateRange.End.After(time.Now()) {
+		forecast, err = h.analyzer.Forecast(r.Context(), platform, metric, chartForecastDays, insights.ForecastAuto)
+		if err != nil && !errors.Is(err, insights.ErrNotEnoughData) {
+			return nil, err
+		}
+	}
+
+	title := metricTitle(metric)
+	return &metricChartView{
+		Platform:  platform,
+		Metric:    metric,
+		Title:     title,
+		Method:    method,
+		Methods:   insights.AnomalyMethods,
+		Anomalies: anomalies,
+		Forecast:  forecast,
+		SVG:       metricSeriesChart(platform, metric, title, points, anomalies, forecast),
+	}, nil
+}
+
+// Metrics returns the daily charts of the platform parameter's metrics
+// over the selected date range.
+func (h *TrendsHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	platform := data.Platform(r.URL.Query().Get("platform"))
+	if !validPlatform(platform) {
+		http.Error(w, "Invalid platform", http.StatusBadRequest)
+		return
+	}
+
+	dateRange := periodFrom(r).Current
+	var views []*metricChartView
+	for _, metric := range insights.PlatformMetrics[platform] {
+		view, err := h.metricChart(r, platform, metric, dateRange)
+		if err != nil {
+			log.Printf("error getting %s %s chart: %v", platform, metric, err)
+			http.Error(w, "Failed to get metrics", http.StatusInternalServerError)
+			return
+		}
+		views = append(views, view)
+	}
+	if err := h.templates.ExecuteTemplate(w, "metric_charts", views); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// SetAnomalyMethod selects the anomaly detection method form value for
+// the platform and metric form values, re-detects the metric's anomalies
+// and returns its updated chart.
+func (h *TrendsHandler) SetAnomalyMethod(w http.ResponseWriter, r *http.Request) {
+	platform := data.Platform(r.FormValue("platform"))
+	if !validPlatform(platform) {
+		http.Error(w, "Invalid platform", http.StatusBadRequest)
+		return
+	}
+	metric := r.FormValue("metric")
+	if metric == "" {
+		http.Error(w, "Metric is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.analyzer.SetAnomalyMethod(r.Context(), platform, metric, r.FormValue("method")); err != nil {
+		if errors.Is(err, insights.ErrUnknownAnomalyMethod) {
+			http.Error(w, "Unknown detection method", http.StatusBadRequest)
+			return
+		}
+		log.Printf("error setting anomaly method: %v", err)
+		http.Error(w, "Failed to detect anomalies", http.StatusInternalServerError)
+		return
+	}
+
+	view, err := h.metricChart(r, platform, metric, periodFrom(r).Current)
+	if err != nil {
+		log.Printf("error getting %s %s chart: %v", platform, metric, err)
+		http.Error(w, "Failed to get metric", http.StatusInternalServerError)
+		return
+	}
+	if err := h.templates.ExecuteTemplate(w, "metric_chart", view); err != nil {
+		log.Printf("error rendering template: %v", err)
+	}
+}
+
+// APIAnomalies returns the stored anomalies over the selected date range
+// as JSON, optionally filtered by the platform and metric parameters.
+func (h *TrendsHandler) APIAnomalies(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := data.AnomalyQuery{
+		Platform:  data.Platform(q.Get("platform")),
+		Metric:    q.Get("metric"),
+		DateRange: periodFrom(r).Current,
+	}
+	if query.Platform != "" && !validPlatform(query.Platform) {
+		writeJSONError(w, http.StatusBadRequest, "invalid platform")
+		return
+	}
+
+	anomalies, err := h.store.GetAnomalies(r.Context(), query)
+	if err != nil {
+		log.Printf("error getting anomalies: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to get anomalies")
+		return
+	}
+	if anomalies == nil {
+		anomalies = []*data.Anomaly{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"start":     query.DateRange.Start,
+		"end":       query.DateRange.End,
+		"anomalies": anomalies,
+	})
+}
+
+// APIForecast projects the metric parameter of the platform parameter the
+// horizon parameter's number of days ahead (30 by default) with the model parameter ("auto",
+// "holt_winters" or "linear", defaulting to "auto"), as JSON with its
+// backtests. The forecast is null when there's too little data.
+func (h *TrendsHandler) APIForecast(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	platform := data.Platform(q.Get("platform"))
+	if !validPlatform(platform) {
+		writeJSONError(w, http.StatusBadRequest, "invalid platform")
+		return
+	}
+	metric := q.Get("metric")
+	if metric == "" {
+		writeJSONError(w, http.StatusBadRequest, "metric is required")
+		return
+	}
+	model, err := insights.ParseForecastModel(q.Get("model"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	horizon := defaultForecastDays
+	if v := q.Get("horizon"); v != "" {
+		horizon, err = strconv.Atoi(v)
+		if err != nil || horizon < 1 || horizon > insights.MaxForecastDays {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("horizon must be between 1 and %d days", insights.MaxForecastDays))
+			return
+		}
+	}
+
+	forecast, err := h.analyzer.Forecast(r.Context(), platform, metric, horizon, model)
+	if err != nil && !errors.Is(err, insights.ErrNotEnoughData) {
+		log.Printf("error forecasting metric: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to forecast metric")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"horizon":  horizon,
+		"forecast": forecast,
+	})
+}
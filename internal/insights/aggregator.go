@@ -3,6 +3,11 @@ package insights
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/omnipulse/omnipulse/internal/data"
 	"github.com/omnipulse/omnipulse/internal/storage"
@@ -10,32 +15,63 @@ import (
 
 // Aggregator aggregates analytics data across platforms.
 type Aggregator struct {
-	store storage.Store
+	store  storage.Store
+	trends *TrendAnalyzer
 }
 
 // NewAggregator creates a new Aggregator.
 func NewAggregator(store storage.Store) *Aggregator {
-	return &Aggregator{store: store}
+	return &Aggregator{store: store, trends: NewTrendAnalyzer(store)}
 }
 
 // GetDashboardData retrieves aggregated data for the dashboard view.
-func (a *Aggregator) GetDashboardData(ctx context.Context, days int) (*DashboardData, error) {
-	// TODO: Implement aggregation logic
-	// 1. Get analytics summary for the date range
-	// 2. Get recent insights
-	// 3. Calculate trends for key metrics
-	// 4. Get top performing content from each platform
-	return nil, nil
+func (a *Aggregator) GetDashboardData(ctx context.Context, period Period) (*DashboardData, error) {
+	summary, err := a.store.GetAnalyticsSummary(ctx, period.Current)
+	if err != nil {
+		return nil, fmt.Errorf("getting analytics summary: %w", err)
+	}
+
+	dashboard := &DashboardData{
+		Period:  period,
+		Summary: summary,
+	}
+
+	if period.Previous != nil {
+		previous, err := a.store.GetAnalyticsSummary(ctx, *period.Previous)
+		if err != nil {
+			return nil, fmt.Errorf("getting previous analytics summary: %w", err)
+		}
+		dashboard.PreviousSummary = previous
+		dashboard.Deltas = SummaryDeltas(summary, previous)
+	}
+
+	if dashboard.YouTubeTrend, err = a.trend(ctx, data.PlatformYouTube, "views", period.Current); err != nil {
+		return nil, err
+	}
+	if dashboard.XTrend, err = a.trend(ctx, data.PlatformX, "impressions", period.Current); err != nil {
+		return nil, err
+	}
+	if dashboard.LinkedInTrend, err = a.trend(ctx, data.PlatformLinkedIn, "impressions", period.Current); err != nil {
+		return nil, err
+	}
+
+	// TODO: Implement remaining aggregation logic
+	// 1. Get recent insights
+	// 2. Get top performing content from each platform
+	return dashboard, nil
 }
 
 // DashboardData contains all data needed for the main dashboard.
 type DashboardData struct {
-	Summary       *data.AnalyticsSummary `json:"summary"`
-	RecentInsights []*data.Insight       `json:"recent_insights"`
-	YouTubeTrend  *data.TrendData        `json:"youtube_trend,omitempty"`
-	XTrend        *data.TrendData        `json:"x_trend,omitempty"`
-	LinkedInTrend *data.TrendData        `json:"linkedin_trend,omitempty"`
-	TopContent    *TopContent            `json:"top_content"`
+	Period          Period                 `json:"period"`
+	Summary         *data.AnalyticsSummary `json:"summary"`
+	PreviousSummary *data.AnalyticsSummary `json:"previous_summary,omitempty"`
+	Deltas          map[string]*Delta      `json:"deltas,omitempty"` // See SummaryDeltas for keys
+	RecentInsights  []*data.Insight        `json:"recent_insights"`
+	YouTubeTrend    *data.TrendData        `json:"youtube_trend,omitempty"`
+	XTrend          *data.TrendData        `json:"x_trend,omitempty"`
+	LinkedInTrend   *data.TrendData        `json:"linkedin_trend,omitempty"`
+	TopContent      *TopContent            `json:"top_content"`
 }
 
 // TopContent holds top performing content from each platform.
@@ -46,22 +82,229 @@ type TopContent struct {
 }
 
 // GetPlatformAnalytics retrieves detailed analytics for a specific platform.
-func (a *Aggregator) GetPlatformAnalytics(ctx context.Context, platform data.Platform, days int) (*PlatformAnalytics, error) {
-	// TODO: Implement platform-specific aggregation
-	return nil, nil
+func (a *Aggregator) GetPlatformAnalytics(ctx context.Context, platform data.Platform, period Period) (*PlatformAnalytics, error) {
+	summary, items, err := a.platformSummary(ctx, platform, period.Current)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics := &PlatformAnalytics{
+		Platform: platform,
+		Summary:  summary,
+		Period:   period,
+	}
+
+	for _, metric := range PlatformMetrics[platform] {
+		trend, err := a.trend(ctx, platform, metric, period.Current)
+		if err != nil {
+			return nil, err
+		}
+		if trend == nil {
+			continue
+		}
+		analytics.Trends = append(analytics.Trends, trend)
+
+		forecast, err := ForecastTrend(trend, platformForecastHorizon(period), ForecastAuto)
+		if errors.Is(err, ErrNotEnoughData) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("forecasting %s %s: %w", platform, metric, err)
+		}
+		if analytics.Forecasts == nil {
+			analytics.Forecasts = make(map[string]*Forecast)
+		}
+		analytics.Forecasts[metric] = forecast
+	}
+
+	for _, item := range items {
+		title := item.Title
+		if title == "" {
+			title = snippet(item.Body, 60)
+		}
+		analytics.Published = append(analytics.Published, PublishEvent{
+			ContentID:   item.ID,
+			Title:       title,
+			PublishedAt: item.PublishedAt,
+		})
+	}
+
+	anomalies, err := a.store.GetAnomalies(ctx, data.AnomalyQuery{
+		Platform:  platform,
+		DateRange: period.Current,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting %s anomalies: %w", platform, err)
+	}
+	if len(anomalies) > 0 {
+		analytics.Anomalies = make(map[string][]*Anomaly)
+		for _, anomaly := range anomalies {
+			analytics.Anomalies[anomaly.Metric] = append(analytics.Anomalies[anomaly.Metric], anomaly)
+		}
+	}
+
+	if period.Previous != nil {
+		if analytics.Deltas, err = a.platformDeltas(ctx, platform, summary, period); err != nil {
+			return nil, err
+		}
+	}
+	return analytics, nil
+}
+
+// maxPlatformForecastDays caps how far past the period the platform
+// page's trend charts are projected.
+const maxPlatformForecastDays = 30
+
+// platformForecastHorizon returns how many days past the period its
+// trends are forecast: a quarter of the period, up to
+// maxPlatformForecastDays.
+func platformForecastHorizon(period Period) int {
+	return max(1, min(period.Days()/4, maxPlatformForecastDays))
+}
+
+// platformDeltas compares a platform's summary over the current period
+// with its summary over the previous one. Keys are the PlatformSummary
+// JSON names, with the platform's SummaryDeltas added without their
+// platform prefix (e.g. "views").
+func (a *Aggregator) platformDeltas(ctx context.Context, platform data.Platform, current *PlatformSummary, period Period) (map[string]*Delta, error) {
+	before, _, err := a.platformSummary(ctx, platform, *period.Previous)
+	if err != nil {
+		return nil, fmt.Errorf("summarizing previous period: %w", err)
+	}
+
+	currentSummary, err := a.store.GetAnalyticsSummary(ctx, period.Current)
+	if err != nil {
+		return nil, fmt.Errorf("getting analytics summary: %w", err)
+	}
+	previousSummary, err := a.store.GetAnalyticsSummary(ctx, *period.Previous)
+	if err != nil {
+		return nil, fmt.Errorf("getting previous analytics summary: %w", err)
+	}
+	deltas := make(map[string]*Delta)
+	prefix := string(platform) + "."
+	for key, delta := range SummaryDeltas(currentSummary, previousSummary) {
+		if metric, ok := strings.CutPrefix(key, prefix); ok {
+			deltas[metric] = delta
+		}
+	}
+
+	deltas["total_engagement"] = NewDelta(float64(current.TotalEngagement), float64(before.TotalEngagement))
+	deltas["engagement_rate"] = NewDelta(current.EngagementRate, before.EngagementRate)
+	deltas["growth_rate"] = NewDelta(current.GrowthRate, before.GrowthRate)
+	deltas["content_count"] = NewDelta(float64(current.ContentCount), float64(before.ContentCount))
+	return deltas, nil
+}
+
+// growthMetrics are the audience metrics each platform's growth rate is
+// measured on.
+var growthMetrics = map[data.Platform]string{
+	data.PlatformYouTube:  "subscribers",
+	data.PlatformX:        "followers",
+	data.PlatformLinkedIn: "connections",
+}
+
+// PlatformSummary summarizes a platform's performance over a period.
+type PlatformSummary struct {
+	TotalEngagement int64   `json:"total_engagement"` // Likes, comments and shares on content published in the period
+	EngagementRate  float64 `json:"engagement_rate"`  // Percent
+	GrowthRate      float64 `json:"growth_rate"`      // Percent change in the audience metric over the period
+	ContentCount    int     `json:"content_count"`
+}
+
+// platformSummary summarizes platform over dateRange and returns it with
+// the content published in the range, oldest first.
+func (a *Aggregator) platformSummary(ctx context.Context, platform data.Platform, dateRange data.DateRange) (*PlatformSummary, []*data.ContentItem, error) {
+	items, _, err := a.store.ListContent(ctx, data.ContentQuery{
+		Platform:  platform,
+		DateRange: dateRange,
+		Sort:      data.SortByDate,
+		Ascending: true,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing %s content: %w", platform, err)
+	}
+
+	summary := &PlatformSummary{ContentCount: len(items)}
+	var views int64
+	for _, item := range items {
+		summary.TotalEngagement += item.Engagements()
+		views += item.Views
+	}
+
+	// Prefer the platform's own engagement rate, falling back to the
+	// rate of the content published in the range.
+	rate, _, ok, err := a.trends.periodValue(ctx, platform, "engagement_rate", AggregateMean, dateRange)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok {
+		summary.EngagementRate = rate
+	} else if views > 0 {
+		summary.EngagementRate = float64(summary.TotalEngagement) / float64(views) * 100
+	}
+
+	if metric, ok := growthMetrics[platform]; ok {
+		length := dateRange.End.Sub(dateRange.Start)
+		end, _, endOK, err := a.trends.periodValue(ctx, platform, metric, AggregateLast, dateRange)
+		if err != nil {
+			return nil, nil, err
+		}
+		start, _, startOK, err := a.trends.periodValue(ctx, platform, metric, AggregateLast,
+			data.DateRange{Start: dateRange.Start.Add(-length), End: dateRange.Start})
+		if err != nil {
+			return nil, nil, err
+		}
+		if endOK && startOK && start > 0 {
+			summary.GrowthRate = math.Round((end-start)/start*1000) / 10
+		}
+	}
+	return summary, items, nil
+}
+
+// trend returns a metric's daily series over dateRange with its direction
+// and change, or nil if it has fewer than two days of data.
+func (a *Aggregator) trend(ctx context.Context, platform data.Platform, metric string, dateRange data.DateRange) (*data.TrendData, error) {
+	points, err := a.trends.DailySeries(ctx, platform, metric, dateRange)
+	if err != nil {
+		return nil, fmt.Errorf("getting %s %s trend: %w", platform, metric, err)
+	}
+	if len(points) < 2 {
+		return nil, nil
+	}
+
+	trend := &data.TrendData{Platform: platform, Metric: metric, Points: points}
+	trend.Trend, trend.ChangePercent = calculateTrendMetrics(points)
+	return trend, nil
 }
 
 // PlatformAnalytics contains detailed analytics for a single platform.
 type PlatformAnalytics struct {
-	Platform    data.Platform   `json:"platform"`
-	Summary     interface{}     `json:"summary"` // Platform-specific summary
-	Trends      []*data.TrendData `json:"trends"`
-	Content     interface{}     `json:"content"` // Platform-specific content list
-	Insights    []*data.Insight `json:"insights"`
+	Platform data.Platform     `json:"platform"`
+	Summary  *PlatformSummary  `json:"summary"`
+	Trends   []*data.TrendData `json:"trends"`
+	Content  interface{}       `json:"content"` // Platform-specific content list
+	Insights []*data.Insight   `json:"insights"`
+	Period   Period            `json:"period"`
+
+	// Deltas against the previous period, keyed by summary metric
+	// (e.g. "engagement_rate"). Empty when comparison is disabled.
+	Deltas map[string]*Delta `json:"deltas,omitempty"`
+
+	// Chart annotations
+	Anomalies map[string][]*Anomaly `json:"anomalies,omitempty"` // Keyed by metric name
+	Published []PublishEvent        `json:"published,omitempty"`
+	Forecasts map[string]*Forecast  `json:"forecasts,omitempty"` // Keyed by metric name
+}
+
+// PublishEvent marks when a piece of content was published.
+type PublishEvent struct {
+	ContentID   string    `json:"content_id"`
+	Title       string    `json:"title"`
+	PublishedAt time.Time `json:"published_at"`
 }
 
 // ComparePlatforms compares performance metrics across platforms.
-func (a *Aggregator) ComparePlatforms(ctx context.Context, days int) (*PlatformComparison, error) {
+func (a *Aggregator) ComparePlatforms(ctx context.Context, period Period) (*PlatformComparison, error) {
 	// TODO: Implement cross-platform comparison
 	// Normalize metrics across platforms (e.g., engagement rate)
 	// Identify which platform is performing best
@@ -70,8 +313,8 @@ func (a *Aggregator) ComparePlatforms(ctx context.Context, days int) (*PlatformC
 
 // PlatformComparison holds comparative analytics across platforms.
 type PlatformComparison struct {
-	BestPerforming     data.Platform            `json:"best_performing"`
-	EngagementRates    map[data.Platform]float64 `json:"engagement_rates"`
-	GrowthRates        map[data.Platform]float64 `json:"growth_rates"`
-	Recommendations    []string                  `json:"recommendations"`
+	BestPerforming  data.Platform             `json:"best_performing"`
+	EngagementRates map[data.Platform]float64 `json:"engagement_rates"`
+	GrowthRates     map[data.Platform]float64 `json:"growth_rates"`
+	Recommendations []string                  `json:"recommendations"`
 }
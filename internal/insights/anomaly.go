@@ -0,0 +1,469 @@
+// Package insights provides anomaly detection for daily platform metrics.
+package insights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+const (
+	// anomalyThreshold is how many robust standard deviations from the
+	// expected value a day's value must be to count as unusual.
+	anomalyThreshold = 3.5
+
+	// changePointThreshold is how many robust standard deviations apart
+	// the levels either side of a change point must be. It's lower than
+	// anomalyThreshold because a shift is sustained for a week or more.
+	changePointThreshold = 3.0
+
+	// minRelativeSpread floors the spread at a share of the typical value,
+	// so near-constant series don't flag every small wobble.
+	minRelativeSpread = 0.05
+
+	madWindow          = 28 // Trailing days the rolling median uses
+	madMinHistory      = 7  // Days needed before a day can be judged
+	seasonPeriod       = 7  // Days in the weekly cycle
+	changePointSegment = 7  // Minimum days either side of a change point
+
+	// anomalyLookbackDays is how far back detection runs look. Older
+	// stored anomalies are kept as they are.
+	anomalyLookbackDays = 90
+
+	// stockCarryDays is how far before a range a stock's last value is
+	// looked for, to carry it into the range's first days.
+	stockCarryDays = 30
+
+	anomalyMethodSettingPrefix = "anomaly_method."
+)
+
+// ErrUnknownAnomalyMethod is returned when selecting an anomaly detection
+// method that doesn't exist.
+var ErrUnknownAnomalyMethod = errors.New("unknown anomaly detection method")
+
+// Anomaly represents an unusual data point or pattern.
+type Anomaly = data.Anomaly
+
+// AnomalyMethod is a selectable anomaly detection method.
+type AnomalyMethod struct {
+	Name  string
+	Label string
+}
+
+// AnomalyMethods lists the detection methods in display order.
+var AnomalyMethods = []AnomalyMethod{
+	{Name: data.AnomalyMethodSTL, Label: "Weekly seasonal"},
+	{Name: data.AnomalyMethodMAD, Label: "Rolling median"},
+	{Name: data.AnomalyMethodChangePoint, Label: "Level shifts"},
+}
+
+// validAnomalyMethod reports whether name is a detection method.
+func validAnomalyMethod(name string) bool {
+	for _, m := range AnomalyMethods {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultAnomalyMethod returns the method used for metric until another
+// is selected: weekly seasonal for flows, which follow the posting week,
+// level shifts for stocks, whose daily gains change pace rather than
+// spike, and the rolling median for rates.
+func defaultAnomalyMethod(metric string) string {
+	switch AggregationFor(metric) {
+	case AggregateLast:
+		return data.AnomalyMethodChangePoint
+	case AggregateMean:
+		return data.AnomalyMethodMAD
+	default:
+		return data.AnomalyMethodSTL
+	}
+}
+
+// AnomalyMethod returns the detection method selected for a platform
+// metric.
+func (t *TrendAnalyzer) AnomalyMethod(ctx context.Context, platform data.Platform, metric string) (string, error) {
+	setting, err := t.store.GetSetting(ctx, anomalyMethodSettingPrefix+string(platform)+"."+metric)
+	if err != nil {
+		return "", fmt.Errorf("getting anomaly method: %w", err)
+	}
+	if setting == nil || !validAnomalyMethod(setting.Value) {
+		return defaultAnomalyMethod(metric), nil
+	}
+	return setting.Value, nil
+}
+
+// SetAnomalyMethod selects the detection method for a platform metric and
+// re-detects its anomalies with it.
+func (t *TrendAnalyzer) SetAnomalyMethod(ctx context.Context, platform data.Platform, metric, method string) ([]*Anomaly, error) {
+	if !validAnomalyMethod(method) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAnomalyMethod, method)
+	}
+	if err := t.store.SaveSetting(ctx, anomalyMethodSettingPrefix+string(platform)+"."+metric, method); err != nil {
+		return nil, fmt.Errorf("saving anomaly method: %w", err)
+	}
+	return t.RefreshAnomalies(ctx, platform, metric)
+}
+
+// DetectAnomalies identifies unusual days in the last days of a metric
+// with its selected method, without storing them.
+func (t *TrendAnalyzer) DetectAnomalies(ctx context.Context, platform data.Platform, metric string, days int) ([]*Anomaly, error) {
+	end := startOfDay(time.Now())
+	return t.detect(ctx, platform, metric, data.DateRange{Start: end.AddDate(0, 0, -days), End: end})
+}
+
+// RefreshAnomalies re-detects a metric's anomalies over the last
+// anomalyLookbackDays complete days and replaces the stored ones.
+func (t *TrendAnalyzer) RefreshAnomalies(ctx context.Context, platform data.Platform, metric string) ([]*Anomaly, error) {
+	end := startOfDay(time.Now())
+	dateRange := data.DateRange{Start: end.AddDate(0, 0, -anomalyLookbackDays), End: end}
+	anomalies, err := t.detect(ctx, platform, metric, dateRange)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.store.ReplaceAnomalies(ctx, platform, metric, dateRange, anomalies); err != nil {
+		return nil, fmt.Errorf("saving %s %s anomalies: %w", platform, metric, err)
+	}
+	return anomalies, nil
+}
+
+// RunAnomalyDetection refreshes the stored anomalies of every platform
+// metric. It's run as a scheduled task.
+func (t *TrendAnalyzer) RunAnomalyDetection(ctx context.Context) error {
+	for _, platform := range promptPlatforms("") {
+		for _, metric := range PlatformMetrics[platform] {
+			if _, err := t.RefreshAnomalies(ctx, platform, metric); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// detect runs the selected method over the metric's daily series within
+// dateRange. Stocks are judged by their daily change, since their level
+// always trends.
+func (t *TrendAnalyzer) detect(ctx context.Context, platform data.Platform, metric string, dateRange data.DateRange) ([]*Anomaly, error) {
+	method, err := t.AnomalyMethod(ctx, platform, metric)
+	if err != nil {
+		return nil, err
+	}
+	points, err := t.DailySeries(ctx, platform, metric, dateRange)
+	if err != nil {
+		return nil, err
+	}
+	if AggregationFor(metric) == AggregateLast {
+		points = dailyChanges(points)
+	}
+
+	anomalies := detectAnomalies(method, points)
+	for _, a := range anomalies {
+		a.Platform = platform
+		a.Metric = metric
+	}
+	return anomalies, nil
+}
+
+// DailySeries returns a metric's value for each complete day of
+// dateRange, in the location of its start. Flows count days without
+// observations as zero once the metric has data, stocks carry their last
+// value forward, and rate days without observations are left out.
+func (t *TrendAnalyzer) DailySeries(ctx context.Context, platform data.Platform, metric string, dateRange data.DateRange) ([]data.DataPoint, error) {
+	aggregation := AggregationFor(metric)
+	loc := dateRange.Start.Location()
+	day := func(ts time.Time) time.Time { return startOfDay(ts.In(loc)) }
+
+	query := dateRange
+	var first time.Time
+	if aggregation == AggregateLast {
+		query.Start = dateRange.Start.AddDate(0, 0, -stockCarryDays)
+	} else {
+		start, err := t.store.GetMetricStart(ctx, platform, metric)
+		if err != nil {
+			return nil, fmt.Errorf("getting %s start: %w", metric, err)
+		}
+		if start.IsZero() {
+			return nil, nil
+		}
+		first = day(start)
+	}
+	points, err := t.store.GetMetricSeries(ctx, platform, metric, query)
+	if err != nil {
+		return nil, fmt.Errorf("getting %s series: %w", metric, err)
+	}
+
+	sums := make(map[time.Time]float64)
+	counts := make(map[time.Time]int)
+	for _, p := range points {
+		d := day(p.Timestamp)
+		sums[d] += p.Value
+		counts[d]++
+	}
+
+	var series []data.DataPoint
+	now := time.Now()
+	next, last, hasLast := 0, 0.0, false
+	for d := day(dateRange.Start); d.Before(dateRange.End) && !d.AddDate(0, 0, 1).After(now); d = d.AddDate(0, 0, 1) {
+		switch aggregation {
+		case AggregateLast:
+			end := d.AddDate(0, 0, 1)
+			for ; next < len(points) && points[next].Timestamp.Before(end); next++ {
+				last, hasLast = points[next].Value, true
+			}
+			if hasLast {
+				series = append(series, data.DataPoint{Timestamp: d, Value: last})
+			}
+		case AggregateMean:
+			if counts[d] > 0 {
+				series = append(series, data.DataPoint{Timestamp: d, Value: sums[d] / float64(counts[d])})
+			}
+		default:
+			if !d.Before(first) {
+				series = append(series, data.DataPoint{Timestamp: d, Value: sums[d]})
+			}
+		}
+	}
+	return series, nil
+}
+
+// dailyChanges returns the change of each point from the one before.
+func dailyChanges(points []data.DataPoint) []data.DataPoint {
+	if len(points) < 2 {
+		return nil
+	}
+	changes := make([]data.DataPoint, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		changes[i-1] = data.DataPoint{Timestamp: points[i].Timestamp, Value: points[i].Value - points[i-1].Value}
+	}
+	return changes
+}
+
+// detectAnomalies runs a detection method over a daily series.
+func detectAnomalies(method string, points []data.DataPoint) []*Anomaly {
+	switch method {
+	case data.AnomalyMethodMAD:
+		return detectRollingMAD(points)
+	case data.AnomalyMethodChangePoint:
+		return detectChangePoints(points)
+	default:
+		return detectSeasonal(points)
+	}
+}
+
+// detectRollingMAD flags days far from the median of the madWindow days
+// before them. Each day is judged against earlier days only, so a spike
+// can't hide itself, and the median keeps it from hiding later ones.
+func detectRollingMAD(points []data.DataPoint) []*Anomaly {
+	values := pointValues(points)
+	var anomalies []*Anomaly
+	for i := madMinHistory; i < len(points); i++ {
+		expected, spread := robustSpread(values[max(i-madWindow, 0):i])
+		if a := judgePoint(points[i], expected, spread, data.AnomalyMethodMAD); a != nil {
+			anomalies = append(anomalies, a)
+		}
+	}
+	return anomalies
+}
+
+// detectSeasonal decomposes the series into a trend, a weekly seasonal
+// pattern and a remainder, STL-style but with medians for robustness, and
+// flags days whose remainder is unusually large. Weekends that are always
+// quiet are expected rather than flagged.
+func detectSeasonal(points []data.DataPoint) []*Anomaly {
+	n := len(points)
+	if n < 2*seasonPeriod {
+		return nil // Too few weeks to tell the pattern from noise
+	}
+	values := pointValues(points)
+
+	// Trend: a centered moving median over one period, shifted inward at
+	// the ends of the series so it always spans a whole week.
+	trend := make([]float64, n)
+	for i := range values {
+		start := min(max(i-seasonPeriod/2, 0), n-seasonPeriod)
+		trend[i] = median(append([]float64(nil), values[start:start+seasonPeriod]...))
+	}
+
+	// Seasonal: the median detrended value of each weekday, centered so
+	// the week's pattern sums to zero.
+	byWeekday := make(map[time.Weekday][]float64)
+	for i, p := range points {
+		byWeekday[p.Timestamp.Weekday()] = append(byWeekday[p.Timestamp.Weekday()], values[i]-trend[i])
+	}
+	seasonal := make(map[time.Weekday]float64)
+	var total float64
+	for weekday, detrended := range byWeekday {
+		seasonal[weekday] = median(detrended)
+		total += seasonal[weekday]
+	}
+	for weekday := range seasonal {
+		seasonal[weekday] -= total / float64(len(seasonal))
+	}
+
+	remainder := make([]float64, n)
+	for i, p := range points {
+		remainder[i] = values[i] - trend[i] - seasonal[p.Timestamp.Weekday()]
+	}
+	center, spread := robustSpread(remainder)
+	// Floor the spread against the level of the series rather than the
+	// remainder, which is centered on zero.
+	level, _ := robustSpread(values)
+	spread = max(spread, minRelativeSpread*math.Abs(level))
+
+	var anomalies []*Anomaly
+	for i, p := range points {
+		expected := trend[i] + seasonal[p.Timestamp.Weekday()] + center
+		if a := judgePoint(p, expected, spread, data.AnomalyMethodSTL); a != nil {
+			anomalies = append(anomalies, a)
+		}
+	}
+	return anomalies
+}
+
+// detectChangePoints finds sustained level shifts by binary segmentation:
+// it splits the series where the medians either side fit it best, keeps
+// the split if the medians differ by a lot against the noise around them,
+// and repeats on each side.
+func detectChangePoints(points []data.DataPoint) []*Anomaly {
+	values := pointValues(points)
+	var anomalies []*Anomaly
+	var split func(lo, hi int)
+	split = func(lo, hi int) {
+		best, bestCost := -1, math.Inf(1)
+		var before, after float64
+		for k := lo + changePointSegment; k <= hi-changePointSegment; k++ {
+			b := median(append([]float64(nil), values[lo:k]...))
+			a := median(append([]float64(nil), values[k:hi]...))
+			var cost float64
+			for i := lo; i < hi; i++ {
+				if i < k {
+					cost += math.Abs(values[i] - b)
+				} else {
+					cost += math.Abs(values[i] - a)
+				}
+			}
+			if cost < bestCost {
+				best, bestCost = k, cost
+				before, after = b, a
+			}
+		}
+		if best < 0 {
+			return
+		}
+
+		deviations := make([]float64, 0, hi-lo)
+		for i := lo; i < hi; i++ {
+			if i < best {
+				deviations = append(deviations, math.Abs(values[i]-before))
+			} else {
+				deviations = append(deviations, math.Abs(values[i]-after))
+			}
+		}
+		spread := max(1.4826*median(deviations), minRelativeSpread*math.Abs(before))
+		if spread == 0 {
+			return
+		}
+		score := math.Abs(after-before) / spread
+		if score < changePointThreshold {
+			return
+		}
+
+		anomalies = append(anomalies, &Anomaly{
+			Method:      data.AnomalyMethodChangePoint,
+			Timestamp:   points[best].Timestamp,
+			Value:       after,
+			Expected:    before,
+			ExpectedMin: before - changePointThreshold*spread,
+			ExpectedMax: before + changePointThreshold*spread,
+			Type:        data.AnomalyLevelShift,
+			Severity:    anomalySeverity(score, changePointThreshold),
+		})
+		split(lo, best)
+		split(best, hi)
+	}
+	split(0, len(values))
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		return anomalies[i].Timestamp.Before(anomalies[j].Timestamp)
+	})
+	return anomalies
+}
+
+// judgePoint returns an anomaly if p is at least anomalyThreshold spreads
+// from expected, or nil.
+func judgePoint(p data.DataPoint, expected, spread float64, method string) *Anomaly {
+	if spread == 0 {
+		return nil
+	}
+	score := math.Abs(p.Value-expected) / spread
+	if score < anomalyThreshold {
+		return nil
+	}
+	anomalyType := data.AnomalySpike
+	if p.Value < expected {
+		anomalyType = data.AnomalyDrop
+	}
+	return &Anomaly{
+		Method:      method,
+		Timestamp:   p.Timestamp,
+		Value:       p.Value,
+		Expected:    expected,
+		ExpectedMin: expected - anomalyThreshold*spread,
+		ExpectedMax: expected + anomalyThreshold*spread,
+		Type:        anomalyType,
+		Severity:    anomalySeverity(score, anomalyThreshold),
+	}
+}
+
+// anomalySeverity grades a score by how far past threshold it is.
+func anomalySeverity(score, threshold float64) string {
+	switch {
+	case score >= 2*threshold:
+		return "high"
+	case score >= 1.5*threshold:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// robustSpread returns the median of values and the median absolute
+// deviation from it, scaled to a standard deviation and floored at
+// minRelativeSpread of the median.
+func robustSpread(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	med := median(append([]float64(nil), values...))
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - med)
+	}
+	return med, max(1.4826*median(deviations), minRelativeSpread*math.Abs(med))
+}
+
+// pointValues returns the values of points.
+func pointValues(points []data.DataPoint) []float64 {
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.Value
+	}
+	return values
+}
+
+// median returns the median of values, which it sorts.
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
@@ -0,0 +1,567 @@
+// Package insights provides campaign rollups of related content across
+// platforms and suggestions for grouping content into campaigns.
+package insights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+const (
+	// campaignSuggestionContent limits the content items, newest first,
+	// considered for campaign suggestions.
+	campaignSuggestionContent = 1000
+
+	// campaignSharedLinkLimit is the most content items a link can be in
+	// and still suggest a campaign. Links in more, such as a newsletter
+	// signup in every post, say nothing about which posts go together.
+	campaignSharedLinkLimit = 10
+
+	// Content is suggested as a campaign by its text when items on
+	// different platforms, published within campaignSimilarWindow of each
+	// other, share at least campaignSharedTerms significant terms and
+	// their TF-IDF cosine similarity is at least campaignSimilarity.
+	campaignSimilarWindow = 7 * 24 * time.Hour
+	campaignSharedTerms   = 2
+	campaignSimilarity    = 0.3
+
+	campaignSuggestionLimit = 20
+	campaignNameLength      = 60
+)
+
+// ErrInvalidCampaign is returned when creating a campaign without a name
+// or with content that isn't stored.
+var ErrInvalidCampaign = errors.New("invalid campaign")
+
+// trackingParams are link query parameters that identify the click rather
+// than the page, removed when comparing links. utm_* parameters are
+// removed too.
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "igshid": true, "mc_cid": true, "mc_eid": true,
+	"ref": true, "ref_src": true, "si": true, "s": true, "t": true,
+}
+
+// CampaignPlatform is the combined performance of a campaign's content on
+// one platform.
+type CampaignPlatform struct {
+	Platform       data.Platform `json:"platform"`
+	Items          int           `json:"items"`
+	Views          int64         `json:"views"` // Views on YouTube, impressions elsewhere
+	Likes          int64         `json:"likes"`
+	Comments       int64         `json:"comments"`
+	Shares         int64         `json:"shares"`
+	Engagements    int64         `json:"engagements"`
+	EngagementRate float64       `json:"engagement_rate"` // Engagements per view, 0-1
+	Share          float64       `json:"share"`           // Of the campaign's engagements, 0-1
+}
+
+// CampaignReport is the combined reach and engagement of a campaign's
+// content and the part each platform played in it.
+type CampaignReport struct {
+	Campaign       *data.Campaign       `json:"campaign"`
+	Items          []*data.CampaignItem `json:"items"` // Oldest first
+	Platforms      []*CampaignPlatform  `json:"platforms"`
+	Views          int64                `json:"views"`
+	Engagements    int64                `json:"engagements"`
+	EngagementRate float64              `json:"engagement_rate"`
+
+	// TopPlatform drove the most engagements, or is empty if the
+	// campaign's content has none.
+	TopPlatform data.Platform `json:"top_platform,omitempty"`
+
+	// First and Last are when the campaign's first and last content items
+	// were published.
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+}
+
+// newCampaignReport totals a campaign's content by platform, ordering the
+// platforms by engagements, most first.
+func newCampaignReport(campaign *data.Campaign, items []*data.CampaignItem) *CampaignReport {
+	report := &CampaignReport{Campaign: campaign, Items: items}
+	byPlatform := make(map[data.Platform]*CampaignPlatform)
+	for _, item := range items {
+		p := byPlatform[item.Platform]
+		if p == nil {
+			p = &CampaignPlatform{Platform: item.Platform}
+			byPlatform[item.Platform] = p
+			report.Platforms = append(report.Platforms, p)
+		}
+		p.Items++
+		p.Views += item.Views
+		p.Likes += item.Likes
+		p.Comments += item.Comments
+		p.Shares += item.Shares
+		p.Engagements += item.Engagements()
+
+		report.Views += item.Views
+		report.Engagements += item.Engagements()
+		if report.First.IsZero() || item.PublishedAt.Before(report.First) {
+			report.First = item.PublishedAt
+		}
+		if item.PublishedAt.After(report.Last) {
+			report.Last = item.PublishedAt
+		}
+	}
+
+	for _, p := range report.Platforms {
+		if p.Views > 0 {
+			p.EngagementRate = float64(p.Engagements) / float64(p.Views)
+		}
+		if report.Engagements > 0 {
+			p.Share = float64(p.Engagements) / float64(report.Engagements)
+		}
+	}
+	if report.Views > 0 {
+		report.EngagementRate = float64(report.Engagements) / float64(report.Views)
+	}
+	sort.SliceStable(report.Platforms, func(i, j int) bool {
+		return report.Platforms[i].Engagements > report.Platforms[j].Engagements
+	})
+	if len(report.Platforms) > 0 && report.Platforms[0].Engagements > 0 {
+		report.TopPlatform = report.Platforms[0].Platform
+	}
+	return report
+}
+
+// GetCampaignReport retrieves a campaign's content and totals it, or
+// returns nil if the campaign doesn't exist.
+func (a *Aggregator) GetCampaignReport(ctx context.Context, id int64) (*CampaignReport, error) {
+	campaign, err := a.store.GetCampaign(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting campaign: %w", err)
+	}
+	if campaign == nil {
+		return nil, nil
+	}
+	items, err := a.store.GetCampaignContent(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting campaign content: %w", err)
+	}
+	return newCampaignReport(campaign, items), nil
+}
+
+// ListCampaignReports totals the content of every campaign, most recently
+// updated first.
+func (a *Aggregator) ListCampaignReports(ctx context.Context) ([]*CampaignReport, error) {
+	campaigns, err := a.store.ListCampaigns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing campaigns: %w", err)
+	}
+	reports := make([]*CampaignReport, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		items, err := a.store.GetCampaignContent(ctx, campaign.ID)
+		if err != nil {
+			return nil, fmt.Errorf("getting campaign content: %w", err)
+		}
+		reports = append(reports, newCampaignReport(campaign, items))
+	}
+	return reports, nil
+}
+
+// CreateCampaign validates and saves a campaign with its initial content.
+// Validation errors wrap ErrInvalidCampaign.
+func (a *Aggregator) CreateCampaign(ctx context.Context, campaign *data.Campaign, members []*data.CampaignMember) error {
+	campaign.Name = strings.TrimSpace(campaign.Name)
+	if campaign.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCampaign)
+	}
+	if err := a.checkCampaignContent(ctx, members); err != nil {
+		return err
+	}
+	if err := a.store.CreateCampaign(ctx, campaign, members); err != nil {
+		return fmt.Errorf("saving campaign: %w", err)
+	}
+	return nil
+}
+
+// AddCampaignContent adds content items to campaigns after checking that
+// they're stored. Validation errors wrap ErrInvalidCampaign.
+func (a *Aggregator) AddCampaignContent(ctx context.Context, members []*data.CampaignMember) error {
+	if err := a.checkCampaignContent(ctx, members); err != nil {
+		return err
+	}
+	if err := a.store.AddCampaignContent(ctx, members); err != nil {
+		return fmt.Errorf("saving campaign content: %w", err)
+	}
+	return nil
+}
+
+// checkCampaignContent returns an error wrapping ErrInvalidCampaign if a
+// member's content item isn't stored.
+func (a *Aggregator) checkCampaignContent(ctx context.Context, members []*data.CampaignMember) error {
+	for _, m := range members {
+		if !m.Source.Valid() {
+			return fmt.Errorf("%w: unknown source %q", ErrInvalidCampaign, m.Source)
+		}
+		item, err := a.store.GetContentItem(ctx, m.Platform, m.ContentID)
+		if err != nil {
+			return fmt.Errorf("getting content item: %w", err)
+		}
+		if item == nil {
+			return fmt.Errorf("%w: no %s content %q", ErrInvalidCampaign, m.Platform, m.ContentID)
+		}
+	}
+	return nil
+}
+
+// CampaignSuggestion is a group of content items not yet in a campaign
+// that look like one piece of work published across platforms.
+type CampaignSuggestion struct {
+	// Source is why the items were grouped: they carry the same
+	// utm_campaign tag, share a link (including links to each other), or
+	// have similar text.
+	Source data.CampaignSource `json:"source"`
+	Key    string              `json:"key,omitempty"` // The utm_campaign value or shared link
+	Name   string              `json:"name"`          // A suggested campaign name
+
+	// Similarity is the lowest similarity between the items joined by
+	// text, for suggestions from similar text.
+	Similarity float64 `json:"similarity,omitempty"`
+
+	Items []*data.ContentItem `json:"items"` // Oldest first
+}
+
+// contentKey identifies a content item across platforms.
+type contentKey struct {
+	platform data.Platform
+	id       string
+}
+
+// SuggestCampaigns groups content published in dateRange that isn't in a
+// campaign by the utm_campaign tags and links it shares, then groups the
+// rest by similar text across platforms. Links are read from the content
+// text and from the entities saved on ingest, which hold X's expanded
+// links.
+func (a *Aggregator) SuggestCampaigns(ctx context.Context, dateRange data.DateRange) ([]*CampaignSuggestion, error) {
+	content, _, err := a.store.ListContent(ctx, data.ContentQuery{
+		DateRange: dateRange,
+		Sort:      data.SortByDate,
+		Limit:     campaignSuggestionContent,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing content: %w", err)
+	}
+	members, err := a.store.ListCampaignMembers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing campaign members: %w", err)
+	}
+	inCampaign := make(map[contentKey]bool, len(members))
+	for _, m := range members {
+		inCampaign[contentKey{m.Platform, m.ContentID}] = true
+	}
+
+	var items []*data.ContentItem
+	index := make(map[contentKey]int)
+	for _, item := range content {
+		key := contentKey{item.Platform, item.ID}
+		if !inCampaign[key] {
+			index[key] = len(items)
+			items = append(items, item)
+		}
+	}
+
+	links := make([][]string, len(items))
+	for i, item := range items {
+		for _, e := range data.ExtractEntities(item.Platform, item.ID, item.Title+"\n"+item.Body) {
+			if e.Type == data.EntityDomain {
+				links[i] = append(links[i], e.Text)
+			}
+		}
+	}
+	saved, err := a.store.ListContentEntities(ctx, data.EntityDomain, dateRange)
+	if err != nil {
+		return nil, fmt.Errorf("listing content links: %w", err)
+	}
+	for _, e := range saved {
+		if i, ok := index[contentKey{e.Platform, e.ContentID}]; ok {
+			links[i] = append(links[i], e.Text)
+		}
+	}
+
+	groups := newDisjointSet(len(items))
+	joined := linkedGroups(items, index, links, groups)
+
+	var suggestions []*CampaignSuggestion
+	linked := make([]bool, len(items))
+	for root, members := range groups.sets() {
+		reason, ok := joined[root]
+		if !ok || len(members) < 2 {
+			continue
+		}
+		for _, i := range members {
+			linked[i] = true
+		}
+		suggestion := &CampaignSuggestion{Source: reason.source, Key: reason.key}
+		for _, i := range members {
+			suggestion.Items = append(suggestion.Items, items[i])
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	suggestions = append(suggestions, similarGroups(items, links, linked)...)
+
+	for _, s := range suggestions {
+		sort.Slice(s.Items, func(i, j int) bool { return s.Items[i].PublishedAt.Before(s.Items[j].PublishedAt) })
+		s.Name = campaignName(s)
+	}
+	sourceOrder := map[data.CampaignSource]int{
+		data.CampaignSourceUTM:     0,
+		data.CampaignSourceLink:    1,
+		data.CampaignSourceSimilar: 2,
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		x, y := suggestions[i], suggestions[j]
+		if sourceOrder[x.Source] != sourceOrder[y.Source] {
+			return sourceOrder[x.Source] < sourceOrder[y.Source]
+		}
+		if len(x.Items) != len(y.Items) {
+			return len(x.Items) > len(y.Items)
+		}
+		if !x.Items[0].PublishedAt.Equal(y.Items[0].PublishedAt) {
+			return x.Items[0].PublishedAt.After(y.Items[0].PublishedAt)
+		}
+		return x.Items[0].ID < y.Items[0].ID
+	})
+	return suggestions[:min(len(suggestions), campaignSuggestionLimit)], nil
+}
+
+// groupReason is why linked content was grouped.
+type groupReason struct {
+	source data.CampaignSource
+	key    string
+}
+
+// linkedGroups joins items in groups that link to one another, share a
+// link or carry the same utm_campaign tag, and returns why each group's
+// root was joined. A shared utm_campaign tag takes precedence over a
+// shared link.
+func linkedGroups(items []*data.ContentItem, index map[contentKey]int, links [][]string, groups *disjointSet) map[int]groupReason {
+	byKey := make(map[groupReason][]int)
+	var keys []groupReason
+	add := func(reason groupReason, i int) {
+		for _, j := range byKey[reason] {
+			if j == i {
+				return
+			}
+		}
+		if byKey[reason] == nil {
+			keys = append(keys, reason)
+		}
+		byKey[reason] = append(byKey[reason], i)
+	}
+
+	for i, itemLinks := range links {
+		for _, link := range itemLinks {
+			if platform, id, ok := data.ParseContentURL(link); ok {
+				if j, ok := index[contentKey{platform, id}]; ok && j != i {
+					target := groupReason{data.CampaignSourceLink, items[j].URL()}
+					add(target, j)
+					add(target, i)
+				}
+			}
+			key, utm := normalizeCampaignLink(link)
+			if utm != "" {
+				add(groupReason{data.CampaignSourceUTM, utm}, i)
+			}
+			if key != "" {
+				add(groupReason{data.CampaignSourceLink, key}, i)
+			}
+		}
+	}
+
+	// UTM tags are applied first so that they name the groups they join.
+	sort.SliceStable(keys, func(a, b int) bool {
+		return keys[a].source == data.CampaignSourceUTM && keys[b].source != data.CampaignSourceUTM
+	})
+	joined := make(map[int]groupReason)
+	for _, reason := range keys {
+		members := byKey[reason]
+		if len(members) < 2 || (reason.source == data.CampaignSourceLink && len(members) > campaignSharedLinkLimit) {
+			continue
+		}
+		var first *groupReason
+		for _, i := range members {
+			if r, ok := joined[groups.find(i)]; ok && first == nil {
+				first = &r
+			}
+		}
+		for _, i := range members[1:] {
+			groups.union(members[0], i)
+		}
+		if first == nil {
+			first = &reason
+		}
+		joined[groups.find(members[0])] = *first
+	}
+	return joined
+}
+
+// similarGroups joins items not already linked whose text is similar to
+// an item's on another platform published around the same time.
+func similarGroups(items []*data.ContentItem, links [][]string, linked []bool) []*CampaignSuggestion {
+	terms := make([]map[string]bool, len(items))
+	docFreq := make(map[string]int)
+	for i, item := range items {
+		text := item.Title + "\n" + item.Body
+		for _, link := range links[i] {
+			text = strings.ReplaceAll(text, link, " ")
+		}
+		terms[i] = significantTerms(text)
+		for t := range terms[i] {
+			docFreq[t]++
+		}
+	}
+	weight := func(t string) float64 {
+		return math.Log(float64(len(items)+1) / float64(docFreq[t]))
+	}
+	norms := make([]float64, len(items))
+	for i := range items {
+		for t := range terms[i] {
+			norms[i] += weight(t) * weight(t)
+		}
+		norms[i] = math.Sqrt(norms[i])
+	}
+
+	groups := newDisjointSet(len(items))
+	lowest := make(map[int]float64) // By group root
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			if linked[i] || linked[j] || items[i].Platform == items[j].Platform {
+				continue
+			}
+			gap := items[i].PublishedAt.Sub(items[j].PublishedAt)
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap > campaignSimilarWindow || norms[i] == 0 || norms[j] == 0 {
+				continue
+			}
+
+			shared, similarity := 0, 0.0
+			for t := range terms[i] {
+				if terms[j][t] {
+					shared++
+					similarity += weight(t) * weight(t)
+				}
+			}
+			similarity /= norms[i] * norms[j]
+			if shared < campaignSharedTerms || similarity < campaignSimilarity {
+				continue
+			}
+
+			low := similarity
+			for _, root := range []int{groups.find(i), groups.find(j)} {
+				if s, ok := lowest[root]; ok {
+					low = min(low, s)
+				}
+			}
+			groups.union(i, j)
+			lowest[groups.find(i)] = low
+		}
+	}
+
+	var suggestions []*CampaignSuggestion
+	for root, members := range groups.sets() {
+		if len(members) < 2 {
+			continue
+		}
+		suggestion := &CampaignSuggestion{Source: data.CampaignSourceSimilar, Similarity: lowest[root]}
+		for _, i := range members {
+			suggestion.Items = append(suggestion.Items, items[i])
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions
+}
+
+// normalizeCampaignLink returns the form of a link compared when grouping
+// content, without tracking parameters, and its utm_campaign tag. key is
+// empty for links to a site's home page, which don't identify a piece of
+// work.
+func normalizeCampaignLink(link string) (key, utm string) {
+	u, err := url.Parse(link)
+	if err != nil || u.Hostname() == "" {
+		return "", ""
+	}
+	query := u.Query()
+	utm = strings.ToLower(strings.TrimSpace(query.Get("utm_campaign")))
+	for name := range query {
+		if strings.HasPrefix(name, "utm_") || trackingParams[name] {
+			query.Del(name)
+		}
+	}
+	path := strings.TrimSuffix(u.EscapedPath(), "/")
+	if path == "" && len(query) == 0 {
+		return "", utm
+	}
+	key = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") + path
+	if len(query) > 0 {
+		key += "?" + query.Encode()
+	}
+	return key, utm
+}
+
+// campaignName suggests a name for a campaign: its utm_campaign tag, or
+// else the title of its first titled item, such as a YouTube video, or the
+// start of its first item's text.
+func campaignName(s *CampaignSuggestion) string {
+	if s.Source == data.CampaignSourceUTM {
+		return snippet(strings.NewReplacer("_", " ", "-", " ", "+", " ").Replace(s.Key), campaignNameLength)
+	}
+	for _, item := range s.Items {
+		if item.Title != "" {
+			return snippet(item.Title, campaignNameLength)
+		}
+	}
+	return snippet(s.Items[0].Body, campaignNameLength)
+}
+
+// disjointSet tracks groups of indexes joined by union.
+type disjointSet struct {
+	parent []int
+}
+
+// newDisjointSet creates a disjointSet of n indexes, each in its own
+// group.
+func newDisjointSet(n int) *disjointSet {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	return &disjointSet{parent: parent}
+}
+
+// find returns the root of i's group.
+func (d *disjointSet) find(i int) int {
+	for d.parent[i] != i {
+		d.parent[i] = d.parent[d.parent[i]]
+		i = d.parent[i]
+	}
+	return d.parent[i]
+}
+
+// union joins the groups of i and j.
+func (d *disjointSet) union(i, j int) {
+	if ri, rj := d.find(i), d.find(j); ri != rj {
+		d.parent[rj] = ri
+	}
+}
+
+// sets returns the members of each group by root, in index order.
+func (d *disjointSet) sets() map[int][]int {
+	sets := make(map[int][]int)
+	for i := range d.parent {
+		root := d.find(i)
+		sets[root] = append(sets[root], i)
+	}
+	return sets
+}
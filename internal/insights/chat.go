@@ -0,0 +1,261 @@
+// Package insights provides the ask-your-analytics chat, which answers
+// questions with the LLM using read-only queries over stored data.
+package insights
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+const (
+	// chatMaxToolCalls limits the queries per answer. After the last one
+	// the model must answer with what it has.
+	chatMaxToolCalls = 6
+
+	// chatHistoryMessages is how many earlier messages of the
+	// conversation are included in the prompt, and chatHistoryLimit cuts
+	// each of them.
+	chatHistoryMessages = 10
+	chatHistoryLimit    = 1000
+
+	chatTitleLimit = 80
+)
+
+// ErrConversationNotFound is returned when asking in a conversation that
+// doesn't exist.
+var ErrConversationNotFound = errors.New("conversation not found")
+
+// chatStepSchema is the JSON schema requested for each step of an answer:
+// either a tool call or the answer.
+var chatStepSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "tool": {"type": "string"},
+    "arguments": {"type": "object"},
+    "answer": {"type": "string"},
+    "citations": {"type": "array", "items": {"type": "string"}}
+  }
+}`)
+
+// chatStep is a response in the format described by chatStepSchema.
+type chatStep struct {
+	Tool      string          `json:"tool"`
+	Arguments json.RawMessage `json:"arguments"`
+	Answer    string          `json:"answer"`
+	Citations []string        `json:"citations"`
+}
+
+// Analyst answers questions about the analytics data in persisted
+// conversations.
+type Analyst struct {
+	store storage.Store
+	tools *QueryTools
+	llm   *LLMClient
+}
+
+// NewAnalyst creates an Analyst. Its queries only use the read-only
+// QueryStore methods of store.
+func NewAnalyst(store storage.Store, llm *LLMClient) *Analyst {
+	return &Analyst{store: store, tools: NewQueryTools(store), llm: llm}
+}
+
+// Ask answers question in the conversation with conversationID, or in a
+// new conversation if conversationID is empty. The question and answer
+// are saved only if answering succeeds. It returns the conversation and
+// the two new messages.
+func (a *Analyst) Ask(ctx context.Context, conversationID, question string) (*data.Conversation, []*data.ChatMessage, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return nil, nil, errors.New("question is empty")
+	}
+
+	var conversation *data.Conversation
+	var history []*data.ChatMessage
+	if conversationID != "" {
+		var err error
+		if conversation, err = a.store.GetConversation(ctx, conversationID); err != nil {
+			return nil, nil, fmt.Errorf("getting conversation: %w", err)
+		}
+		if conversation == nil {
+			return nil, nil, ErrConversationNotFound
+		}
+		if history, err = a.store.GetChatMessages(ctx, conversationID); err != nil {
+			return nil, nil, fmt.Errorf("getting conversation messages: %w", err)
+		}
+	}
+
+	asked := time.Now()
+	answer, err := a.answer(ctx, history, question)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if conversation == nil {
+		conversation = &data.Conversation{
+			ID:    fmt.Sprintf("chat_%d", asked.UnixNano()),
+			Title: snippet(question, chatTitleLimit),
+		}
+		if err := a.store.SaveConversation(ctx, conversation); err != nil {
+			return nil, nil, fmt.Errorf("saving conversation: %w", err)
+		}
+	}
+	messages := []*data.ChatMessage{
+		{ConversationID: conversation.ID, Role: data.ChatRoleUser, Content: question, CreatedAt: asked},
+		answer,
+	}
+	answer.ConversationID = conversation.ID
+	if err := a.store.SaveChatMessages(ctx, messages); err != nil {
+		return nil, nil, fmt.Errorf("saving chat messages: %w", err)
+	}
+	conversation.UpdatedAt = answer.CreatedAt
+	return conversation, messages, nil
+}
+
+// answer runs the model until it answers question, calling the queries it
+// asks for and adding their results to the prompt.
+func (a *Analyst) answer(ctx context.Context, history []*data.ChatMessage, question string) (*data.ChatMessage, error) {
+	var calls []*data.ChatToolCall
+	for {
+		final := len(calls) >= chatMaxToolCalls
+		prompt := a.prompt(history, question, calls, final)
+
+		var step chatStep
+		err := a.llm.generateJSON(ctx, prompt, chatStepSchema, nil, nil, func(text string) error {
+			var err error
+			step, err = decodeChatStep(text, a.tools.Tools(), final)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("answering question: %w", err)
+		}
+
+		if step.Answer != "" {
+			return &data.ChatMessage{
+				Role:      data.ChatRoleAssistant,
+				Content:   step.Answer,
+				ToolCalls: calls,
+				Citations: citedRows(calls, step.Citations),
+				CreatedAt: time.Now(),
+			}, nil
+		}
+
+		call, err := a.tools.Call(ctx, step.Tool, step.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+}
+
+// prompt builds the prompt for the next step of an answer.
+func (a *Analyst) prompt(history []*data.ChatMessage, question string, calls []*data.ChatToolCall, final bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "You are an analytics assistant for a creator's YouTube, X and LinkedIn accounts. Today is %s.\n",
+		time.Now().Format("Monday, January 2, 2006"))
+	b.WriteString("Answer the user's question using only data returned by the tools below. Never invent numbers; if the data doesn't answer the question, say so.\n\nTools:\n")
+	for _, tool := range a.tools.Tools() {
+		fmt.Fprintf(&b, "- %s: %s\n  Arguments: %s\n", tool.Name, tool.Description, tool.Arguments)
+	}
+
+	if len(history) > 0 {
+		b.WriteString("\nConversation so far:\n")
+		for _, m := range history[max(len(history)-chatHistoryMessages, 0):] {
+			role := "User"
+			if m.Role == data.ChatRoleAssistant {
+				role = "Assistant"
+			}
+			fmt.Fprintf(&b, "%s: %s\n", role, snippet(m.Content, chatHistoryLimit))
+		}
+	}
+	fmt.Fprintf(&b, "\nQuestion: %s\n", question)
+
+	if len(calls) > 0 {
+		b.WriteString("\nTool results so far:\n")
+		for i, call := range calls {
+			fmt.Fprintf(&b, "%d. %s %s\n", i+1, call.Name, string(call.Arguments))
+			switch {
+			case call.Error != "":
+				fmt.Fprintf(&b, "  Error: %s\n", call.Error)
+			case len(call.Rows) == 0:
+				b.WriteString("  No rows.\n")
+			default:
+				formatRows(&b, call.Rows)
+			}
+		}
+	}
+
+	b.WriteString("\nRespond with only a JSON object, no other text.\n")
+	if !final {
+		b.WriteString(`To run a tool: {"tool": "tool name", "arguments": {...}}` + "\n")
+		b.WriteString("Once the results are enough to answer: ")
+	} else {
+		b.WriteString("You have run all the tools you can, so answer now: ")
+	}
+	b.WriteString(`{"answer": "your answer", "citations": ["ref", ...]}` + "\n")
+	b.WriteString("Cite the [refs] of the rows your answer relies on. Explain why where the data suggests a reason, and keep the answer short.")
+	return b.String()
+}
+
+// decodeChatStep parses and validates a step response. When final, only
+// an answer is accepted.
+func decodeChatStep(text string, tools []Tool, final bool) (chatStep, error) {
+	var step chatStep
+	raw, err := extractJSON(text)
+	if err != nil {
+		return step, err
+	}
+	if err := json.Unmarshal(raw, &step); err != nil {
+		return step, fmt.Errorf("response doesn't match the format: %v", err)
+	}
+	step.Answer = strings.TrimSpace(step.Answer)
+	step.Tool = strings.TrimSpace(step.Tool)
+
+	switch {
+	case step.Answer != "":
+		step.Tool = ""
+		return step, nil
+	case final:
+		return step, errors.New(`"answer" is required now; no more tools can be run`)
+	case step.Tool == "":
+		return step, errors.New(`respond with either "tool" and "arguments" or "answer"`)
+	}
+	for _, tool := range tools {
+		if tool.Name == step.Tool {
+			return step, nil
+		}
+	}
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name
+	}
+	return step, fmt.Errorf("unknown tool %q; use one of %s", step.Tool, strings.Join(names, ", "))
+}
+
+// citedRows returns the rows of calls with the cited refs, in citation
+// order. Refs that no query returned are dropped, so an answer can't cite
+// data it didn't see.
+func citedRows(calls []*data.ChatToolCall, refs []string) []*data.ChatRow {
+	rows := make(map[string]*data.ChatRow)
+	for _, call := range calls {
+		for _, row := range call.Rows {
+			rows[row.Ref] = row
+		}
+	}
+	var cited []*data.ChatRow
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		ref = strings.Trim(strings.TrimSpace(ref), "[]")
+		if row, ok := rows[ref]; ok && !seen[ref] {
+			seen[ref] = true
+			cited = append(cited, row)
+		}
+	}
+	return cited
+}
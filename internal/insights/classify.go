@@ -0,0 +1,298 @@
+// Package insights provides comment sentiment, intent and language
+// classification.
+package insights
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/config"
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// Comment classifier names, as used in InsightsConfig.
+const (
+	ClassifierLLM     = "llm"
+	ClassifierLexicon = "lexicon"
+)
+
+// classifyBatchesPerRun limits the batches ClassifyPending handles at once,
+// so a large backlog is worked through over several runs.
+const classifyBatchesPerRun = 10
+
+// classifyTextLimit cuts long comments in classification prompts.
+const classifyTextLimit = 500
+
+// commentLabelSchema is the JSON schema requested for comment labels.
+var commentLabelSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "labels": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "n": {"type": "integer"},
+          "sentiment": {"type": "string", "enum": ["positive", "neutral", "negative"]},
+          "score": {"type": "number", "minimum": -1, "maximum": 1},
+          "intent": {"type": "string", "enum": ["question", "praise", "complaint", "request", "spam", "other"]},
+          "language": {"type": "string"}
+        },
+        "required": ["n", "sentiment", "score", "intent", "language"]
+      }
+    }
+  },
+  "required": ["labels"]
+}`)
+
+// commentLabelInstructions describes commentLabelSchema in the prompt.
+const commentLabelInstructions = `Respond with only a JSON object, no other text, in this form:
+{"labels": [{
+  "n": comment number,
+  "sentiment": "positive" | "neutral" | "negative",
+  "score": number from -1 (most negative) to 1 (most positive),
+  "intent": "question" | "praise" | "complaint" | "request" | "spam" | "other",
+  "language": two-letter ISO 639-1 code such as "en", or "und" if unclear
+}]}
+Label every comment. Use "request" for asks for new content or features and
+"spam" for self-promotion, scams and links unrelated to the content.`
+
+// commentLabelItem is one label in the response format described by
+// commentLabelSchema.
+type commentLabelItem struct {
+	N         int     `json:"n"`
+	Sentiment string  `json:"sentiment"`
+	Score     float64 `json:"score"`
+	Intent    string  `json:"intent"`
+	Language  string  `json:"language"`
+}
+
+// ClassifyComments labels comments with the LLM. Comments the model
+// skipped or labeled invalidly are missing from the result; an error is
+// returned only if none could be labeled.
+func (c *LLMClient) ClassifyComments(ctx context.Context, comments []*data.Comment) ([]*data.CommentLabel, error) {
+	var prompt strings.Builder
+	prompt.WriteString("Classify the sentiment, intent and language of each of these audience comments on social media content.\n\n")
+	for i, comment := range comments {
+		fmt.Fprintf(&prompt, "%d. %s\n", i+1, snippet(comment.Text, classifyTextLimit))
+	}
+	prompt.WriteString("\n" + commentLabelInstructions)
+
+	var items []commentLabelItem
+	err := c.generateJSON(ctx, prompt.String(), commentLabelSchema, nil, nil, func(text string) error {
+		var err error
+		items, err = decodeCommentLabels(text, len(comments))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("classifying comments: %w", err)
+	}
+
+	labels := make([]*data.CommentLabel, 0, len(items))
+	for _, item := range items {
+		labels = append(labels, &data.CommentLabel{
+			CommentID: comments[item.N-1].ID,
+			Sentiment: item.Sentiment,
+			Score:     item.Score,
+			Intent:    item.Intent,
+			Language:  item.Language,
+			Source:    data.LabelSourceLLM,
+		})
+	}
+	return labels, nil
+}
+
+// decodeCommentLabels parses and validates a label response for n
+// comments, dropping invalid and repeated labels.
+func decodeCommentLabels(text string, n int) ([]commentLabelItem, error) {
+	raw, err := extractJSON(text)
+	if err != nil {
+		return nil, err
+	}
+	var output struct {
+		Labels []commentLabelItem `json:"labels"`
+	}
+	if err := json.Unmarshal(raw, &output); err != nil || len(output.Labels) == 0 {
+		// Accept a bare array as well as the requested form.
+		if json.Unmarshal(raw, &output.Labels) != nil || len(output.Labels) == 0 {
+			return nil, errors.New(`"labels" must be a non-empty array`)
+		}
+	}
+
+	seen := make(map[int]bool)
+	var valid []commentLabelItem
+	var problems []string
+	for _, item := range output.Labels {
+		if problem := item.normalize(n); problem != "" {
+			problems = append(problems, fmt.Sprintf("label %d: %s", item.N, problem))
+			continue
+		}
+		if !seen[item.N] {
+			seen[item.N] = true
+			valid = append(valid, item)
+		}
+	}
+	if len(valid) == 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+	return valid, nil
+}
+
+// normalize repairs common deviations from the schema in place and
+// returns a description of any problem it can't repair.
+func (item *commentLabelItem) normalize(n int) string {
+	if item.N < 1 || item.N > n {
+		return fmt.Sprintf("n must be between 1 and %d", n)
+	}
+
+	item.Sentiment = strings.ToLower(strings.TrimSpace(item.Sentiment))
+	switch item.Sentiment {
+	case data.SentimentPositive, data.SentimentNeutral, data.SentimentNegative:
+	default:
+		return fmt.Sprintf(`sentiment %q must be "positive", "neutral" or "negative"`, item.Sentiment)
+	}
+
+	// Tolerate plurals such as "questions".
+	item.Intent = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(item.Intent)), "s")
+	if !data.ValidIntent(item.Intent) {
+		return fmt.Sprintf("intent %q must be one of %s", item.Intent, strings.Join(data.Intents, ", "))
+	}
+
+	// Models sometimes answer with a percentage.
+	if item.Score > 1 && item.Score <= 100 || item.Score < -1 && item.Score >= -100 {
+		item.Score /= 100
+	}
+	item.Score = min(max(item.Score, -1), 1)
+	// Keep the score's sign consistent with the sentiment.
+	if item.Sentiment == data.SentimentPositive && item.Score <= 0 ||
+		item.Sentiment == data.SentimentNegative && item.Score >= 0 {
+		item.Score = -item.Score
+	}
+
+	item.Language = strings.ToLower(strings.TrimSpace(item.Language))
+	if len(item.Language) != 2 {
+		item.Language = data.LanguageUnknown
+	}
+	return ""
+}
+
+// Classifier labels new comments in batches, using the LLM when one is
+// configured and the offline lexicon otherwise or when the LLM fails.
+type Classifier struct {
+	store     storage.Store
+	llm       *LLMClient // nil to always use the lexicon
+	batchSize int
+}
+
+// NewClassifier creates a Classifier. llm may be nil, and is ignored if
+// the configured classifier is the lexicon.
+func NewClassifier(store storage.Store, llm *LLMClient, cfg config.InsightsConfig) *Classifier {
+	if cfg.CommentClassifier == ClassifierLexicon {
+		llm = nil
+	}
+	batchSize := cfg.CommentBatchSize
+	if batchSize <= 0 {
+		batchSize = 20
+	}
+	return &Classifier{store: store, llm: llm, batchSize: batchSize}
+}
+
+// ClassifyPending labels the most recent unlabeled comments and returns
+// how many it labeled. Comments labeled by the lexicon because the LLM was
+// unavailable are labeled again once it works, in the run's remaining
+// batches. It is meant to run as a scheduled task.
+func (c *Classifier) ClassifyPending(ctx context.Context) (int, error) {
+	limit := c.batchSize * classifyBatchesPerRun
+	comments, err := c.store.ListUnlabeledComments(ctx, limit)
+	if err != nil {
+		return 0, fmt.Errorf("listing unlabeled comments: %w", err)
+	}
+
+	useLLM := c.llm != nil
+	labeled := 0
+	for start := 0; start < len(comments); start += c.batchSize {
+		batch := comments[start:min(start+c.batchSize, len(comments))]
+		var labels []*data.CommentLabel
+		if useLLM {
+			labels, err = c.llm.ClassifyComments(ctx, batch)
+			if err != nil {
+				if ctx.Err() != nil {
+					return labeled, ctx.Err()
+				}
+				// The model is likely to keep failing, so don't wait on it
+				// for the rest of this run.
+				log.Printf("comment classification falling back to the lexicon: %v", err)
+				useLLM = false
+			}
+		}
+		labels = completeLabels(batch, labels)
+
+		if err := c.store.SaveCommentLabels(ctx, labels); err != nil {
+			return labeled, fmt.Errorf("saving comment labels: %w", err)
+		}
+		labeled += len(labels)
+	}
+
+	if !useLLM || len(comments) >= limit {
+		return labeled, nil
+	}
+	relabeled, err := c.relabelLexicon(ctx, limit-len(comments))
+	return labeled + relabeled, err
+}
+
+// relabelLexicon labels up to limit comments previously labeled by the
+// lexicon with the LLM, stopping at the first batch the LLM fails on.
+// Comments the model skips keep their lexicon label but move to the back
+// of the queue.
+func (c *Classifier) relabelLexicon(ctx context.Context, limit int) (int, error) {
+	comments, err := c.store.ListLexiconLabeledComments(ctx, limit)
+	if err != nil {
+		return 0, fmt.Errorf("listing lexicon-labeled comments: %w", err)
+	}
+
+	relabeled := 0
+	for start := 0; start < len(comments); start += c.batchSize {
+		batch := comments[start:min(start+c.batchSize, len(comments))]
+		labels, err := c.llm.ClassifyComments(ctx, batch)
+		if err != nil {
+			if ctx.Err() != nil {
+				return relabeled, ctx.Err()
+			}
+			log.Printf("comment relabeling stopped: %v", err)
+			return relabeled, nil
+		}
+		if err := c.store.SaveCommentLabels(ctx, completeLabels(batch, labels)); err != nil {
+			return relabeled, fmt.Errorf("saving comment labels: %w", err)
+		}
+		relabeled += len(labels)
+	}
+	return relabeled, nil
+}
+
+// completeLabels adds lexicon labels for the comments in batch missing
+// from labels, and fills in the language where the model couldn't tell.
+func completeLabels(batch []*data.Comment, labels []*data.CommentLabel) []*data.CommentLabel {
+	byID := make(map[string]*data.CommentLabel, len(labels))
+	for _, l := range labels {
+		byID[l.CommentID] = l
+	}
+	now := time.Now()
+	complete := make([]*data.CommentLabel, 0, len(batch))
+	for _, comment := range batch {
+		label, ok := byID[comment.ID]
+		if !ok {
+			label = lexiconLabel(comment)
+		} else if label.Language == data.LanguageUnknown {
+			label.Language = detectLanguage(comment.Text, lexiconWords(strings.ToLower(comment.Text)))
+		}
+		label.LabeledAt = now
+		complete = append(complete, label)
+	}
+	return complete
+}
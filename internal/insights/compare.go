@@ -0,0 +1,264 @@
+// Package insights provides period-over-period comparisons of platform
+// metrics.
+package insights
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// minFlowCoverage is the share of a period that must have data before a
+// flow metric's total is extrapolated to the whole period.
+const minFlowCoverage = 0.5
+
+// MetricAggregation is how a metric's observations in a period are
+// combined into a single value.
+type MetricAggregation string
+
+const (
+	// AggregateSum totals flow metrics, such as views, over the period.
+	AggregateSum MetricAggregation = "sum"
+	// AggregateLast takes the last value of stock metrics, such as
+	// followers, at the end of the period.
+	AggregateLast MetricAggregation = "last"
+	// AggregateMean averages rate metrics, such as engagement rate.
+	AggregateMean MetricAggregation = "mean"
+)
+
+// metricAggregations lists the metrics that aren't flows.
+var metricAggregations = map[string]MetricAggregation{
+	"subscribers":     AggregateLast,
+	"channel_views":   AggregateLast,
+	"videos":          AggregateLast,
+	"followers":       AggregateLast,
+	"following":       AggregateLast,
+	"tweets":          AggregateLast,
+	"listed":          AggregateLast,
+	"connections":     AggregateLast,
+	"engagement_rate": AggregateMean,
+}
+
+// AggregationFor returns how metric is aggregated over a period. Metrics
+// not known to be stocks or rates are treated as flows.
+func AggregationFor(metric string) MetricAggregation {
+	if aggregation, ok := metricAggregations[metric]; ok {
+		return aggregation
+	}
+	return AggregateSum
+}
+
+// PlatformMetrics are the metrics compared for each platform when looking
+// for the biggest movers.
+var PlatformMetrics = map[data.Platform][]string{
+	data.PlatformYouTube:  {"views", "likes", "comments", "posts", "engagement_rate", "subscribers"},
+	data.PlatformX:        {"impressions", "likes", "comments", "shares", "posts", "engagement_rate", "followers"},
+	data.PlatformLinkedIn: {"impressions", "likes", "comments", "shares", "posts", "engagement_rate", "connections", "followers"},
+}
+
+// Comparison is a standard period-over-period comparison.
+type Comparison string
+
+const (
+	ComparisonWeek  Comparison = "wow" // Last 7 days vs the 7 days before
+	ComparisonMonth Comparison = "mom" // Last 30 days vs the 30 days before
+	ComparisonYear  Comparison = "yoy" // Last 30 days vs the same days a year earlier
+)
+
+// Comparisons lists the comparisons in display order.
+var Comparisons = []Comparison{ComparisonWeek, ComparisonMonth, ComparisonYear}
+
+// ParseComparison parses a comparison name, defaulting to week over week
+// when s is empty.
+func ParseComparison(s string) (Comparison, error) {
+	if s == "" {
+		return ComparisonWeek, nil
+	}
+	for _, c := range Comparisons {
+		if string(c) == s {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unknown comparison %q", s)
+}
+
+// Label returns the comparison's display name.
+func (c Comparison) Label() string {
+	switch c {
+	case ComparisonMonth:
+		return "Month over month"
+	case ComparisonYear:
+		return "Year over year"
+	default:
+		return "Week over week"
+	}
+}
+
+// Ranges returns the current range ending at end and the previous range
+// it's compared with. Days are calendar days in end's location, so the
+// ranges stay aligned across daylight saving changes.
+func (c Comparison) Ranges(end time.Time) (current, previous data.DateRange) {
+	switch c {
+	case ComparisonMonth:
+		current = data.DateRange{Start: end.AddDate(0, 0, -30), End: end}
+		previous = data.DateRange{Start: end.AddDate(0, 0, -60), End: current.Start}
+	case ComparisonYear:
+		current = data.DateRange{Start: end.AddDate(0, 0, -30), End: end}
+		previous = data.DateRange{Start: current.Start.AddDate(-1, 0, 0), End: end.AddDate(-1, 0, 0)}
+	default:
+		current = data.DateRange{Start: end.AddDate(0, 0, -7), End: end}
+		previous = data.DateRange{Start: end.AddDate(0, 0, -14), End: current.Start}
+	}
+	return current, previous
+}
+
+// CompareOverPeriod compares a metric over the current range of
+// comparison ending at end with its previous range.
+func (t *TrendAnalyzer) CompareOverPeriod(ctx context.Context, platform data.Platform, metric string, comparison Comparison, end time.Time) (*PeriodComparison, error) {
+	current, previous := comparison.Ranges(end)
+	return t.ComparePeriods(ctx, platform, metric, current, previous)
+}
+
+// BiggestMovers compares every platform metric over comparison ending at
+// end and returns up to limit of them, largest percentage change first.
+// Metrics without data in both periods or with a previous value of zero,
+// whose percentage change is undefined, are left out.
+func (t *TrendAnalyzer) BiggestMovers(ctx context.Context, comparison Comparison, end time.Time, limit int) ([]*PeriodComparison, error) {
+	var movers []*PeriodComparison
+	for _, platform := range promptPlatforms("") {
+		for _, metric := range PlatformMetrics[platform] {
+			c, err := t.CompareOverPeriod(ctx, platform, metric, comparison, end)
+			if err != nil {
+				return nil, err
+			}
+			if c == nil || c.Period2Value == 0 {
+				continue
+			}
+			movers = append(movers, c)
+		}
+	}
+
+	sort.SliceStable(movers, func(i, j int) bool {
+		return math.Abs(movers[i].ChangePercent) > math.Abs(movers[j].ChangePercent)
+	})
+	if limit > 0 && len(movers) > limit {
+		movers = movers[:limit]
+	}
+	return movers, nil
+}
+
+// periodValue aggregates a metric over dateRange. It returns the value,
+// the share of the range covered by data and whether there was enough
+// data to compute it.
+func (t *TrendAnalyzer) periodValue(ctx context.Context, platform data.Platform, metric string, aggregation MetricAggregation, dateRange data.DateRange) (float64, float64, bool, error) {
+	if aggregation == AggregateLast {
+		// Carry the last value forward from up to a period before the
+		// range when it has none of its own.
+		lookback := data.DateRange{Start: dateRange.Start.Add(-dateRange.End.Sub(dateRange.Start)), End: dateRange.End}
+		points, err := t.store.GetMetricSeries(ctx, platform, metric, lookback)
+		if err != nil {
+			return 0, 0, false, fmt.Errorf("getting %s series: %w", metric, err)
+		}
+		if len(points) == 0 {
+			return 0, 0, false, nil
+		}
+		last := points[len(points)-1]
+		if last.Timestamp.Before(dateRange.Start) {
+			return last.Value, 0, true, nil
+		}
+		return last.Value, 1, true, nil
+	}
+
+	start, err := t.store.GetMetricStart(ctx, platform, metric)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("getting %s start: %w", metric, err)
+	}
+	coverage := rangeCoverage(dateRange, start, time.Now())
+	if start.IsZero() || coverage == 0 {
+		return 0, 0, false, nil
+	}
+	points, err := t.store.GetMetricSeries(ctx, platform, metric, dateRange)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("getting %s series: %w", metric, err)
+	}
+
+	var sum float64
+	for _, p := range points {
+		sum += p.Value
+	}
+	if aggregation == AggregateMean {
+		if len(points) == 0 {
+			return 0, coverage, false, nil
+		}
+		return sum / float64(len(points)), coverage, true, nil
+	}
+	if coverage < minFlowCoverage {
+		return 0, coverage, false, nil
+	}
+	return sum / coverage, coverage, true, nil
+}
+
+// rangeCoverage returns the share of dateRange between the day data
+// begins on and now.
+func rangeCoverage(dateRange data.DateRange, start, now time.Time) float64 {
+	length := dateRange.End.Sub(dateRange.Start)
+	if start.IsZero() || length <= 0 {
+		return 0
+	}
+	start = startOfDay(start.In(dateRange.Start.Location()))
+	from, to := dateRange.Start, dateRange.End
+	if start.After(from) {
+		from = start
+	}
+	if now.Before(to) {
+		to = now
+	}
+	if !to.After(from) {
+		return 0
+	}
+	return to.Sub(from).Seconds() / length.Seconds()
+}
+
+// startOfDay returns midnight at the start of t's day in its location.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// trendDirection classifies a percentage change as "up", "down" or
+// "stable".
+func trendDirection(changePercent float64) string {
+	switch {
+	case changePercent > 5:
+		return "up"
+	case changePercent < -5:
+		return "down"
+	default:
+		return "stable"
+	}
+}
+
+// Label formats the change for display, e.g. "+12.5%", or the absolute
+// change when the previous value is zero.
+func (c *PeriodComparison) Label() string {
+	if c.Period2Value == 0 {
+		return fmt.Sprintf("%+.0f", c.Change)
+	}
+	return fmt.Sprintf("%+.1f%%", c.ChangePercent)
+}
+
+// Partial reports whether either period is only partly covered by data.
+func (c *PeriodComparison) Partial() bool {
+	return c.Period1Coverage < 1 || c.Period2Coverage < 1
+}
+
+// FormatValue formats one of the comparison's values for display.
+func (c *PeriodComparison) FormatValue(value float64) string {
+	if c.Metric == "engagement_rate" {
+		return fmt.Sprintf("%.2f%%", value)
+	}
+	return formatNumber(math.Round(value))
+}
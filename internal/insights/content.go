@@ -0,0 +1,97 @@
+// Package insights provides per-content aggregation for detail pages.
+package insights
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// ContentDetail contains everything shown on a single content item's page.
+type ContentDetail struct {
+	Item     *data.ContentItem       `json:"item"`
+	Source   interface{}             `json:"source"` // *data.Video, *data.Tweet or *data.LinkedInPost
+	History  []*data.ContentSnapshot `json:"history"`
+	Comments []*data.Comment         `json:"comments"`
+	Insights []*data.Insight         `json:"insights"`
+	Ranks    []*ContentRank          `json:"ranks"`
+}
+
+// ContentRank describes where a content item places among the account's
+// content on the same platform.
+type ContentRank struct {
+	Sort       data.ContentSort `json:"sort"`
+	Label      string           `json:"label"`
+	Rank       int              `json:"rank"`
+	Total      int              `json:"total"`
+	Percentile float64          `json:"percentile"` // Share of content ranked below, 0-100
+}
+
+// rankedSorts lists the rankings shown on content detail pages.
+var rankedSorts = []struct {
+	sort  data.ContentSort
+	label string
+}{
+	{data.SortByViews, "Views"},
+	{data.SortByEngagement, "Engagement rate"},
+}
+
+// contentInsightLimit caps the related insights shown for an item.
+const contentInsightLimit = 5
+
+// GetContentDetail retrieves a content item with its metric history,
+// comments, related insights and rankings. It returns nil if the item
+// does not exist.
+func (a *Aggregator) GetContentDetail(ctx context.Context, platform data.Platform, id string, period Period) (*ContentDetail, error) {
+	item, err := a.store.GetContentItem(ctx, platform, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting content item: %w", err)
+	}
+	if item == nil {
+		return nil, nil
+	}
+
+	detail := &ContentDetail{Item: item}
+
+	switch platform {
+	case data.PlatformYouTube:
+		detail.Source, err = a.store.GetVideo(ctx, id)
+	case data.PlatformX:
+		detail.Source, err = a.store.GetTweet(ctx, id)
+	case data.PlatformLinkedIn:
+		detail.Source, err = a.store.GetLinkedInPost(ctx, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting %s content: %w", platform, err)
+	}
+
+	if detail.History, err = a.store.GetContentSnapshots(ctx, platform, id, period.Current); err != nil {
+		return nil, fmt.Errorf("getting content history: %w", err)
+	}
+	if detail.Comments, err = a.store.GetComments(ctx, platform, id); err != nil {
+		return nil, fmt.Errorf("getting comments: %w", err)
+	}
+	if detail.Insights, err = a.store.GetContentInsights(ctx, platform, id, contentInsightLimit); err != nil {
+		return nil, fmt.Errorf("getting content insights: %w", err)
+	}
+
+	for _, r := range rankedSorts {
+		rank, total, err := a.store.GetContentRank(ctx, platform, id, r.sort)
+		if err != nil {
+			return nil, fmt.Errorf("ranking content by %s: %w", r.sort, err)
+		}
+		if total == 0 {
+			continue
+		}
+		detail.Ranks = append(detail.Ranks, &ContentRank{
+			Sort:       r.sort,
+			Label:      r.label,
+			Rank:       rank,
+			Total:      total,
+			Percentile: float64(total-rank) / float64(total) * 100,
+		})
+	}
+
+	return detail, nil
+}
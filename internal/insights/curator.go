@@ -0,0 +1,118 @@
+// Package insights provides de-duplication, expiry and feedback for saved
+// insights.
+package insights
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/config"
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// dedupLimit caps the recent insights new ones are compared against.
+const dedupLimit = 200
+
+// Curator saves generated insights, skipping ones too similar to recent
+// insights, and lists the insights that haven't expired or been dismissed.
+type Curator struct {
+	store     storage.Store
+	ttl       time.Duration
+	threshold float64
+}
+
+// NewCurator creates a Curator.
+func NewCurator(store storage.Store, cfg config.InsightsConfig) *Curator {
+	return &Curator{store: store, ttl: cfg.TTL, threshold: cfg.SimilarityThreshold}
+}
+
+// Save saves the generated insights that aren't duplicates of each other
+// or of an insight generated within the TTL, including dismissed ones. It
+// returns the insights saved.
+func (c *Curator) Save(ctx context.Context, generated []*GeneratedInsight) ([]*GeneratedInsight, error) {
+	recent, err := c.store.ListInsights(ctx, data.InsightFilter{
+		Since:            c.since(),
+		IncludeDismissed: true,
+		Limit:            dedupLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting recent insights: %w", err)
+	}
+
+	var saved []*GeneratedInsight
+	for _, g := range generated {
+		if match := c.duplicateOf(g.Insight, recent); match != nil {
+			log.Printf("skipping insight %q: similar to %s %q", g.Insight.Title, match.ID, match.Title)
+			continue
+		}
+		if err := c.store.SaveInsight(ctx, g.Insight); err != nil {
+			return saved, err
+		}
+		if g.Detail != nil {
+			if err := c.store.SaveInsightDetail(ctx, g.Detail); err != nil {
+				return saved, err
+			}
+		}
+		saved = append(saved, g)
+		recent = append(recent, g.Insight)
+	}
+	return saved, nil
+}
+
+// Active lists the most recent insights for platform (empty for all
+// platforms) that haven't expired or been dismissed.
+func (c *Curator) Active(ctx context.Context, platform data.Platform, limit int) ([]*data.Insight, error) {
+	insightsList, err := c.store.ListInsights(ctx, data.InsightFilter{
+		Platform: platform,
+		Since:    c.since(),
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing insights: %w", err)
+	}
+	return insightsList, nil
+}
+
+// since returns the generation time of the oldest unexpired insight.
+func (c *Curator) since() time.Time {
+	if c.ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-c.ttl)
+}
+
+// duplicateOf returns the insight in recent that insight duplicates, or nil.
+func (c *Curator) duplicateOf(insight *data.Insight, recent []*data.Insight) *data.Insight {
+	for _, r := range recent {
+		if r.Platform != insight.Platform {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(r.Title), strings.TrimSpace(insight.Title)) ||
+			Similarity(r, insight) >= c.threshold {
+			return r
+		}
+	}
+	return nil
+}
+
+// Similarity returns the overlap between the significant words of two
+// insights' titles and descriptions, from 0 for none to 1 for the same
+// words.
+func Similarity(a, b *data.Insight) float64 {
+	termsA := significantTerms(a.Title + " " + a.Description)
+	termsB := significantTerms(b.Title + " " + b.Description)
+	if len(termsA) == 0 || len(termsB) == 0 {
+		return 0
+	}
+	shared := 0
+	for term := range termsA {
+		if termsB[term] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(termsA)+len(termsB)-shared)
+}
@@ -0,0 +1,187 @@
+// Package insights provides text embeddings for grouping content and
+// comments by topic.
+package insights
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+
+	"github.com/omnipulse/omnipulse/internal/config"
+)
+
+// Embedder turns texts into vectors whose cosine similarity reflects how
+// alike the texts are in meaning.
+type Embedder interface {
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+
+	// EmbeddingModel identifies the model. Vectors from different models
+	// can't be compared.
+	EmbeddingModel() string
+}
+
+// NewEmbedder creates the embedder for the LLM backend selected by
+// cfg.Backend, using cfg.EmbeddingModel.
+func NewEmbedder(cfg config.LLMConfig) (Embedder, error) {
+	switch cfg.Backend {
+	case "", "ollama":
+		return NewOllama(cfg), nil
+	case "openai":
+		return NewOpenAI(cfg), nil
+	case "fake":
+		return NewFakeLLM(), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM backend %q", cfg.Backend)
+	}
+}
+
+// OllamaEmbeddingRequest represents a request to Ollama's /api/embeddings.
+type OllamaEmbeddingRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+// OllamaEmbeddingResponse represents a response from /api/embeddings.
+type OllamaEmbeddingResponse struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+// EmbeddingModel returns the configured embedding model.
+func (o *Ollama) EmbeddingModel() string { return o.embeddingModel }
+
+// Embed returns the embeddings of texts. /api/embeddings takes one text
+// per request, so texts are embedded in turn; the configured timeout
+// applies to each.
+func (o *Ollama) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector, err := o.embed(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+func (o *Ollama) embed(ctx context.Context, text string) ([]float32, error) {
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	req := &OllamaEmbeddingRequest{Model: o.embeddingModel, Prompt: text}
+	resp, err := postJSON(ctx, o.httpClient, o.endpoint+"/api/embeddings", nil, req)
+	if err != nil {
+		return nil, transportError(ctx, o.Backend(), o.embeddingModel, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, o.statusError(resp)
+	}
+
+	var embResp OllamaEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
+		return nil, transportError(ctx, o.Backend(), o.embeddingModel, fmt.Errorf("decoding response: %w", err))
+	}
+	if len(embResp.Embedding) == 0 {
+		return nil, &LLMError{Backend: o.Backend(), Model: o.embeddingModel, StatusCode: resp.StatusCode,
+			Message: "response has no embedding; is this an embedding model?"}
+	}
+	return embResp.Embedding, nil
+}
+
+// EmbeddingRequest represents a request to the OpenAI embeddings API.
+type EmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// EmbeddingResponse represents a response from the embeddings API.
+type EmbeddingResponse struct {
+	Model string `json:"model"`
+	Data  []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// EmbeddingModel returns the configured embedding model.
+func (o *OpenAI) EmbeddingModel() string { return o.embeddingModel }
+
+// Embed returns the embeddings of texts in a single request.
+func (o *OpenAI) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	req := &EmbeddingRequest{Model: o.embeddingModel, Input: texts}
+	resp, err := postJSON(ctx, o.httpClient, o.endpoint+"/embeddings", o.headers(), req)
+	if err != nil {
+		return nil, transportError(ctx, o.Backend(), o.embeddingModel, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, o.statusError(resp)
+	}
+
+	var embResp EmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
+		return nil, transportError(ctx, o.Backend(), o.embeddingModel, fmt.Errorf("decoding response: %w", err))
+	}
+
+	// Servers may return the embeddings in any order.
+	vectors := make([][]float32, len(texts))
+	for _, d := range embResp.Data {
+		if d.Index >= 0 && d.Index < len(vectors) {
+			vectors[d.Index] = d.Embedding
+		}
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, &LLMError{Backend: o.Backend(), Model: o.embeddingModel, StatusCode: resp.StatusCode,
+				Message: fmt.Sprintf("response has no embedding for input %d", i)}
+		}
+	}
+	return vectors, nil
+}
+
+// fakeEmbeddingDims is the length of FakeLLM embeddings.
+const fakeEmbeddingDims = 64
+
+// EmbeddingModel returns "fake".
+func (f *FakeLLM) EmbeddingModel() string { return "fake" }
+
+// Embed returns deterministic embeddings that hash each significant word
+// of a text into a dimension, so texts sharing words are similar.
+func (f *FakeLLM) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, fakeEmbeddingDims)
+		for term := range significantTerms(text) {
+			h := fnv.New32a()
+			h.Write([]byte(term))
+			vector[h.Sum32()%fakeEmbeddingDims]++
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
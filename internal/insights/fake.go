@@ -0,0 +1,90 @@
+// Package insights provides a deterministic fake LLM backend.
+package insights
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// FakeLLM is a deterministic LLM for tests and for running the dashboard
+// without a model server. It returns its canned responses in order,
+// repeating the last one, or a summary of the prompt if it has none.
+type FakeLLM struct {
+	mu        sync.Mutex
+	responses []string
+	requests  []GenerateRequest
+	calls     int
+
+	// Err, when set, is returned by every call to Generate.
+	Err error
+}
+
+// NewFakeLLM creates a FakeLLM returning responses in order.
+func NewFakeLLM(responses ...string) *FakeLLM {
+	return &FakeLLM{responses: responses}
+}
+
+// Backend returns "fake".
+func (f *FakeLLM) Backend() string { return "fake" }
+
+// Generate records req and returns the next canned response.
+func (f *FakeLLM) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, *req)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	text := f.next(req)
+	f.calls++
+	return &GenerateResponse{
+		Text:             text,
+		Model:            "fake",
+		PromptTokens:     len(strings.Fields(req.Prompt)),
+		CompletionTokens: len(strings.Fields(text)),
+	}, nil
+}
+
+// Stream returns the next canned response like Generate, passing it to
+// onToken a word at a time.
+func (f *FakeLLM) Stream(ctx context.Context, req *GenerateRequest, onToken StreamFunc) (*GenerateResponse, error) {
+	resp, err := f.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range strings.SplitAfter(resp.Text, " ") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if token == "" {
+			continue
+		}
+		if err := onToken(token); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// next returns the response for the current call.
+func (f *FakeLLM) next(req *GenerateRequest) string {
+	if len(f.responses) == 0 {
+		firstLine, _, _ := strings.Cut(strings.TrimSpace(req.Prompt), "\n")
+		return fmt.Sprintf("Fake response to: %s", firstLine)
+	}
+	return f.responses[min(f.calls, len(f.responses)-1)]
+}
+
+// Requests returns the requests received so far.
+func (f *FakeLLM) Requests() []GenerateRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]GenerateRequest(nil), f.requests...)
+}
@@ -0,0 +1,457 @@
+// Package insights provides metric forecasts with prediction intervals,
+// from Holt-Winters exponential smoothing or a linear baseline, and
+// backtests of their accuracy.
+package insights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+const (
+	// forecastHistoryDays is how many complete days of a metric are
+	// forecast from.
+	forecastHistoryDays = 90
+	// MaxForecastDays limits how far ahead a metric is projected.
+	MaxForecastDays = 365
+
+	// ForecastInterval is the coverage of the prediction intervals, and
+	// forecastZ the normal quantile that gives it.
+	ForecastInterval = 0.95
+	forecastZ        = 1.96
+
+	// Minimum points each model is fitted to. Holt-Winters needs two
+	// weekly cycles to initialize its seasonal pattern.
+	minLinearPoints      = 3
+	minHoltWintersPoints = 2 * seasonPeriod
+
+	// Backtests hold out up to a quarter of the series, and at least
+	// minBacktestHoldout points.
+	backtestShare      = 0.25
+	minBacktestHoldout = 3
+)
+
+// ErrNotEnoughData is returned when a series is too short to forecast.
+var ErrNotEnoughData = errors.New("not enough data to forecast")
+
+// ForecastModel is a forecasting model.
+type ForecastModel string
+
+const (
+	// ForecastAuto uses whichever model backtests best.
+	ForecastAuto ForecastModel = "auto"
+	// ForecastHoltWinters is additive Holt-Winters exponential smoothing
+	// with a damped trend and, for daily series, a weekly season.
+	ForecastHoltWinters ForecastModel = "holt_winters"
+	// ForecastLinear extends a least-squares line through the series.
+	ForecastLinear ForecastModel = "linear"
+)
+
+// ForecastModels lists the models in display order.
+var ForecastModels = []ForecastModel{ForecastAuto, ForecastHoltWinters, ForecastLinear}
+
+// ParseForecastModel parses a model name, defaulting to ForecastAuto when
+// s is empty.
+func ParseForecastModel(s string) (ForecastModel, error) {
+	if s == "" {
+		return ForecastAuto, nil
+	}
+	for _, m := range ForecastModels {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown forecast model %q", s)
+}
+
+// Label returns the model's display name.
+func (m ForecastModel) Label() string {
+	switch m {
+	case ForecastHoltWinters:
+		return "Holt-Winters"
+	case ForecastLinear:
+		return "Linear"
+	default:
+		return "Best fit"
+	}
+}
+
+// ForecastPoint is a projected value and its prediction interval.
+type ForecastPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+	Lower     float64   `json:"lower"`
+	Upper     float64   `json:"upper"`
+}
+
+// Backtest is a model's accuracy forecasting the last Holdout points of a
+// series from the points before them.
+type Backtest struct {
+	Model   ForecastModel `json:"model"`
+	Holdout int           `json:"holdout"`
+	MAE     float64       `json:"mae"`            // Mean absolute error
+	MAPE    float64       `json:"mape,omitempty"` // Mean absolute percentage error, over non-zero actuals
+	RMSE    float64       `json:"rmse"`           // Root mean squared error
+
+	// Share of held-out points inside their prediction intervals, which
+	// should be close to ForecastInterval.
+	Coverage float64 `json:"coverage"`
+}
+
+// Forecast is a metric projected ahead of its last observation.
+type Forecast struct {
+	Platform  data.Platform   `json:"platform"`
+	Metric    string          `json:"metric"`
+	Model     ForecastModel   `json:"model"`
+	Interval  float64         `json:"interval"`
+	History   int             `json:"history"` // Points the forecast was fitted to
+	Points    []ForecastPoint `json:"points"`
+	Backtests []*Backtest     `json:"backtests,omitempty"`
+}
+
+// Backtest returns the backtest of the forecast's model, or nil if it
+// wasn't backtested.
+func (f *Forecast) Backtest() *Backtest {
+	for _, b := range f.Backtests {
+		if b.Model == f.Model {
+			return b
+		}
+	}
+	return nil
+}
+
+// At returns the projected point on or just before t, or nil if t is
+// outside the forecast.
+func (f *Forecast) At(t time.Time) *ForecastPoint {
+	var at *ForecastPoint
+	for i := range f.Points {
+		if f.Points[i].Timestamp.After(t) {
+			break
+		}
+		at = &f.Points[i]
+	}
+	return at
+}
+
+// Forecast projects a metric's daily series horizon days past the last
+// complete day, fitted to the forecastHistoryDays before it.
+func (t *TrendAnalyzer) Forecast(ctx context.Context, platform data.Platform, metric string, horizon int, model ForecastModel) (*Forecast, error) {
+	end := startOfDay(time.Now())
+	points, err := t.DailySeries(ctx, platform, metric, data.DateRange{Start: end.AddDate(0, 0, -forecastHistoryDays), End: end})
+	if err != nil {
+		return nil, err
+	}
+	return ForecastTrend(&data.TrendData{Platform: platform, Metric: metric, Points: points}, horizon, model)
+}
+
+// ForecastTrend projects a trend horizon steps past its last point, where
+// a step is the typical gap between its points. Daily series are given a
+// weekly season. The points are backtested with each model, and
+// ForecastAuto picks the model with the lowest mean absolute error.
+// Series that never go negative aren't projected below zero.
+func ForecastTrend(trend *data.TrendData, horizon int, model ForecastModel) (*Forecast, error) {
+	if trend == nil || len(trend.Points) < minLinearPoints {
+		return nil, ErrNotEnoughData
+	}
+	if horizon <= 0 || horizon > MaxForecastDays {
+		return nil, fmt.Errorf("forecast horizon must be between 1 and %d", MaxForecastDays)
+	}
+
+	points := append([]data.DataPoint(nil), trend.Points...)
+	sort.Slice(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+	values := pointValues(points)
+	step, daily := forecastStep(points)
+	period := 0
+	if daily {
+		period = seasonPeriod
+	}
+
+	result := &Forecast{
+		Platform: trend.Platform,
+		Metric:   trend.Metric,
+		Model:    model,
+		Interval: ForecastInterval,
+		History:  len(points),
+	}
+	for _, m := range []ForecastModel{ForecastHoltWinters, ForecastLinear} {
+		if b := backtest(m, values, period, horizon); b != nil {
+			result.Backtests = append(result.Backtests, b)
+		}
+	}
+	if model == ForecastAuto {
+		result.Model = ForecastHoltWinters
+		if len(values) < minHoltWintersPoints {
+			result.Model = ForecastLinear
+		}
+		for _, b := range result.Backtests {
+			if best := result.Backtest(); best == nil || b.MAE < best.MAE {
+				result.Model = b.Model
+			}
+		}
+	}
+
+	projected, ok := forecastValues(result.Model, values, period, horizon)
+	if !ok {
+		return nil, ErrNotEnoughData
+	}
+	nonNegative := true
+	for _, v := range values {
+		if v < 0 {
+			nonNegative = false
+			break
+		}
+	}
+
+	last := points[len(points)-1].Timestamp
+	for h, p := range projected {
+		if daily {
+			p.Timestamp = last.AddDate(0, 0, h+1)
+		} else {
+			p.Timestamp = last.Add(time.Duration(h+1) * step)
+		}
+		if nonNegative {
+			p.Value = math.Max(p.Value, 0)
+			p.Lower = math.Max(p.Lower, 0)
+			p.Upper = math.Max(p.Upper, 0)
+		}
+		result.Points = append(result.Points, p)
+	}
+	return result, nil
+}
+
+// forecastStep returns the median gap between points and whether it's
+// about a day.
+func forecastStep(points []data.DataPoint) (time.Duration, bool) {
+	gaps := make([]float64, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		gaps = append(gaps, float64(points[i].Timestamp.Sub(points[i-1].Timestamp)))
+	}
+	step := time.Duration(median(gaps))
+	// Allow for daylight saving changes.
+	return step, step >= 23*time.Hour && step <= 25*time.Hour
+}
+
+// forecastValues projects values horizon steps ahead with model. It
+// reports false when there are too few values for the model.
+func forecastValues(model ForecastModel, values []float64, period, horizon int) ([]ForecastPoint, bool) {
+	if model == ForecastHoltWinters {
+		return holtWinters(values, period, horizon)
+	}
+	return linearForecast(values, horizon)
+}
+
+// backtest fits model to all but the last holdout values and measures its
+// forecast of them, or returns nil if the series is too short to spare
+// them.
+func backtest(model ForecastModel, values []float64, period, horizon int) *Backtest {
+	holdout := min(horizon, int(float64(len(values))*backtestShare))
+	if holdout < minBacktestHoldout {
+		return nil
+	}
+	train, actual := values[:len(values)-holdout], values[len(values)-holdout:]
+	projected, ok := forecastValues(model, train, period, holdout)
+	if !ok {
+		return nil
+	}
+
+	b := &Backtest{Model: model, Holdout: holdout}
+	var sumAbs, sumSq, sumPct float64
+	var covered, pctCount int
+	for i, a := range actual {
+		p := projected[i]
+		err := a - p.Value
+		sumAbs += math.Abs(err)
+		sumSq += err * err
+		if a != 0 {
+			sumPct += math.Abs(err / a)
+			pctCount++
+		}
+		if a >= p.Lower && a <= p.Upper {
+			covered++
+		}
+	}
+	n := float64(holdout)
+	b.MAE = sumAbs / n
+	b.RMSE = math.Sqrt(sumSq / n)
+	if pctCount > 0 {
+		b.MAPE = sumPct / float64(pctCount) * 100
+	}
+	b.Coverage = float64(covered) / n
+	return b
+}
+
+// linearForecast extends the least-squares line through values, with
+// intervals widening with the distance from the values' center.
+func linearForecast(values []float64, horizon int) ([]ForecastPoint, bool) {
+	n := float64(len(values))
+	if len(values) < minLinearPoints {
+		return nil, false
+	}
+
+	var sumX, sumY float64
+	for i, v := range values {
+		sumX += float64(i)
+		sumY += v
+	}
+	meanX, meanY := sumX/n, sumY/n
+	var sxx, sxy float64
+	for i, v := range values {
+		dx := float64(i) - meanX
+		sxx += dx * dx
+		sxy += dx * (v - meanY)
+	}
+	slope := sxy / sxx
+	intercept := meanY - slope*meanX
+
+	var sse float64
+	for i, v := range values {
+		r := v - (intercept + slope*float64(i))
+		sse += r * r
+	}
+	sigma := math.Sqrt(sse / (n - 2))
+
+	points := make([]ForecastPoint, horizon)
+	for h := range points {
+		x := n + float64(h)
+		value := intercept + slope*x
+		margin := forecastZ * sigma * math.Sqrt(1+1/n+(x-meanX)*(x-meanX)/sxx)
+		points[h] = ForecastPoint{Value: value, Lower: value - margin, Upper: value + margin}
+	}
+	return points, true
+}
+
+// holtWintersState is additive Holt-Winters smoothing fitted to a series.
+type holtWintersState struct {
+	alpha, beta, gamma, phi float64
+	level, trend            float64
+	season                  []float64 // Seasonal offsets, indexed by position in the period
+	next                    int       // Position in the period of the next value
+	sigma                   float64   // Standard deviation of one-step errors
+}
+
+// Smoothing parameters searched when fitting Holt-Winters.
+var (
+	hwAlphas = []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9}
+	hwBetas  = []float64{0, 0.05, 0.1, 0.2, 0.3}
+	hwGammas = []float64{0, 0.1, 0.2, 0.3, 0.5}
+	hwPhis   = []float64{0.9, 0.95, 0.98, 1}
+)
+
+// holtWinters projects values horizon steps ahead by additive Holt-Winters
+// smoothing with a damped trend and a season of period values, or none if
+// period is 0. The parameters are those with the smallest one-step errors.
+func holtWinters(values []float64, period, horizon int) ([]ForecastPoint, bool) {
+	if (period > 0 && len(values) < 2*period) || len(values) < minLinearPoints {
+		return nil, false
+	}
+	gammas := hwGammas
+	if period == 0 {
+		gammas = []float64{0}
+	}
+
+	var best *holtWintersState
+	bestSSE := math.Inf(1)
+	for _, alpha := range hwAlphas {
+		for _, beta := range hwBetas {
+			for _, gamma := range gammas {
+				for _, phi := range hwPhis {
+					state, sse := fitHoltWinters(values, period, alpha, beta, gamma, phi)
+					if sse < bestSSE {
+						best, bestSSE = state, sse
+					}
+				}
+			}
+		}
+	}
+	if best == nil {
+		return nil, false
+	}
+
+	points := make([]ForecastPoint, horizon)
+	var damped, variance float64 // Sum of phi^1..phi^h, and the h-step error variance factor
+	for h := range points {
+		// The error variance grows with each step by the squared effect
+		// of one error on the level, trend and season.
+		if h > 0 {
+			c := best.alpha * (1 + best.beta*damped)
+			if period > 0 && h%period == 0 {
+				c += best.gamma
+			}
+			variance += c * c
+		}
+		damped += math.Pow(best.phi, float64(h+1))
+
+		value := best.level + damped*best.trend
+		if period > 0 {
+			value += best.season[(best.next+h)%period]
+		}
+		margin := forecastZ * best.sigma * math.Sqrt(1+variance)
+		points[h] = ForecastPoint{Value: value, Lower: value - margin, Upper: value + margin}
+	}
+	return points, true
+}
+
+// fitHoltWinters smooths values with the given parameters and returns the
+// final state and its sum of squared one-step errors, not counting the
+// first period, which initializes the state.
+func fitHoltWinters(values []float64, period int, alpha, beta, gamma, phi float64) (*holtWintersState, float64) {
+	s := &holtWintersState{alpha: alpha, beta: beta, gamma: gamma, phi: phi}
+	start := 1
+	if period > 0 {
+		// Start from the first period's mean, the change in mean to the
+		// second period, and the first period's offsets from its mean.
+		first, second := mean(values[:period]), mean(values[period:2*period])
+		s.level = first
+		s.trend = (second - first) / float64(period)
+		s.season = make([]float64, period)
+		for i := range s.season {
+			s.season[i] = values[i] - first
+		}
+		start = period
+		// Move the level from the middle of the first period to its end.
+		s.level += s.trend * float64(period-1) / 2
+	} else {
+		s.level = values[0]
+		s.trend = values[1] - values[0]
+	}
+
+	var sse float64
+	for i := start; i < len(values); i++ {
+		seasonal := 0.0
+		if period > 0 {
+			seasonal = s.season[i%period]
+		}
+		predicted := s.level + phi*s.trend + seasonal
+		err := values[i] - predicted
+		sse += err * err
+
+		level := alpha*(values[i]-seasonal) + (1-alpha)*(s.level+phi*s.trend)
+		s.trend = beta*(level-s.level) + (1-beta)*phi*s.trend
+		s.level = level
+		if period > 0 {
+			s.season[i%period] = gamma*(values[i]-level) + (1-gamma)*seasonal
+		}
+	}
+	if period > 0 {
+		s.next = len(values) % period
+	}
+	if steps := len(values) - start; steps > 0 {
+		s.sigma = math.Sqrt(sse / float64(steps))
+	}
+	return s, sse
+}
+
+// mean returns the mean of values.
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
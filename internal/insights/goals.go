@@ -0,0 +1,323 @@
+// Package insights provides progress tracking for metric goals, judged
+// against the metric's forecast.
+package insights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// goalRateWindow is how many recent days a goal's actual daily rate, and
+// a rate metric's current value, are measured over.
+const goalRateWindow = 28
+
+// ErrInvalidGoal is returned when creating a goal that can't be tracked.
+var ErrInvalidGoal = errors.New("invalid goal")
+
+// GoalStatus is how a goal is tracking towards its target.
+type GoalStatus string
+
+const (
+	GoalAchieved GoalStatus = "achieved" // Target reached
+	GoalOnTrack  GoalStatus = "on_track" // Projected to reach the target by the deadline
+	GoalAtRisk   GoalStatus = "at_risk"  // Projected to fall short
+	GoalMissed   GoalStatus = "missed"   // Deadline passed without reaching the target
+	GoalNoData   GoalStatus = "no_data"  // The metric has no data yet
+)
+
+// Label returns the status's display name.
+func (s GoalStatus) Label() string {
+	switch s {
+	case GoalAchieved:
+		return "Achieved"
+	case GoalOnTrack:
+		return "On track"
+	case GoalAtRisk:
+		return "At risk"
+	case GoalMissed:
+		return "Missed"
+	default:
+		return "No data"
+	}
+}
+
+// GoalProgress is a goal's progress and how it's tracking.
+type GoalProgress struct {
+	Goal *data.Goal `json:"goal"`
+
+	// Current is the latest value of a stock, the total of a flow since
+	// the goal's start, or the average of a rate over the last
+	// goalRateWindow days, up to the deadline.
+	Current   float64 `json:"current"`
+	Remaining float64 `json:"remaining"`
+	Progress  float64 `json:"progress"`  // Share of the way from the baseline to the target
+	DaysLeft  int     `json:"days_left"` // Days from today through the deadline
+
+	// Daily change of a stock, or daily amount of a flow, needed from
+	// today to reach the target, and over the last goalRateWindow days.
+	// Both are zero for rate metrics, and the required rate is zero once
+	// the goal is achieved or missed.
+	RequiredRate float64 `json:"required_rate"`
+	ActualRate   float64 `json:"actual_rate"`
+
+	// Projected value at the deadline and its prediction interval, from
+	// the forecast or, beyond its reach, the actual rate.
+	Projected      float64 `json:"projected"`
+	ProjectedLower float64 `json:"projected_lower"`
+	ProjectedUpper float64 `json:"projected_upper"`
+
+	Status GoalStatus `json:"status"`
+	Basis  string     `json:"basis,omitempty"` // "forecast" or "rate", for goals still in progress
+}
+
+// Label names the goal's platform and metric, e.g. "YouTube subscribers".
+func (p *GoalProgress) Label() string {
+	return platformNames[p.Goal.Platform] + " " + strings.ReplaceAll(p.Goal.Metric, "_", " ")
+}
+
+// HasRates reports whether the goal has daily rates, which rate metrics
+// don't.
+func (p *GoalProgress) HasRates() bool {
+	return AggregationFor(p.Goal.Metric) != AggregateMean
+}
+
+// ProgressPercent returns Progress as a percentage between 0 and 100.
+func (p *GoalProgress) ProgressPercent() int {
+	return int(math.Round(math.Min(math.Max(p.Progress, 0), 1) * 100))
+}
+
+// FormatValue formats one of the goal's values for display.
+func (p *GoalProgress) FormatValue(value float64) string {
+	if p.Goal.Metric == "engagement_rate" {
+		return fmt.Sprintf("%.2f%%", value)
+	}
+	return formatNumber(math.Round(value))
+}
+
+// CreateGoal validates a goal, records the metric's value at its start as
+// the baseline and saves it. The start defaults to today. Validation
+// errors wrap ErrInvalidGoal.
+func (t *TrendAnalyzer) CreateGoal(ctx context.Context, goal *data.Goal) error {
+	known := false
+	for _, metric := range PlatformMetrics[goal.Platform] {
+		known = known || metric == goal.Metric
+	}
+	if !known {
+		return fmt.Errorf("%w: %s has no metric %q", ErrInvalidGoal, goal.Platform, goal.Metric)
+	}
+	if goal.Target <= 0 || math.IsInf(goal.Target, 0) || math.IsNaN(goal.Target) {
+		return fmt.Errorf("%w: target must be positive", ErrInvalidGoal)
+	}
+	today := startOfDay(time.Now())
+	if goal.Start.IsZero() {
+		goal.Start = today
+	}
+	goal.Start = startOfDay(goal.Start)
+	goal.Deadline = startOfDay(goal.Deadline)
+	if goal.Deadline.Before(goal.Start) {
+		return fmt.Errorf("%w: deadline is before the start", ErrInvalidGoal)
+	}
+
+	switch AggregationFor(goal.Metric) {
+	case AggregateLast:
+		points, err := t.store.GetMetricSeries(ctx, goal.Platform, goal.Metric, data.DateRange{End: goal.Start.AddDate(0, 0, 1)})
+		if err != nil {
+			return fmt.Errorf("getting %s series: %w", goal.Metric, err)
+		}
+		if len(points) > 0 {
+			goal.Baseline = points[len(points)-1].Value
+		}
+	case AggregateMean:
+		points, err := t.DailySeries(ctx, goal.Platform, goal.Metric, data.DateRange{Start: goal.Start.AddDate(0, 0, -goalRateWindow), End: goal.Start})
+		if err != nil {
+			return err
+		}
+		if len(points) > 0 {
+			goal.Baseline = mean(pointValues(points))
+		}
+	}
+
+	now := time.Now()
+	goal.CreatedAt, goal.UpdatedAt = now, now
+	if err := t.store.CreateGoal(ctx, goal); err != nil {
+		return fmt.Errorf("creating goal: %w", err)
+	}
+	return nil
+}
+
+// AllGoalProgress returns the progress of the goals for platform, or for
+// all platforms if it is empty, soonest deadline first.
+func (t *TrendAnalyzer) AllGoalProgress(ctx context.Context, platform data.Platform) ([]*GoalProgress, error) {
+	goals, err := t.store.ListGoals(ctx, platform)
+	if err != nil {
+		return nil, fmt.Errorf("listing goals: %w", err)
+	}
+	progress := make([]*GoalProgress, 0, len(goals))
+	for _, goal := range goals {
+		p, err := t.GoalProgress(ctx, goal)
+		if err != nil {
+			return nil, err
+		}
+		progress = append(progress, p)
+	}
+	return progress, nil
+}
+
+// GoalProgress measures a goal's progress up to today, or its deadline if
+// that has passed, and judges whether it will reach its target from the
+// metric's forecast.
+func (t *TrendAnalyzer) GoalProgress(ctx context.Context, goal *data.Goal) (*GoalProgress, error) {
+	aggregation := AggregationFor(goal.Metric)
+	today := startOfDay(time.Now())
+	deadline := startOfDay(goal.Deadline.In(today.Location()))
+	start := startOfDay(goal.Start.In(today.Location()))
+	end := today // Complete days counted so far
+	if deadline.Before(today) {
+		end = deadline.AddDate(0, 0, 1)
+	}
+
+	p := &GoalProgress{Goal: goal}
+	from := today
+	if aggregation == AggregateSum && start.After(today) {
+		from = start
+	}
+	if !deadline.Before(from) {
+		p.DaysLeft = daysBetween(from, deadline) + 1
+	}
+
+	recent, err := t.DailySeries(ctx, goal.Platform, goal.Metric, data.DateRange{Start: end.AddDate(0, 0, -goalRateWindow), End: end})
+	if err != nil {
+		return nil, err
+	}
+	switch aggregation {
+	case AggregateLast:
+		if len(recent) == 0 {
+			p.Status = GoalNoData
+			return p, nil
+		}
+		first, last := recent[0], recent[len(recent)-1]
+		p.Current = last.Value
+		if days := daysBetween(first.Timestamp, last.Timestamp); days > 0 {
+			p.ActualRate = (last.Value - first.Value) / float64(days)
+		}
+	case AggregateMean:
+		if len(recent) == 0 {
+			p.Status = GoalNoData
+			return p, nil
+		}
+		p.Current = mean(pointValues(recent))
+	default:
+		metricStart, err := t.store.GetMetricStart(ctx, goal.Platform, goal.Metric)
+		if err != nil {
+			return nil, fmt.Errorf("getting %s start: %w", goal.Metric, err)
+		}
+		if metricStart.IsZero() {
+			p.Status = GoalNoData
+			return p, nil
+		}
+		if end.After(start) {
+			total, err := t.DailySeries(ctx, goal.Platform, goal.Metric, data.DateRange{Start: start, End: end})
+			if err != nil {
+				return nil, err
+			}
+			for _, point := range total {
+				p.Current += point.Value
+			}
+		}
+		if len(recent) > 0 {
+			p.ActualRate = mean(pointValues(recent))
+		}
+	}
+
+	increasing := aggregation == AggregateSum || goal.Target >= goal.Baseline
+	reached := func(v float64) bool {
+		if increasing {
+			return v >= goal.Target
+		}
+		return v <= goal.Target
+	}
+	p.Remaining = goal.Target - p.Current
+	if span := goal.Target - goal.Baseline; span != 0 {
+		p.Progress = (p.Current - goal.Baseline) / span
+	} else if reached(p.Current) {
+		p.Progress = 1
+	}
+	p.Projected, p.ProjectedLower, p.ProjectedUpper = p.Current, p.Current, p.Current
+
+	// A rate could still fall back below its target before the deadline.
+	if reached(p.Current) && (aggregation != AggregateMean || p.DaysLeft == 0) {
+		p.Status = GoalAchieved
+		return p, nil
+	}
+	if p.DaysLeft == 0 {
+		p.Status = GoalMissed
+		return p, nil
+	}
+	if aggregation != AggregateMean {
+		p.RequiredRate = p.Remaining / float64(p.DaysLeft)
+	}
+
+	if err := t.projectGoal(ctx, p, today, start, deadline); err != nil {
+		return nil, err
+	}
+	p.Status = GoalAtRisk
+	if reached(p.Projected) {
+		p.Status = GoalOnTrack
+	}
+	return p, nil
+}
+
+// projectGoal projects a goal's value at its deadline from the metric's
+// forecast, or from its actual rate when the deadline is beyond the
+// forecast's reach or there's too little data to forecast. Flow forecasts
+// are added to the current total, with the daily bounds summed, which
+// widens the interval as if each day's error were in the same direction.
+func (t *TrendAnalyzer) projectGoal(ctx context.Context, p *GoalProgress, today, start, deadline time.Time) error {
+	aggregation := AggregationFor(p.Goal.Metric)
+	horizon := daysBetween(today, deadline) + 1
+	if horizon <= MaxForecastDays {
+		forecast, err := t.Forecast(ctx, p.Goal.Platform, p.Goal.Metric, horizon, ForecastAuto)
+		if err != nil && !errors.Is(err, ErrNotEnoughData) {
+			return err
+		}
+		if forecast != nil {
+			p.Basis = "forecast"
+			if aggregation != AggregateSum {
+				if at := forecast.At(deadline); at != nil {
+					p.Projected, p.ProjectedLower, p.ProjectedUpper = at.Value, at.Lower, at.Upper
+					return nil
+				}
+			} else {
+				for _, point := range forecast.Points {
+					day := startOfDay(point.Timestamp.In(today.Location()))
+					if day.Before(start) || day.After(deadline) {
+						continue
+					}
+					p.Projected += point.Value
+					p.ProjectedLower += point.Lower
+					p.ProjectedUpper += point.Upper
+				}
+				return nil
+			}
+		}
+	}
+
+	p.Basis = "rate"
+	if aggregation != AggregateMean {
+		p.Projected = p.Current + p.ActualRate*float64(p.DaysLeft)
+		p.ProjectedLower, p.ProjectedUpper = p.Projected, p.Projected
+	}
+	return nil
+}
+
+// daysBetween returns the number of calendar days from one midnight to
+// another, allowing for daylight saving changes.
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
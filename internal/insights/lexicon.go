@@ -0,0 +1,264 @@
+// Package insights provides offline comment classification with word
+// lists, used when no LLM is available.
+package insights
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// sentimentThreshold is the lexicon score beyond which a comment counts as
+// positive or negative rather than neutral.
+const sentimentThreshold = 0.2
+
+// sentimentWords weights English words by the sentiment they express.
+var sentimentWords = map[string]float64{
+	// Positive
+	"amazing": 2, "awesome": 2, "beautiful": 1.5, "best": 2, "brilliant": 2,
+	"clear": 1, "cool": 1, "enjoyed": 1.5, "excellent": 2, "fantastic": 2,
+	"fun": 1, "glad": 1, "good": 1, "great": 1.5, "helpful": 1.5,
+	"impressive": 1.5, "incredible": 2, "informative": 1, "insightful": 1.5,
+	"interesting": 1, "love": 2, "loved": 2, "loving": 2, "nice": 1,
+	"perfect": 2, "recommend": 1, "thank": 1.5, "thanks": 1.5, "useful": 1.5,
+	"valuable": 1.5, "well": 0.5, "wonderful": 2, "wow": 1.5,
+	// Negative
+	"annoying": -1.5, "awful": -2, "bad": -1.5, "boring": -1.5, "broken": -1.5,
+	"clickbait": -2, "confusing": -1.5, "disappointed": -2, "disappointing": -2,
+	"dislike": -1.5, "hate": -2, "horrible": -2, "misleading": -2,
+	"pointless": -1.5, "poor": -1.5, "quiet": -0.5, "sad": -1, "scam": -2,
+	"slow": -1, "stupid": -2, "terrible": -2, "ugly": -1.5, "unsubscribe": -2,
+	"unsubscribed": -2, "useless": -2, "waste": -2, "worse": -1.5,
+	"worst": -2, "wrong": -1.5,
+}
+
+// sentimentEmoji weights emoji by sentiment.
+var sentimentEmoji = map[rune]float64{
+	'😀': 1.5, '😃': 1.5, '😄': 1.5, '😁': 1.5, '😊': 1.5, '😍': 2, '🥰': 2,
+	'❤': 2, '👍': 1.5, '👏': 1.5, '🔥': 1.5, '🙌': 1.5, '💯': 1.5,
+	'😡': -2, '😠': -2, '👎': -2, '😢': -1, '😞': -1.5, '🤮': -2, '💩': -2,
+}
+
+// negators flip the sentiment of the words following them.
+var negators = map[string]bool{
+	"not": true, "no": true, "never": true, "don't": true, "doesn't": true,
+	"didn't": true, "isn't": true, "wasn't": true, "aren't": true,
+	"can't": true, "cannot": true, "won't": true, "hardly": true,
+}
+
+// negationWindow is how many words after a negator are flipped.
+const negationWindow = 3
+
+// Intent phrases, matched against lower-cased comment text.
+var (
+	spamPhrases = []string{
+		"check out my", "check my channel", "subscribe to my", "sub to my",
+		"visit my", "dm me", "message me on", "whatsapp", "telegram",
+		"crypto", "bitcoin", "forex", "giveaway", "earn $", "make money",
+		"work from home", "free followers",
+	}
+	requestPhrases = []string{
+		"please make", "please do", "please cover", "can you make", "can you do",
+		"could you make", "could you do", "could you cover", "would love to see",
+		"would like to see", "make a video", "do a video", "next video",
+		"part 2", "part two", "you should do", "you should make", "tutorial on",
+		"video on", "video about", "more videos", "more content", "please",
+	}
+	complaintPhrases = []string{
+		"doesn't work", "didn't work", "not working", "can't hear", "too loud",
+		"too long", "too fast", "too many ads", "bug", "error", "refund",
+	}
+	questionWords = map[string]bool{
+		"how": true, "what": true, "why": true, "when": true, "where": true,
+		"who": true, "which": true, "is": true, "are": true, "can": true,
+		"could": true, "do": true, "does": true, "did": true, "will": true,
+		"would": true, "should": true, "anyone": true,
+	}
+)
+
+// linkPattern matches URLs in comments.
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://|\bwww\.\S+\.\S+`)
+
+// languageStopWords are common words identifying Latin-script languages.
+var languageStopWords = map[string][]string{
+	"en": {"the", "and", "is", "you", "this", "that", "it", "of", "to", "for", "was", "with", "your", "are", "have", "i", "my", "on", "can", "how", "what", "so"},
+	"es": {"el", "la", "que", "de", "y", "es", "muy", "por", "para", "con", "los", "las", "una", "gracias", "pero"},
+	"fr": {"le", "la", "les", "et", "est", "je", "que", "de", "des", "pour", "une", "merci", "vous", "très", "avec"},
+	"de": {"der", "die", "das", "und", "ist", "ich", "nicht", "ein", "eine", "danke", "sehr", "mit", "für", "auch", "du"},
+	"pt": {"o", "a", "que", "de", "e", "é", "muito", "obrigado", "obrigada", "para", "com", "uma", "não", "você", "os"},
+	"it": {"il", "la", "che", "di", "e", "è", "molto", "grazie", "per", "con", "una", "non", "sono", "questo", "anche"},
+	"nl": {"de", "het", "een", "en", "is", "niet", "ik", "dat", "van", "voor", "met", "dank", "heel", "ook", "je"},
+}
+
+// lexiconLabel classifies a comment without an LLM. Sentiment and intent
+// use English word lists, so comments in other languages are mostly
+// labeled neutral.
+func lexiconLabel(comment *data.Comment) *data.CommentLabel {
+	text := strings.ToLower(comment.Text)
+	words := lexiconWords(text)
+	score := lexiconScore(text, words)
+
+	sentiment := data.SentimentNeutral
+	switch {
+	case score >= sentimentThreshold:
+		sentiment = data.SentimentPositive
+	case score <= -sentimentThreshold:
+		sentiment = data.SentimentNegative
+	}
+
+	return &data.CommentLabel{
+		CommentID: comment.ID,
+		Sentiment: sentiment,
+		Score:     score,
+		Intent:    lexiconIntent(text, words, score),
+		Language:  detectLanguage(comment.Text, words),
+		Source:    data.LabelSourceLexicon,
+	}
+}
+
+// lexiconWords splits lower-cased text into words, keeping apostrophes.
+func lexiconWords(text string) []string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’'
+	})
+	for i, w := range words {
+		words[i] = strings.Trim(strings.ReplaceAll(w, "’", "'"), "'")
+	}
+	return words
+}
+
+// lexiconScore sums the sentiment of words and emoji, flipping words
+// after a negator, and normalizes the sum to the range -1 to 1.
+func lexiconScore(text string, words []string) float64 {
+	var sum float64
+	negated := 0
+	for _, w := range words {
+		if negators[w] {
+			negated = negationWindow
+			continue
+		}
+		if weight, ok := sentimentWords[w]; ok {
+			if negated > 0 {
+				weight = -weight / 2 // "not bad" is milder than "good"
+			}
+			sum += weight
+		}
+		if negated > 0 {
+			negated--
+		}
+	}
+	for _, r := range text {
+		sum += sentimentEmoji[r]
+	}
+	if strings.Contains(text, "!") {
+		sum *= 1.2
+	}
+	// Normalized as in VADER, approaching ±1 as the sum grows.
+	return sum / math.Sqrt(sum*sum+15)
+}
+
+// lexiconIntent picks the intent of a comment from its phrasing and
+// sentiment score.
+func lexiconIntent(text string, words []string, score float64) string {
+	switch {
+	case containsAny(text, spamPhrases) || (linkPattern.MatchString(text) && len(words) < 12):
+		return data.IntentSpam
+	case containsAny(text, requestPhrases):
+		return data.IntentRequest
+	case strings.Contains(text, "?") || (len(words) > 0 && questionWords[words[0]] && len(words) <= 15):
+		return data.IntentQuestion
+	case containsAny(text, complaintPhrases) || score <= -sentimentThreshold:
+		return data.IntentComplaint
+	case score >= sentimentThreshold:
+		return data.IntentPraise
+	}
+	return data.IntentOther
+}
+
+func containsAny(text string, phrases []string) bool {
+	for _, p := range phrases {
+		if strings.Contains(text, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectLanguage identifies the language of text from its script or, for
+// Latin script, its most common words. It returns data.LanguageUnknown
+// when there's too little to go on.
+func detectLanguage(text string, words []string) string {
+	scripts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			scripts["ja"]++
+		case unicode.Is(unicode.Han, r):
+			scripts["zh"]++
+		case unicode.Is(unicode.Hangul, r):
+			scripts["ko"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			scripts["ru"]++
+		case unicode.Is(unicode.Arabic, r):
+			scripts["ar"]++
+		case unicode.Is(unicode.Devanagari, r):
+			scripts["hi"]++
+		case unicode.Is(unicode.Greek, r):
+			scripts["el"]++
+		case unicode.Is(unicode.Hebrew, r):
+			scripts["he"]++
+		case unicode.Is(unicode.Thai, r):
+			scripts["th"]++
+		}
+	}
+	if letters == 0 {
+		return data.LanguageUnknown
+	}
+	// Japanese mixes kana with Han characters.
+	if scripts["ja"] > 0 {
+		return "ja"
+	}
+	best, bestCount := "", 0
+	for lang, count := range scripts {
+		if count > bestCount {
+			best, bestCount = lang, count
+		}
+	}
+	if bestCount*2 > letters {
+		return best
+	}
+
+	counts := make(map[string]int)
+	for _, w := range words {
+		for lang, stops := range languageStopWords {
+			for _, s := range stops {
+				if w == s {
+					counts[lang]++
+					break
+				}
+			}
+		}
+	}
+	best, bestCount = data.LanguageUnknown, 0
+	tied := false
+	for lang, count := range counts {
+		switch {
+		case count > bestCount:
+			best, bestCount, tied = lang, count, false
+		case count == bestCount:
+			tied = true
+		}
+	}
+	if bestCount == 0 || tied {
+		return data.LanguageUnknown
+	}
+	return best
+}
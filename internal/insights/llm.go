@@ -5,204 +5,276 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/omnipulse/omnipulse/internal/config"
 	"github.com/omnipulse/omnipulse/internal/data"
 )
 
-// LLMClient handles communication with the LLM (Ollama) for generating insights.
-type LLMClient struct {
-	httpClient *http.Client
-	endpoint   string
-	model      string
-}
+// LLM generates text from a prompt. Implementations exist for Ollama,
+// OpenAI-compatible chat completion servers and a deterministic fake.
+type LLM interface {
+	// Generate returns the completion of req.Prompt. Errors wrap ErrTimeout
+	// or ErrModelNotFound where they apply.
+	Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error)
 
-// NewLLMClient creates a new LLM client.
-func NewLLMClient(cfg config.LLMConfig) *LLMClient {
-	return &LLMClient{
-		httpClient: &http.Client{
-			Timeout: cfg.Timeout,
-		},
-		endpoint: cfg.Endpoint,
-		model:    cfg.Model,
-	}
-}
+	// Stream is like Generate but calls onToken with each chunk of text as
+	// the model produces it. The returned response holds the full text. An
+	// error from onToken stops generation and is returned.
+	Stream(ctx context.Context, req *GenerateRequest, onToken StreamFunc) (*GenerateResponse, error)
 
-// OllamaRequest represents a request to the Ollama API.
-type OllamaRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
+	// Backend returns the backend name, as used in LLMConfig.
+	Backend() string
 }
 
-// OllamaResponse represents a response from the Ollama API.
-type OllamaResponse struct {
-	Model     string    `json:"model"`
-	CreatedAt time.Time `json:"created_at"`
-	Response  string    `json:"response"`
-	Done      bool      `json:"done"`
+// GenerateRequest is a single prompt for an LLM.
+type GenerateRequest struct {
+	Prompt string
+	// System overrides the configured system prompt when set.
+	System string
+	// Temperature overrides the configured temperature when non-nil.
+	Temperature *float64
+	// MaxTokens overrides the configured completion limit when positive.
+	MaxTokens int
+	// Schema, when set, is a JSON schema the response must match. Backends
+	// that support it constrain generation to valid JSON.
+	Schema json.RawMessage
 }
 
-// GenerateInsight generates an insight using the LLM.
-func (c *LLMClient) GenerateInsight(ctx context.Context, prompt string) (string, error) {
-	req := OllamaRequest{
-		Model:  c.model,
-		Prompt: prompt,
-		Stream: false,
-	}
-
-	body, err := json.Marshal(req)
-	if err != nil {
-		return "", fmt.Errorf("marshaling request: %w", err)
-	}
+// StreamFunc receives the chunks of a streamed completion.
+type StreamFunc func(token string) error
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST",
-		c.endpoint+"/api/generate", bytes.NewReader(body))
-	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
+// GenerateResponse is an LLM completion.
+type GenerateResponse struct {
+	Text             string
+	Model            string
+	PromptTokens     int // Zero if the backend doesn't report usage
+	CompletionTokens int
+	Duration         time.Duration
+}
 
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return "", fmt.Errorf("making request: %w", err)
-	}
-	defer resp.Body.Close()
+// LLM error kinds. Backend errors wrap one of these, where they apply, in
+// an *LLMError.
+var (
+	ErrTimeout       = errors.New("LLM request timed out")
+	ErrModelNotFound = errors.New("LLM model not found")
+)
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+// LLMError is an error from an LLM backend.
+type LLMError struct {
+	Backend    string
+	Model      string
+	StatusCode int   // Zero for transport errors
+	Kind       error // ErrTimeout, ErrModelNotFound or nil
+	Message    string
+}
 
-	var ollamaResp OllamaResponse
-	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
-		return "", fmt.Errorf("decoding response: %w", err)
+func (e *LLMError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s model %s: %s (status %d)", e.Backend, e.Model, e.Message, e.StatusCode)
 	}
+	return fmt.Sprintf("%s model %s: %s", e.Backend, e.Model, e.Message)
+}
 
-	return ollamaResp.Response, nil
+// Unwrap returns the error kind, so errors.Is matches ErrTimeout and
+// ErrModelNotFound.
+func (e *LLMError) Unwrap() error {
+	return e.Kind
 }
 
-// GenerateAnalyticsInsight generates an insight based on analytics data.
-func (c *LLMClient) GenerateAnalyticsInsight(ctx context.Context, summary *data.AnalyticsSummary) (*data.Insight, error) {
-	prompt := buildAnalyticsPrompt(summary)
+// HTTPStatus returns the backend's HTTP status code, if any.
+func (e *LLMError) HTTPStatus() int {
+	return e.StatusCode
+}
 
-	response, err := c.GenerateInsight(ctx, prompt)
-	if err != nil {
-		return nil, fmt.Errorf("generating insight: %w", err)
+// Temporary reports whether retrying the request may succeed.
+func (e *LLMError) Temporary() bool {
+	switch {
+	case e.Kind == ErrTimeout:
+		return true
+	case e.Kind == ErrModelNotFound:
+		return false
+	case e.StatusCode == 0:
+		return true // Connection refused while the server starts, etc.
 	}
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
 
-	insight := &data.Insight{
-		ID:          generateID(),
-		Platform:    "", // Cross-platform
-		Type:        "summary",
-		Title:       "Analytics Overview",
-		Description: response,
-		Confidence:  0.8,
-		GeneratedAt: time.Now(),
-		DataRange:   "custom",
+// NewLLM creates the LLM backend selected by cfg.Backend.
+func NewLLM(cfg config.LLMConfig) (LLM, error) {
+	switch cfg.Backend {
+	case "", "ollama":
+		return NewOllama(cfg), nil
+	case "openai":
+		return NewOpenAI(cfg), nil
+	case "fake":
+		return NewFakeLLM(), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM backend %q", cfg.Backend)
 	}
-
-	return insight, nil
 }
 
-// GenerateContentSuggestions generates content ideas based on analytics trends.
-func (c *LLMClient) GenerateContentSuggestions(ctx context.Context, platform data.Platform, trends []*data.TrendData) ([]string, error) {
-	prompt := buildContentSuggestionPrompt(platform, trends)
+// generationSettings are the per-backend defaults that requests override.
+type generationSettings struct {
+	system      string
+	temperature *float64
+	maxTokens   int
+}
 
-	response, err := c.GenerateInsight(ctx, prompt)
-	if err != nil {
-		return nil, fmt.Errorf("generating suggestions: %w", err)
+func newGenerationSettings(cfg config.LLMConfig) generationSettings {
+	settings := generationSettings{
+		system:    cfg.SystemPrompt,
+		maxTokens: cfg.MaxTokens,
 	}
-
-	// TODO: Parse response into individual suggestions
-	return []string{response}, nil
+	if cfg.Temperature >= 0 {
+		temperature := cfg.Temperature
+		settings.temperature = &temperature
+	}
+	return settings
 }
 
-// buildAnalyticsPrompt creates a prompt for analytics insight generation.
-func buildAnalyticsPrompt(summary *data.AnalyticsSummary) string {
-	// TODO: Build a detailed prompt including:
-	// - Key metrics from each platform
-	// - Comparison with previous periods
-	// - Request for actionable insights
-	return fmt.Sprintf(`Analyze the following social media analytics and provide insights:
-
-YouTube:
-- Total views: %d
-- Engagement rate: %.2f%%
-
-X (Twitter):
-- Total impressions: %d
-- Engagement rate: %.2f%%
+// apply returns the settings for req.
+func (s generationSettings) apply(req *GenerateRequest) generationSettings {
+	if req.System != "" {
+		s.system = req.System
+	}
+	if req.Temperature != nil {
+		s.temperature = req.Temperature
+	}
+	if req.MaxTokens > 0 {
+		s.maxTokens = req.MaxTokens
+	}
+	return s
+}
 
-LinkedIn:
-- Total impressions: %d
-- Engagement rate: %.2f%%
+// transportError converts an error from sending a request into an
+// *LLMError, classifying timeouts. Cancellation by the caller is returned
+// unchanged.
+func transportError(ctx context.Context, backend, model string, err error) error {
+	idle := errors.Is(context.Cause(ctx), context.DeadlineExceeded) // See withIdleTimeout
+	if errors.Is(ctx.Err(), context.Canceled) && !idle {
+		return ctx.Err()
+	}
+	llmErr := &LLMError{Backend: backend, Model: model, Message: err.Error()}
+	var netErr net.Error
+	if idle || errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		llmErr.Kind = ErrTimeout
+		llmErr.Message = "request timed out"
+	}
+	return llmErr
+}
 
-Please provide:
-1. Key observations
-2. Areas of strength
-3. Areas needing improvement
-4. Recommended actions
+// withIdleTimeout returns a context that is canceled when timeout passes
+// without a call to touch, for streams that may legitimately run for a
+// long time as long as they keep producing output. Its cause is then
+// context.DeadlineExceeded, which transportError reports as ErrTimeout.
+// A timeout of zero or less disables it. Call stop to release the timer.
+func withIdleTimeout(ctx context.Context, timeout time.Duration) (idleCtx context.Context, touch func(), stop func()) {
+	if timeout <= 0 {
+		return ctx, func() {}, func() {}
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(timeout, func() { cancel(context.DeadlineExceeded) })
+	return ctx, func() { timer.Reset(timeout) }, func() {
+		timer.Stop()
+		cancel(nil)
+	}
+}
 
-Keep the response concise and actionable.`,
-		getYouTubeViews(summary),
-		getYouTubeEngagement(summary),
-		getXImpressions(summary),
-		getXEngagement(summary),
-		getLinkedInImpressions(summary),
-		getLinkedInEngagement(summary),
-	)
+// LLMClient generates insights using an LLM backend.
+type LLMClient struct {
+	llm LLM
 }
 
-// buildContentSuggestionPrompt creates a prompt for content suggestions.
-func buildContentSuggestionPrompt(platform data.Platform, trends []*data.TrendData) string {
-	// TODO: Build prompt based on trending topics and performance data
-	return fmt.Sprintf(`Based on recent performance trends on %s, suggest 3 content ideas that could improve engagement.`, platform)
+// NewLLMClient creates a new LLM client.
+func NewLLMClient(llm LLM) *LLMClient {
+	return &LLMClient{llm: llm}
 }
 
-// Helper functions for prompt building
-func getYouTubeViews(s *data.AnalyticsSummary) int64 {
-	if s != nil && s.YouTube != nil {
-		return s.YouTube.TotalViews
+// GenerateInsight generates an insight using the LLM.
+func (c *LLMClient) GenerateInsight(ctx context.Context, prompt string) (string, error) {
+	resp, err := c.llm.Generate(ctx, &GenerateRequest{Prompt: prompt})
+	if err != nil {
+		return "", err
 	}
-	return 0
+	return resp.Text, nil
 }
 
-func getYouTubeEngagement(s *data.AnalyticsSummary) float64 {
-	if s != nil && s.YouTube != nil {
-		return s.YouTube.EngagementRate * 100
-	}
-	return 0
+// GenerateAnalyticsInsights generates typed insights from an analytics
+// prompt built by PromptBuilder. The LLM is asked for JSON matching
+// insightSchema; invalid responses are repaired where possible and
+// otherwise retried.
+func (c *LLMClient) GenerateAnalyticsInsights(ctx context.Context, prompt string) ([]*GeneratedInsight, error) {
+	return c.analyticsInsights(ctx, prompt, nil, nil)
 }
 
-func getXImpressions(s *data.AnalyticsSummary) int64 {
-	if s != nil && s.X != nil {
-		return s.X.TotalImpressions
-	}
-	return 0
+// StreamAnalyticsInsights is like GenerateAnalyticsInsights but passes the
+// raw response to onToken as it is generated. onRetry is called before an
+// unusable response is retried, after its tokens have been sent.
+func (c *LLMClient) StreamAnalyticsInsights(ctx context.Context, prompt string, onToken StreamFunc, onRetry RetryFunc) ([]*GeneratedInsight, error) {
+	return c.analyticsInsights(ctx, prompt, onToken, onRetry)
 }
 
-func getXEngagement(s *data.AnalyticsSummary) float64 {
-	if s != nil && s.X != nil {
-		return s.X.EngagementRate * 100
+func (c *LLMClient) analyticsInsights(ctx context.Context, prompt string, onToken StreamFunc, onRetry RetryFunc) ([]*GeneratedInsight, error) {
+	var items []insightItem
+	err := c.generateJSON(ctx, prompt, insightSchema, onToken, onRetry, func(text string) error {
+		var err error
+		items, err = decodeInsights(text)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("generating insights: %w", err)
 	}
-	return 0
-}
 
-func getLinkedInImpressions(s *data.AnalyticsSummary) int64 {
-	if s != nil && s.LinkedIn != nil {
-		return s.LinkedIn.TotalImpressions
+	id := generateID()
+	now := time.Now()
+	generated := make([]*GeneratedInsight, 0, len(items))
+	for i, item := range items {
+		insight := &data.Insight{
+			ID:          fmt.Sprintf("%s_%d", id, i+1),
+			Platform:    data.Platform(item.Platform),
+			Type:        item.Type,
+			Title:       item.Title,
+			Description: item.Description,
+			Confidence:  item.Confidence,
+			GeneratedAt: now,
+			DataRange:   "custom",
+		}
+		generated = append(generated, &GeneratedInsight{
+			Insight: insight,
+			Detail: &data.InsightDetail{
+				InsightID: insight.ID,
+				Evidence:  item.Evidence,
+				Actions:   item.Actions,
+			},
+		})
 	}
-	return 0
+	return generated, nil
 }
 
-func getLinkedInEngagement(s *data.AnalyticsSummary) float64 {
-	if s != nil && s.LinkedIn != nil {
-		return s.LinkedIn.EngagementRate * 100
+// GenerateContentSuggestions generates content ideas from a suggestions
+// prompt built by PromptBuilder.
+func (c *LLMClient) GenerateContentSuggestions(ctx context.Context, prompt string) ([]string, error) {
+	var suggestions []string
+	err := c.generateJSON(ctx, prompt, suggestionSchema, nil, nil, func(text string) error {
+		var err error
+		if suggestions, err = decodeSuggestions(text); err != nil {
+			// Fall back to a plain list if the model ignored the format.
+			if suggestions = splitList(text); len(suggestions) > 0 {
+				return nil
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("generating suggestions: %w", err)
 	}
-	return 0
+	return suggestions, nil
 }
 
 // generateID generates a unique ID for insights.
@@ -210,3 +282,35 @@ func generateID() string {
 	// TODO: Use a proper UUID library
 	return fmt.Sprintf("insight_%d", time.Now().UnixNano())
 }
+
+// postJSON sends body as JSON to url and returns the response, which the
+// caller must close.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body interface{}) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return client.Do(req)
+}
+
+// isModelNotFound reports whether an error response means the requested
+// model isn't available on the server. The status alone isn't enough: a
+// 404 also comes from a wrong base URL or a server without the endpoint,
+// so the message must be about the model.
+func isModelNotFound(status int, message string) bool {
+	message = strings.ToLower(message)
+	if !strings.Contains(message, "model") {
+		return false
+	}
+	return status == http.StatusNotFound ||
+		strings.Contains(message, "not found") || strings.Contains(message, "does not exist")
+}
@@ -0,0 +1,187 @@
+// Package insights provides the Ollama LLM backend.
+package insights
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/config"
+)
+
+// Ollama generates text with a local Ollama server's /api/generate.
+type Ollama struct {
+	httpClient     *http.Client
+	endpoint       string
+	model          string
+	embeddingModel string
+	timeout        time.Duration
+	settings       generationSettings
+}
+
+// NewOllama creates an Ollama backend.
+func NewOllama(cfg config.LLMConfig) *Ollama {
+	return &Ollama{
+		httpClient:     &http.Client{},
+		endpoint:       strings.TrimSuffix(cfg.Endpoint, "/"),
+		model:          cfg.Model,
+		embeddingModel: cfg.EmbeddingModel,
+		timeout:        cfg.Timeout,
+		settings:       newGenerationSettings(cfg),
+	}
+}
+
+// OllamaRequest represents a request to the Ollama API.
+type OllamaRequest struct {
+	Model   string          `json:"model"`
+	Prompt  string          `json:"prompt"`
+	System  string          `json:"system,omitempty"`
+	Format  json.RawMessage `json:"format,omitempty"` // "json" or a JSON schema
+	Stream  bool            `json:"stream"`
+	Options *OllamaOptions  `json:"options,omitempty"`
+}
+
+// OllamaOptions are the model parameters of an Ollama request.
+type OllamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"` // Maximum tokens to generate
+}
+
+// OllamaResponse represents a response from the Ollama API.
+type OllamaResponse struct {
+	Model           string    `json:"model"`
+	CreatedAt       time.Time `json:"created_at"`
+	Response        string    `json:"response"`
+	Done            bool      `json:"done"`
+	PromptEvalCount int       `json:"prompt_eval_count"`
+	EvalCount       int       `json:"eval_count"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// Backend returns "ollama".
+func (o *Ollama) Backend() string { return "ollama" }
+
+// Generate returns the completion of req.Prompt.
+func (o *Ollama) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	resp, err := postJSON(ctx, o.httpClient, o.endpoint+"/api/generate", nil, o.request(req, false))
+	if err != nil {
+		return nil, transportError(ctx, o.Backend(), o.model, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, o.statusError(resp)
+	}
+
+	var ollamaResp OllamaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
+		return nil, transportError(ctx, o.Backend(), o.model, fmt.Errorf("decoding response: %w", err))
+	}
+
+	return &GenerateResponse{
+		Text:             ollamaResp.Response,
+		Model:            ollamaResp.Model,
+		PromptTokens:     ollamaResp.PromptEvalCount,
+		CompletionTokens: ollamaResp.EvalCount,
+		Duration:         time.Since(start),
+	}, nil
+}
+
+// Stream returns the completion of req.Prompt, passing each chunk to
+// onToken as Ollama streams it. Ollama streams newline-delimited JSON
+// objects, the last of which has Done set and carries the token counts.
+// The configured timeout applies to the wait for the first chunk and
+// between chunks, not to the whole stream.
+func (o *Ollama) Stream(ctx context.Context, req *GenerateRequest, onToken StreamFunc) (*GenerateResponse, error) {
+	ctx, touch, stop := withIdleTimeout(ctx, o.timeout)
+	defer stop()
+
+	start := time.Now()
+	resp, err := postJSON(ctx, o.httpClient, o.endpoint+"/api/generate", nil, o.request(req, true))
+	if err != nil {
+		return nil, transportError(ctx, o.Backend(), o.model, err)
+	}
+	defer resp.Body.Close()
+	touch()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, o.statusError(resp)
+	}
+
+	var text strings.Builder
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk OllamaResponse
+		if err := decoder.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF // The stream ended without a done chunk
+			}
+			return nil, transportError(ctx, o.Backend(), o.model, fmt.Errorf("reading stream: %w", err))
+		}
+		touch()
+		if chunk.Error != "" {
+			return nil, &LLMError{Backend: o.Backend(), Model: o.model, Message: chunk.Error} // Failed mid-stream
+		}
+		if chunk.Response != "" {
+			text.WriteString(chunk.Response)
+			if err := onToken(chunk.Response); err != nil {
+				return nil, err
+			}
+		}
+		if chunk.Done {
+			return &GenerateResponse{
+				Text:             text.String(),
+				Model:            chunk.Model,
+				PromptTokens:     chunk.PromptEvalCount,
+				CompletionTokens: chunk.EvalCount,
+				Duration:         time.Since(start),
+			}, nil
+		}
+	}
+}
+
+// request builds the API request for req.
+func (o *Ollama) request(req *GenerateRequest, stream bool) *OllamaRequest {
+	settings := o.settings.apply(req)
+	ollamaReq := &OllamaRequest{
+		Model:  o.model,
+		Prompt: req.Prompt,
+		System: settings.system,
+		Format: req.Schema,
+		Stream: stream,
+	}
+	if settings.temperature != nil || settings.maxTokens > 0 {
+		ollamaReq.Options = &OllamaOptions{
+			Temperature: settings.temperature,
+			NumPredict:  settings.maxTokens,
+		}
+	}
+	return ollamaReq
+}
+
+// statusError converts an error response into an *LLMError.
+func (o *Ollama) statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	message := strings.TrimSpace(string(body))
+	var ollamaResp OllamaResponse
+	if json.Unmarshal(body, &ollamaResp) == nil && ollamaResp.Error != "" {
+		message = ollamaResp.Error
+	}
+
+	llmErr := &LLMError{Backend: o.Backend(), Model: o.model, StatusCode: resp.StatusCode, Message: message}
+	if isModelNotFound(resp.StatusCode, message) {
+		llmErr.Kind = ErrModelNotFound
+	}
+	return llmErr
+}
@@ -0,0 +1,328 @@
+// Package insights provides the OpenAI-compatible LLM backend.
+package insights
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/config"
+)
+
+// OpenAI generates text with any server implementing the OpenAI chat
+// completions API, such as llama.cpp server, vLLM or LM Studio. The
+// configured endpoint is the API base URL, usually ending in /v1.
+type OpenAI struct {
+	httpClient     *http.Client
+	endpoint       string
+	model          string
+	embeddingModel string
+	apiKey         string
+	timeout        time.Duration
+	settings       generationSettings
+}
+
+// NewOpenAI creates an OpenAI-compatible backend.
+func NewOpenAI(cfg config.LLMConfig) *OpenAI {
+	return &OpenAI{
+		httpClient:     &http.Client{},
+		endpoint:       strings.TrimSuffix(cfg.Endpoint, "/"),
+		model:          cfg.Model,
+		embeddingModel: cfg.EmbeddingModel,
+		apiKey:         cfg.APIKey,
+		timeout:        cfg.Timeout,
+		settings:       newGenerationSettings(cfg),
+	}
+}
+
+// ChatMessage is a message in a chat completion request.
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatCompletionRequest represents a request to the chat completions API.
+type ChatCompletionRequest struct {
+	Model          string          `json:"model"`
+	Messages       []ChatMessage   `json:"messages"`
+	Temperature    *float64        `json:"temperature,omitempty"`
+	MaxTokens      int             `json:"max_tokens,omitempty"`
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	Stream         bool            `json:"stream"`
+	StreamOptions  *StreamOptions  `json:"stream_options,omitempty"`
+}
+
+// StreamOptions configures a streamed chat completion.
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"` // Send token counts in a final chunk
+}
+
+// ResponseFormat constrains a chat completion to JSON matching a schema.
+type ResponseFormat struct {
+	Type       string `json:"type"` // "json_schema"
+	JSONSchema struct {
+		Name   string          `json:"name"`
+		Schema json.RawMessage `json:"schema"`
+	} `json:"json_schema"`
+}
+
+// ChatCompletionResponse represents a response from the chat completions API.
+type ChatCompletionResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      ChatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// ChatCompletionChunk is an event in a streamed chat completion.
+type ChatCompletionChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta        ChatMessage `json:"delta"`
+		FinishReason *string     `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// sseDone is the data of the event ending an OpenAI stream.
+const sseDone = "[DONE]"
+
+// Backend returns "openai".
+func (o *OpenAI) Backend() string { return "openai" }
+
+// Generate returns the completion of req.Prompt.
+func (o *OpenAI) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	resp, err := postJSON(ctx, o.httpClient, o.endpoint+"/chat/completions", o.headers(), o.request(req, false))
+	if err != nil {
+		return nil, transportError(ctx, o.Backend(), o.model, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, o.statusError(resp)
+	}
+
+	var chatResp ChatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return nil, transportError(ctx, o.Backend(), o.model, fmt.Errorf("decoding response: %w", err))
+	}
+	if len(chatResp.Choices) == 0 {
+		return nil, &LLMError{Backend: o.Backend(), Model: o.model, StatusCode: resp.StatusCode, Message: "response has no choices"}
+	}
+
+	return &GenerateResponse{
+		Text:             chatResp.Choices[0].Message.Content,
+		Model:            chatResp.Model,
+		PromptTokens:     chatResp.Usage.PromptTokens,
+		CompletionTokens: chatResp.Usage.CompletionTokens,
+		Duration:         time.Since(start),
+	}, nil
+}
+
+// Stream returns the completion of req.Prompt, passing each chunk to
+// onToken as the server streams it as server-sent events. The configured
+// timeout applies to the wait for the first event and between events, not
+// to the whole stream.
+func (o *OpenAI) Stream(ctx context.Context, req *GenerateRequest, onToken StreamFunc) (*GenerateResponse, error) {
+	ctx, touch, stop := withIdleTimeout(ctx, o.timeout)
+	defer stop()
+
+	start := time.Now()
+	resp, err := postJSON(ctx, o.httpClient, o.endpoint+"/chat/completions", o.headers(), o.request(req, true))
+	if err != nil {
+		return nil, transportError(ctx, o.Backend(), o.model, err)
+	}
+	defer resp.Body.Close()
+	touch()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, o.statusError(resp)
+	}
+
+	result := &GenerateResponse{Model: o.model}
+	var text strings.Builder
+	var tokenErr error
+	finished := false
+	err = readSSE(resp.Body, func(data string) error {
+		touch()
+		if data == sseDone {
+			finished = true
+			return errStopSSE
+		}
+		var chunk ChatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("decoding stream event: %w", err)
+		}
+		if chunk.Error != nil {
+			return &LLMError{Backend: o.Backend(), Model: o.model, Message: chunk.Error.Message} // Failed mid-stream
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			result.PromptTokens = chunk.Usage.PromptTokens
+			result.CompletionTokens = chunk.Usage.CompletionTokens
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				text.WriteString(choice.Delta.Content)
+				if tokenErr = onToken(choice.Delta.Content); tokenErr != nil {
+					return tokenErr
+				}
+			}
+			if choice.FinishReason != nil {
+				finished = true // Some servers close the stream without [DONE]
+			}
+		}
+		return nil
+	})
+	var llmErr *LLMError
+	switch {
+	case tokenErr != nil:
+		return nil, tokenErr
+	case errors.As(err, &llmErr):
+		return nil, err
+	case err != nil:
+		return nil, transportError(ctx, o.Backend(), o.model, err)
+	case !finished:
+		return nil, transportError(ctx, o.Backend(), o.model, fmt.Errorf("reading stream: %w", io.ErrUnexpectedEOF))
+	}
+
+	result.Text = text.String()
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// request builds the API request for req.
+func (o *OpenAI) request(req *GenerateRequest, stream bool) *ChatCompletionRequest {
+	settings := o.settings.apply(req)
+	var messages []ChatMessage
+	if settings.system != "" {
+		messages = append(messages, ChatMessage{Role: "system", Content: settings.system})
+	}
+	messages = append(messages, ChatMessage{Role: "user", Content: req.Prompt})
+
+	chatReq := &ChatCompletionRequest{
+		Model:       o.model,
+		Messages:    messages,
+		Temperature: settings.temperature,
+		MaxTokens:   settings.maxTokens,
+		Stream:      stream,
+	}
+	if stream {
+		chatReq.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+	if req.Schema != nil {
+		chatReq.ResponseFormat = &ResponseFormat{Type: "json_schema"}
+		chatReq.ResponseFormat.JSONSchema.Name = "response"
+		chatReq.ResponseFormat.JSONSchema.Schema = req.Schema
+	}
+	return chatReq
+}
+
+// headers returns the request headers, authenticating if an API key is set.
+func (o *OpenAI) headers() map[string]string {
+	if o.apiKey == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + o.apiKey}
+}
+
+// statusError converts an error response into an *LLMError. Servers differ
+// in where they put the message, so both the OpenAI form
+// {"error": {"message", "code"}} and a top-level "message" are accepted.
+func (o *OpenAI) statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	message := strings.TrimSpace(string(body))
+	var errResp struct {
+		Message string `json:"message"`
+		Error   struct {
+			Message string          `json:"message"`
+			Code    json.RawMessage `json:"code"` // String or number depending on server
+		} `json:"error"`
+	}
+	code := ""
+	if json.Unmarshal(body, &errResp) == nil {
+		switch {
+		case errResp.Error.Message != "":
+			message = errResp.Error.Message
+		case errResp.Message != "":
+			message = errResp.Message
+		}
+		code = strings.Trim(string(errResp.Error.Code), `"`)
+	}
+
+	llmErr := &LLMError{Backend: o.Backend(), Model: o.model, StatusCode: resp.StatusCode, Message: message}
+	if code == "model_not_found" || isModelNotFound(resp.StatusCode, message) {
+		llmErr.Kind = ErrModelNotFound
+	}
+	return llmErr
+}
+
+// errStopSSE stops readSSE without an error.
+var errStopSSE = errors.New("stop reading events")
+
+// readSSE reads a server-sent event stream, calling onData with the data of
+// each event until the stream ends or onData returns an error. Events
+// without data, such as comments used as keep-alives, are skipped.
+func readSSE(r io.Reader, onData func(data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			return nil
+		}
+		event := strings.Join(data, "\n")
+		data = data[:0]
+		return onData(event)
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		var err error
+		switch {
+		case line == "":
+			err = dispatch()
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		if err == errStopSSE {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading stream: %w", err)
+	}
+	// Dispatch a final event not followed by a blank line.
+	if err := dispatch(); err != nil && err != errStopSSE {
+		return err
+	}
+	return nil
+}
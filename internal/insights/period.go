@@ -0,0 +1,112 @@
+// Package insights provides analysis periods and period-over-period deltas.
+package insights
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// Period describes the date range being analyzed and, optionally, the
+// equally long range immediately before it used for comparison.
+type Period struct {
+	Current  data.DateRange  `json:"current"`
+	Previous *data.DateRange `json:"previous,omitempty"` // nil when comparison is disabled
+}
+
+// NewPeriod creates a period covering [start, end). If compare is true the
+// previous period of the same length is included.
+func NewPeriod(start, end time.Time, compare bool) Period {
+	p := Period{Current: data.DateRange{Start: start, End: end}}
+	if compare {
+		length := end.Sub(start)
+		p.Previous = &data.DateRange{Start: start.Add(-length), End: start}
+	}
+	return p
+}
+
+// Days returns the length of the current range in whole days (at least 1).
+func (p Period) Days() int {
+	days := int(math.Ceil(p.Current.End.Sub(p.Current.Start).Hours() / 24))
+	if days < 1 {
+		return 1
+	}
+	return days
+}
+
+// Comparing reports whether a previous period is set.
+func (p Period) Comparing() bool {
+	return p.Previous != nil
+}
+
+// Delta describes the change in a metric between two periods.
+type Delta struct {
+	Current       float64 `json:"current"`
+	Previous      float64 `json:"previous"`
+	Change        float64 `json:"change"`
+	ChangePercent float64 `json:"change_percent"`
+	Direction     string  `json:"direction"` // "up", "down", "flat"
+}
+
+// NewDelta calculates the change from previous to current.
+func NewDelta(current, previous float64) *Delta {
+	d := &Delta{
+		Current:  current,
+		Previous: previous,
+		Change:   current - previous,
+	}
+
+	if previous != 0 {
+		d.ChangePercent = math.Round(d.Change/math.Abs(previous)*10000) / 100
+	}
+
+	switch {
+	case d.Change > 0:
+		d.Direction = "up"
+	case d.Change < 0:
+		d.Direction = "down"
+	default:
+		d.Direction = "flat"
+	}
+	return d
+}
+
+// Label formats the delta for display, e.g. "+12.5%" or "-3".
+// Absolute change is used when there is no previous value to compare to.
+func (d *Delta) Label() string {
+	if d.Previous == 0 {
+		if d.Change == 0 {
+			return "0%"
+		}
+		return fmt.Sprintf("%+.0f", d.Change)
+	}
+	return fmt.Sprintf("%+.1f%%", d.ChangePercent)
+}
+
+// SummaryDeltas compares two analytics summaries metric by metric.
+// Keys are "<platform>.<metric>", e.g. "youtube.views".
+func SummaryDeltas(current, previous *data.AnalyticsSummary) map[string]*Delta {
+	deltas := make(map[string]*Delta)
+	if current == nil || previous == nil {
+		return deltas
+	}
+
+	if current.YouTube != nil && previous.YouTube != nil {
+		deltas["youtube.views"] = NewDelta(float64(current.YouTube.TotalViews), float64(previous.YouTube.TotalViews))
+		deltas["youtube.engagement_rate"] = NewDelta(current.YouTube.EngagementRate, previous.YouTube.EngagementRate)
+		deltas["youtube.subscribers"] = NewDelta(float64(current.YouTube.SubscriberChange), float64(previous.YouTube.SubscriberChange))
+	}
+	if current.X != nil && previous.X != nil {
+		deltas["x.impressions"] = NewDelta(float64(current.X.TotalImpressions), float64(previous.X.TotalImpressions))
+		deltas["x.engagement_rate"] = NewDelta(current.X.EngagementRate, previous.X.EngagementRate)
+		deltas["x.followers"] = NewDelta(float64(current.X.FollowerChange), float64(previous.X.FollowerChange))
+	}
+	if current.LinkedIn != nil && previous.LinkedIn != nil {
+		deltas["linkedin.impressions"] = NewDelta(float64(current.LinkedIn.TotalImpressions), float64(previous.LinkedIn.TotalImpressions))
+		deltas["linkedin.engagement_rate"] = NewDelta(current.LinkedIn.EngagementRate, previous.LinkedIn.EngagementRate)
+		deltas["linkedin.connections"] = NewDelta(float64(current.LinkedIn.ConnectionChange), float64(previous.LinkedIn.ConnectionChange))
+	}
+	return deltas
+}
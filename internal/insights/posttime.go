@@ -0,0 +1,386 @@
+// Package insights provides best-time-to-post analysis, comparing the
+// early engagement of posts by the weekday and hour they were published.
+package insights
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/config"
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+const (
+	// earlyEngagementWindow is how long after publishing a post's
+	// engagement is measured, so that older posts, which have had longer
+	// to collect engagement, don't count for more.
+	earlyEngagementWindow = 24 * time.Hour
+
+	// postTimeMinPosts is the fewest posts a time slot needs before its
+	// engagement is trusted, and postTimePriorPosts is how many posts'
+	// weight the platform average gets when scoring a slot.
+	postTimeMinPosts   = 3
+	postTimePriorPosts = 5
+
+	postingWindowHours = 3 // Length of a recommended posting window
+	postingWindowCount = 3 // Recommended windows per platform
+
+	// bestTimeLookbackDays is how far back Insights looks for posts.
+	bestTimeLookbackDays = 180
+)
+
+// postTimeWeekdays orders the heatmap's rows, Monday first.
+var postTimeWeekdays = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday,
+	time.Friday, time.Saturday, time.Sunday,
+}
+
+// PostTimeConfidence is how sure the analysis is that a time slot's
+// engagement differs from the platform average.
+type PostTimeConfidence string
+
+const (
+	PostTimeLow    PostTimeConfidence = "low"    // Fewer than postTimeMinPosts posts
+	PostTimeMedium PostTimeConfidence = "medium" // The confidence interval includes the average
+	PostTimeHigh   PostTimeConfidence = "high"   // The confidence interval excludes the average
+)
+
+// insightConfidence is the confidence given to insights recommending
+// windows of each level.
+var insightConfidence = map[PostTimeConfidence]float64{
+	PostTimeLow:    0.3,
+	PostTimeMedium: 0.6,
+	PostTimeHigh:   0.85,
+}
+
+// PostTimeSlot is the early engagement of posts published in a span of
+// hours on one weekday, in the account's timezone.
+type PostTimeSlot struct {
+	Weekday time.Weekday `json:"weekday"` // 0 is Sunday
+	Hour    int          `json:"hour"`    // First hour, 0-23
+	Hours   int          `json:"hours"`
+	Posts   int          `json:"posts"`
+	Early   int          `json:"early"` // Posts measured from an early snapshot rather than their current totals
+
+	// Mean engagement rate, 0-1, with its 95% confidence interval.
+	Mean  float64 `json:"mean"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+
+	// Score is the mean shrunk towards the platform average, so that slots
+	// with few posts rank nearer the middle, and Lift is how far it is
+	// above the average, e.g. 0.2 for 20% higher.
+	Score      float64            `json:"score"`
+	Lift       float64            `json:"lift"`
+	Confidence PostTimeConfidence `json:"confidence"`
+
+	heat float64 // Score scaled between the lowest and highest in the heatmap
+}
+
+// Label describes the slot, e.g. "Tue 18:00-21:00".
+func (s *PostTimeSlot) Label() string {
+	return fmt.Sprintf("%s %02d:00-%02d:00", s.Weekday.String()[:3], s.Hour, (s.Hour+s.Hours)%24)
+}
+
+// HeatPercent returns the slot's shade in the heatmap, from 0 for the
+// lowest score to 100 for the highest.
+func (s *PostTimeSlot) HeatPercent() int {
+	return int(math.Round(s.heat * 100))
+}
+
+// PostTimeDay is a heatmap row: one weekday's hourly slots.
+type PostTimeDay struct {
+	Weekday time.Weekday    `json:"weekday"`
+	Hours   []*PostTimeSlot `json:"hours"`
+}
+
+// Name returns the weekday's short name.
+func (d PostTimeDay) Name() string {
+	return d.Weekday.String()[:3]
+}
+
+// PostTimeAnalysis is a platform's early engagement by the weekday and
+// hour posts were published, with the posting windows it recommends.
+type PostTimeAnalysis struct {
+	Platform data.Platform `json:"platform"`
+	Timezone string        `json:"timezone"`
+	Posts    int           `json:"posts"`
+	Early    int           `json:"early"`
+	Mean     float64       `json:"mean"` // Average engagement rate of all posts, 0-1
+
+	Days    []PostTimeDay   `json:"days"`    // Monday first
+	Windows []*PostTimeSlot `json:"windows"` // Best first
+}
+
+// PostTimeAnalyzer finds the times of the week when posts get the most
+// engagement.
+type PostTimeAnalyzer struct {
+	store    storage.Store
+	curator  *Curator
+	location *time.Location
+}
+
+// NewPostTimeAnalyzer creates a PostTimeAnalyzer that buckets posts in the
+// configured timezone.
+func NewPostTimeAnalyzer(store storage.Store, curator *Curator, cfg config.InsightsConfig) *PostTimeAnalyzer {
+	location := cfg.Timezone
+	if location == nil {
+		location = time.Local
+	}
+	return &PostTimeAnalyzer{store: store, curator: curator, location: location}
+}
+
+// Analyze compares the engagement rate of the platform's posts published
+// in the date range by weekday and hour. Each post's rate is taken from
+// its last snapshot in the first earlyEngagementWindow after publishing,
+// or from its current totals if there is none, as for backfilled posts.
+// Posts younger than the window are left out.
+func (a *PostTimeAnalyzer) Analyze(ctx context.Context, platform data.Platform, dateRange data.DateRange) (*PostTimeAnalysis, error) {
+	items, _, err := a.store.ListContent(ctx, data.ContentQuery{Platform: platform, DateRange: dateRange})
+	if err != nil {
+		return nil, fmt.Errorf("listing %s content: %w", platform, err)
+	}
+	snapshots, err := a.store.GetEarlySnapshots(ctx, platform, dateRange, earlyEngagementWindow)
+	if err != nil {
+		return nil, fmt.Errorf("getting %s early snapshots: %w", platform, err)
+	}
+
+	var rates [7][24][]float64
+	var early [7][24]int
+	var all []float64
+	analysis := &PostTimeAnalysis{Platform: platform, Timezone: a.location.String()}
+	cutoff := time.Now().Add(-earlyEngagementWindow)
+	for _, item := range items {
+		if item.PublishedAt.IsZero() || item.PublishedAt.After(cutoff) {
+			continue
+		}
+		rate, isEarly, ok := earlyEngagementRate(item, snapshots[item.ID])
+		if !ok {
+			continue
+		}
+		published := item.PublishedAt.In(a.location)
+		day, hour := published.Weekday(), published.Hour()
+		rates[day][hour] = append(rates[day][hour], rate)
+		all = append(all, rate)
+		if isEarly {
+			early[day][hour]++
+			analysis.Early++
+		}
+	}
+	analysis.Posts = len(all)
+	if len(all) > 0 {
+		analysis.Mean = mean(all)
+	}
+
+	var scored []*PostTimeSlot
+	for _, day := range postTimeWeekdays {
+		row := PostTimeDay{Weekday: day}
+		for hour := 0; hour < 24; hour++ {
+			slot := analysis.slot(day, hour, 1, rates[day][hour:hour+1], early[day][hour:hour+1])
+			row.Hours = append(row.Hours, slot)
+			if slot.Posts > 0 {
+				scored = append(scored, slot)
+			}
+		}
+		analysis.Days = append(analysis.Days, row)
+	}
+	shadeSlots(scored)
+
+	var windows []*PostTimeSlot
+	for _, day := range postTimeWeekdays {
+		for hour := 0; hour+postingWindowHours <= 24; hour++ {
+			end := hour + postingWindowHours
+			window := analysis.slot(day, hour, postingWindowHours, rates[day][hour:end], early[day][hour:end])
+			if window.Posts >= postTimeMinPosts && window.Score > analysis.Mean {
+				windows = append(windows, window)
+			}
+		}
+	}
+	sort.SliceStable(windows, func(i, j int) bool { return windows[i].Score > windows[j].Score })
+	for _, window := range windows {
+		if len(analysis.Windows) == postingWindowCount {
+			break
+		}
+		overlaps := false
+		for _, chosen := range analysis.Windows {
+			overlaps = overlaps || (chosen.Weekday == window.Weekday &&
+				window.Hour < chosen.Hour+chosen.Hours && chosen.Hour < window.Hour+window.Hours)
+		}
+		if !overlaps {
+			analysis.Windows = append(analysis.Windows, window)
+		}
+	}
+	return analysis, nil
+}
+
+// slot summarizes the engagement rates of posts published in the given
+// hours of a weekday.
+func (a *PostTimeAnalysis) slot(day time.Weekday, hour, hours int, rates [][]float64, early []int) *PostTimeSlot {
+	slot := &PostTimeSlot{Weekday: day, Hour: hour, Hours: hours, Confidence: PostTimeLow}
+	var values []float64
+	for i := range rates {
+		values = append(values, rates[i]...)
+		slot.Early += early[i]
+	}
+	slot.Posts = len(values)
+	if slot.Posts == 0 {
+		return slot
+	}
+
+	n := float64(slot.Posts)
+	slot.Mean = mean(values)
+	slot.Lower, slot.Upper = slot.Mean, slot.Mean
+	if slot.Posts > 1 {
+		var squares float64
+		for _, v := range values {
+			squares += (v - slot.Mean) * (v - slot.Mean)
+		}
+		margin := tCritical(slot.Posts-1) * math.Sqrt(squares/(n-1)/n)
+		slot.Lower, slot.Upper = math.Max(slot.Mean-margin, 0), slot.Mean+margin
+	}
+	slot.Score = (n*slot.Mean + postTimePriorPosts*a.Mean) / (n + postTimePriorPosts)
+	if a.Mean > 0 {
+		slot.Lift = slot.Score/a.Mean - 1
+	}
+	if slot.Posts >= postTimeMinPosts {
+		slot.Confidence = PostTimeMedium
+		if slot.Lower > a.Mean || slot.Upper < a.Mean {
+			slot.Confidence = PostTimeHigh
+		}
+	}
+	return slot
+}
+
+// shadeSlots scales the slots' scores between the lowest and highest.
+func shadeSlots(slots []*PostTimeSlot) {
+	if len(slots) == 0 {
+		return
+	}
+	low, high := slots[0].Score, slots[0].Score
+	for _, slot := range slots {
+		low, high = math.Min(low, slot.Score), math.Max(high, slot.Score)
+	}
+	for _, slot := range slots {
+		slot.heat = 0.5
+		if high > low {
+			slot.heat = (slot.Score - low) / (high - low)
+		}
+	}
+}
+
+// earlyEngagementRate returns a post's engagement rate from its early
+// snapshot, if it has one with views, and otherwise from its current
+// totals. ok is false if the post has no views to measure it by.
+func earlyEngagementRate(item *data.ContentItem, snapshot *data.ContentSnapshot) (rate float64, early, ok bool) {
+	if snapshot != nil && snapshot.Views > 0 {
+		engagements := snapshot.Likes + snapshot.Comments + snapshot.Shares
+		return float64(engagements) / float64(snapshot.Views), true, true
+	}
+	if item.Views > 0 {
+		return item.EngagementRate, false, true
+	}
+	return 0, false, false
+}
+
+// tCritical returns the two-sided 95% critical value of Student's t
+// distribution with df degrees of freedom, which widens the intervals of
+// slots with few posts.
+func tCritical(df int) float64 {
+	table := []float64{12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+		2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086}
+	switch {
+	case df < 1:
+		return math.Inf(1)
+	case df <= len(table):
+		return table[df-1]
+	case df <= 30:
+		return 2.042
+	case df <= 60:
+		return 2.000
+	}
+	return forecastZ
+}
+
+// Insights analyzes the last bestTimeLookbackDays of each platform's
+// posts and returns a recommendation for each platform with a posting
+// window that beats its average.
+func (a *PostTimeAnalyzer) Insights(ctx context.Context) ([]*GeneratedInsight, error) {
+	now := time.Now()
+	dateRange := data.DateRange{Start: startOfDay(now).AddDate(0, 0, -bestTimeLookbackDays), End: now}
+	id := generateID()
+
+	var generated []*GeneratedInsight
+	for _, platform := range promptPlatforms("") {
+		analysis, err := a.Analyze(ctx, platform, dateRange)
+		if err != nil {
+			return nil, err
+		}
+		if len(analysis.Windows) == 0 {
+			continue
+		}
+		generated = append(generated, analysis.insight(fmt.Sprintf("%s_%s", id, platform), now))
+	}
+	return generated, nil
+}
+
+// insight describes the analysis's recommended windows as an insight.
+func (a *PostTimeAnalysis) insight(id string, now time.Time) *GeneratedInsight {
+	best := a.Windows[0]
+	name := platformNames[a.Platform]
+
+	var description strings.Builder
+	fmt.Fprintf(&description, "%s posts published %s (%s) averaged %.2f%% engagement in their first day, "+
+		"%.0f%% above the %s average of %.2f%%, across %d posts.",
+		name, best.Label(), a.Timezone, best.Mean*100, best.Lift*100, name, a.Mean*100, best.Posts)
+	if len(a.Windows) > 1 {
+		var others []string
+		for _, window := range a.Windows[1:] {
+			others = append(others, fmt.Sprintf("%s (+%.0f%%, %d posts)", window.Label(), window.Lift*100, window.Posts))
+		}
+		fmt.Fprintf(&description, " Also strong: %s.", strings.Join(others, ", "))
+	}
+
+	detail := &data.InsightDetail{InsightID: id}
+	for _, window := range a.Windows {
+		change := window.Lift * 100
+		detail.Evidence = append(detail.Evidence, data.InsightEvidence{
+			Metric:    "engagement_rate",
+			Platform:  a.Platform,
+			Value:     window.Mean * 100,
+			ChangePct: &change,
+		})
+		detail.Actions = append(detail.Actions, fmt.Sprintf("Schedule %s posts for %s (%s)", name, window.Label(), a.Timezone))
+	}
+
+	return &GeneratedInsight{
+		Insight: &data.Insight{
+			ID:          id,
+			Platform:    a.Platform,
+			Type:        data.InsightTypeRecommendation,
+			Title:       fmt.Sprintf("Best time to post on %s: %s", name, best.Label()),
+			Description: description.String(),
+			Confidence:  insightConfidence[best.Confidence],
+			GeneratedAt: now,
+			DataRange:   "custom",
+		},
+		Detail: detail,
+	}
+}
+
+// Run saves the recommended posting windows as insights, skipping ones
+// that repeat recent insights.
+func (a *PostTimeAnalyzer) Run(ctx context.Context) error {
+	generated, err := a.Insights(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := a.curator.Save(ctx, generated); err != nil {
+		return fmt.Errorf("saving best time insights: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,828 @@
+// Package insights provides prompt construction from user-editable templates.
+package insights
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+// Prompt template names.
+const (
+	PromptAnalytics   = "analytics"
+	PromptSuggestions = "suggestions"
+)
+
+// PromptNames lists the editable prompt templates.
+var PromptNames = []string{PromptAnalytics, PromptSuggestions}
+
+// promptSettingPrefix prefixes the settings keys of edited templates, e.g.
+// "prompt_template.analytics".
+const promptSettingPrefix = "prompt_template."
+
+// Prompt template errors.
+var (
+	ErrUnknownPrompt   = errors.New("unknown prompt template")
+	ErrInvalidTemplate = errors.New("invalid prompt template")
+)
+
+// Limits on the inputs gathered for prompts, before budget reductions.
+const (
+	promptContentLimit  = 5   // Top and bottom content items each
+	promptThemeLimit    = 8   // Comment themes
+	promptAnomalyLimit  = 5   // Unusual days
+	promptCommentSample = 500 // Recent comments scanned for themes
+	promptFeedbackLimit = 3   // Rated insights of each kind
+)
+
+// defaultPromptTemplates are used until a template is edited.
+var defaultPromptTemplates = map[string]string{
+	PromptAnalytics: `Analyze the following social media analytics for {{.PeriodLabel}} and provide insights.
+{{- if .Period.Comparing}} Changes are compared with the previous {{.Period.Days}} days.{{end}}
+
+Metrics:
+{{- range .Metrics}}
+- {{.Label}}: {{.Formatted}}{{with .Delta}} ({{.Label}}){{end}}
+{{- else}}
+- No metrics were recorded for this period.
+{{- end}}
+{{- if .TopContent}}
+
+Best performing content by views:
+{{- range .TopContent}}
+- [{{platformName .Platform}}] "{{snippet (or .Title .Body) 80}}": {{num .Views}} views, {{pct .EngagementRate}} engagement
+{{- end}}
+{{- end}}
+{{- if .BottomContent}}
+
+Weakest content by views:
+{{- range .BottomContent}}
+- [{{platformName .Platform}}] "{{snippet (or .Title .Body) 80}}": {{num .Views}} views, {{pct .EngagementRate}} engagement
+{{- end}}
+{{- end}}
+{{- if .Anomalies}}
+
+Unusual days:
+{{- range .Anomalies}}
+- {{.Date.Format "Jan 2"}}: {{.Label}} {{if .Shift}}shifted to {{num .Value}} from {{num .Expected}}{{else}}was {{num .Value}} against a typical {{num .Expected}}{{end}}
+{{- end}}
+{{- end}}
+{{- if .Goals}}
+
+Goals:
+{{- range .Goals}}
+- {{.Label}}: {{.FormatValue .Current}} towards {{.FormatValue .Goal.Target}} by {{.Goal.Deadline.Format "Jan 2, 2006"}}, {{.Status.Label}}
+{{- if and .HasRates .Basis}} (needs {{.FormatValue .RequiredRate}} a day, recently {{.FormatValue .ActualRate}}){{end}}
+{{- end}}
+{{- end}}
+{{- if .Comments.Total}}
+
+Audience comments: {{.Comments.Total}} this period, {{.Comments.Questions}} of them questions.
+{{- if or .Comments.Positive .Comments.Negative}} {{.Comments.Positive}} were positive and {{.Comments.Negative}} negative.{{end}}
+{{- range .Comments.Themes}}
+- "{{.Term}}" came up in {{.Count}} comments{{with .Example}}, e.g. "{{snippet . 100}}"{{end}}
+{{- end}}
+{{- end}}
+{{- if .Feedback.Liked}}
+
+The team found insights like these useful:
+{{- range .Feedback.Liked}}
+- [{{.Type}}] {{.Title}}
+{{- end}}
+{{- end}}
+{{- if .Feedback.Disliked}}
+
+The team found insights like these unhelpful, so avoid similar ones:
+{{- range .Feedback.Disliked}}
+- [{{.Type}}] {{.Title}}
+{{- end}}
+{{- end}}
+
+Identify key observations, areas of strength, areas needing improvement
+and recommended actions. Use "alert" for problems needing attention,
+"trend" for notable movements, "recommendation" for suggested changes and
+"summary" for overall performance.`,
+
+	PromptSuggestions: `Based on recent performance on {{platformName .Platform}} for {{.PeriodLabel}}, suggest 3 content ideas that could improve engagement.
+
+Metrics:
+{{- range .Metrics}}
+- {{.Label}}: {{.Formatted}}{{with .Delta}} ({{.Label}}){{end}}
+{{- else}}
+- No metrics were recorded for this period.
+{{- end}}
+{{- if .Trends}}
+
+Trends:
+{{- range .Trends}}
+- {{.Metric}} is trending {{.Trend}} ({{printf "%+.1f" .ChangePercent}}%)
+{{- end}}
+{{- end}}
+{{- if .TopContent}}
+
+What worked best:
+{{- range .TopContent}}
+- "{{snippet (or .Title .Body) 80}}": {{num .Views}} views, {{pct .EngagementRate}} engagement
+{{- end}}
+{{- end}}
+{{- if .BottomContent}}
+
+What worked least:
+{{- range .BottomContent}}
+- "{{snippet (or .Title .Body) 80}}": {{num .Views}} views, {{pct .EngagementRate}} engagement
+{{- end}}
+{{- end}}
+{{- if .Comments.Themes}}
+
+What the audience talks about:
+{{- range .Comments.Themes}}
+- "{{.Term}}" ({{.Count}} comments){{with .Example}}, e.g. "{{snippet . 100}}"{{end}}
+{{- end}}
+{{- end}}
+
+Build on what worked and answer what the audience is asking about.`,
+}
+
+// promptInstructions are appended to each rendered template to describe the
+// response format, so editing a template can't break response parsing.
+var promptInstructions = map[string]string{
+	PromptAnalytics:   insightInstructions,
+	PromptSuggestions: suggestionInstructions,
+}
+
+// platformNames are the display names of platforms in prompts.
+var platformNames = map[data.Platform]string{
+	data.PlatformYouTube:  "YouTube",
+	data.PlatformX:        "X (Twitter)",
+	data.PlatformLinkedIn: "LinkedIn",
+}
+
+// promptFuncs are the functions available to prompt templates.
+var promptFuncs = template.FuncMap{
+	"num":     formatNumber,
+	"pct":     func(rate float64) string { return fmt.Sprintf("%.2f%%", rate*100) },
+	"snippet": snippet,
+	"platformName": func(p data.Platform) string {
+		if name, ok := platformNames[p]; ok {
+			return name
+		}
+		return "all platforms"
+	},
+}
+
+// PromptData is the data available to prompt templates.
+type PromptData struct {
+	Period        Period
+	PeriodLabel   string        // e.g. "Jan 2, 2026 to Jan 8, 2026"
+	Platform      data.Platform // Empty for all platforms
+	Metrics       []PromptMetric
+	Trends        []*data.TrendData
+	TopContent    []*data.ContentItem
+	BottomContent []*data.ContentItem
+	Anomalies     []PromptAnomaly
+	Goals         []*GoalProgress // Goals whose deadline is in or after the period
+	Comments      CommentSummary
+	Feedback      PromptFeedback
+}
+
+// PromptMetric is a headline metric with its change from the previous
+// period, if comparing.
+type PromptMetric struct {
+	Key   string // As in SummaryDeltas, e.g. "youtube.views"
+	Label string // e.g. "YouTube views"
+	Value float64
+	Rate  bool   // Whether Value is a 0-1 rate
+	Delta *Delta // nil when not comparing
+}
+
+// Formatted returns the metric's value for display.
+func (m PromptMetric) Formatted() string {
+	if m.Rate {
+		return fmt.Sprintf("%.2f%%", m.Value*100)
+	}
+	return formatNumber(m.Value)
+}
+
+// PromptAnomaly is a day on which a metric was far from its typical value,
+// or moved to a new level.
+type PromptAnomaly struct {
+	Platform data.Platform
+	Metric   string
+	Label    string // e.g. "YouTube views"
+	Date     time.Time
+	Value    float64
+	Expected float64 // The typical value, or the level before a shift
+	Shift    bool    // Whether the metric moved to a new level from Date on
+}
+
+// CommentSummary describes the period's comments.
+type CommentSummary struct {
+	Total     int
+	Questions int
+	Positive  int // Comments classified as positive
+	Negative  int // Comments classified as negative
+	Themes    []CommentTheme
+}
+
+// CommentTheme is a term recurring across comments.
+type CommentTheme struct {
+	Term    string
+	Count   int    // Comments mentioning the term
+	Example string // A short comment mentioning the term
+}
+
+// PromptFeedback holds recently rated insights, as examples of what the
+// team does and doesn't find useful.
+type PromptFeedback struct {
+	Liked    []*data.Insight
+	Disliked []*data.Insight
+}
+
+// Prompt is a rendered prompt ready to send.
+type Prompt struct {
+	Name       string
+	Text       string
+	Tokens     int // Estimated
+	Budget     int
+	Reductions []string // How inputs were shortened to fit the budget
+}
+
+// OverBudget reports whether the prompt exceeds its budget even after
+// shortening its inputs.
+func (p *Prompt) OverBudget() bool {
+	return p.Budget > 0 && p.Tokens > p.Budget
+}
+
+// PromptBuilder renders prompts from templates stored in the settings
+// table, falling back to the built-in defaults.
+type PromptBuilder struct {
+	store  storage.Store
+	trends *TrendAnalyzer
+	budget int
+}
+
+// NewPromptBuilder creates a PromptBuilder keeping prompts under budget
+// tokens. A budget of zero disables the limit.
+func NewPromptBuilder(store storage.Store, budget int) *PromptBuilder {
+	return &PromptBuilder{store: store, trends: NewTrendAnalyzer(store), budget: budget}
+}
+
+// Template returns the template for name and whether it has been edited.
+func (b *PromptBuilder) Template(ctx context.Context, name string) (string, bool, error) {
+	def, ok := defaultPromptTemplates[name]
+	if !ok {
+		return "", false, fmt.Errorf("%w: %q", ErrUnknownPrompt, name)
+	}
+	setting, err := b.store.GetSetting(ctx, promptSettingPrefix+name)
+	if err != nil {
+		return "", false, fmt.Errorf("getting prompt template: %w", err)
+	}
+	if setting == nil {
+		return def, false, nil
+	}
+	return setting.Value, true, nil
+}
+
+// DefaultTemplate returns the built-in template for name.
+func (b *PromptBuilder) DefaultTemplate(name string) (string, error) {
+	def, ok := defaultPromptTemplates[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownPrompt, name)
+	}
+	return def, nil
+}
+
+// SaveTemplate replaces the template for name after checking that it
+// renders. Errors in the template wrap ErrInvalidTemplate.
+func (b *PromptBuilder) SaveTemplate(ctx context.Context, name, text string) error {
+	if _, ok := defaultPromptTemplates[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownPrompt, name)
+	}
+	if _, err := b.Render(name, text, samplePromptData()); err != nil {
+		return err
+	}
+	if err := b.store.SaveSetting(ctx, promptSettingPrefix+name, text); err != nil {
+		return fmt.Errorf("saving prompt template: %w", err)
+	}
+	return nil
+}
+
+// ResetTemplate restores the built-in template for name.
+func (b *PromptBuilder) ResetTemplate(ctx context.Context, name string) error {
+	if _, ok := defaultPromptTemplates[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownPrompt, name)
+	}
+	if err := b.store.DeleteSetting(ctx, promptSettingPrefix+name); err != nil {
+		return fmt.Errorf("resetting prompt template: %w", err)
+	}
+	return nil
+}
+
+// Build renders the current template for name with data for platform
+// (empty for all platforms) over period.
+func (b *PromptBuilder) Build(ctx context.Context, name string, platform data.Platform, period Period) (*Prompt, error) {
+	text, _, err := b.Template(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	promptData, err := b.Data(ctx, platform, period)
+	if err != nil {
+		return nil, err
+	}
+	return b.Render(name, text, promptData)
+}
+
+// Render renders text as the template for name, shortening the inputs in
+// promptData until the prompt fits the token budget. If it still doesn't
+// fit, the rendered template is truncated; the response format
+// instructions are always kept whole.
+func (b *PromptBuilder) Render(name, text string, promptData *PromptData) (*Prompt, error) {
+	instructions, ok := promptInstructions[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPrompt, name)
+	}
+	tmpl, err := template.New(name).Funcs(promptFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	prompt := &Prompt{Name: name, Budget: b.budget}
+	d := *promptData
+	for step := 0; ; step++ {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, &d); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+		}
+		prompt.Text = strings.TrimSpace(buf.String()) + "\n\n" + instructions
+		prompt.Tokens = estimateTokens(prompt.Text)
+		if b.budget <= 0 || prompt.Tokens <= b.budget {
+			return prompt, nil
+		}
+		if step == len(promptReductions) {
+			break
+		}
+		if reduction := promptReductions[step]; reduction.apply(&d) {
+			prompt.Reductions = append(prompt.Reductions, reduction.description)
+		}
+	}
+
+	// Truncate what's left, keeping the instructions.
+	body := strings.TrimSuffix(prompt.Text, "\n\n"+instructions)
+	keep := max(b.budget-estimateTokens(instructions), 0) * charsPerToken
+	if runes := []rune(body); len(runes) > keep {
+		body = string(runes[:keep])
+		if i := strings.LastIndex(body, "\n"); i > 0 {
+			body = body[:i] // Don't leave half a line
+		}
+		body += "\n..."
+	}
+	prompt.Text = body + "\n\n" + instructions
+	prompt.Tokens = estimateTokens(prompt.Text)
+	prompt.Reductions = append(prompt.Reductions, "truncated the prompt")
+	return prompt, nil
+}
+
+// promptReductions are the ways prompt inputs are shortened to fit the
+// token budget, least valuable first. Each reports whether it changed
+// anything.
+var promptReductions = []struct {
+	description string
+	apply       func(d *PromptData) bool
+}{
+	{"removed comment examples", func(d *PromptData) bool {
+		themes := make([]CommentTheme, len(d.Comments.Themes))
+		changed := false
+		for i, theme := range d.Comments.Themes {
+			changed = changed || theme.Example != ""
+			theme.Example = ""
+			themes[i] = theme
+		}
+		d.Comments.Themes = themes
+		return changed
+	}},
+	{"kept 3 comment themes", func(d *PromptData) bool {
+		return shortenThemes(&d.Comments.Themes, 3)
+	}},
+	{"kept 3 best and weakest content items", func(d *PromptData) bool {
+		top, bottom := shortenContent(&d.TopContent, 3), shortenContent(&d.BottomContent, 3)
+		return top || bottom
+	}},
+	{"kept 2 unusual days", func(d *PromptData) bool {
+		if len(d.Anomalies) <= 2 {
+			return false
+		}
+		d.Anomalies = d.Anomalies[:2]
+		return true
+	}},
+	{"removed feedback examples", func(d *PromptData) bool {
+		changed := len(d.Feedback.Liked) > 0 || len(d.Feedback.Disliked) > 0
+		d.Feedback = PromptFeedback{}
+		return changed
+	}},
+	{"removed comment themes", func(d *PromptData) bool {
+		return shortenThemes(&d.Comments.Themes, 0)
+	}},
+	{"kept 1 best and weakest content item", func(d *PromptData) bool {
+		top, bottom := shortenContent(&d.TopContent, 1), shortenContent(&d.BottomContent, 1)
+		return top || bottom
+	}},
+	{"removed trends and unusual days", func(d *PromptData) bool {
+		changed := len(d.Trends) > 0 || len(d.Anomalies) > 0
+		d.Trends, d.Anomalies = nil, nil
+		return changed
+	}},
+}
+
+func shortenThemes(themes *[]CommentTheme, n int) bool {
+	if len(*themes) <= n {
+		return false
+	}
+	*themes = (*themes)[:n]
+	return true
+}
+
+func shortenContent(items *[]*data.ContentItem, n int) bool {
+	if len(*items) <= n {
+		return false
+	}
+	*items = (*items)[:n]
+	return true
+}
+
+// charsPerToken approximates the length of a token in English text.
+const charsPerToken = 4
+
+// estimateTokens approximates the number of tokens in text.
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// Data gathers the prompt inputs for platform (empty for all platforms)
+// over period.
+func (b *PromptBuilder) Data(ctx context.Context, platform data.Platform, period Period) (*PromptData, error) {
+	d := &PromptData{
+		Period:      period,
+		PeriodLabel: periodLabel(period.Current),
+		Platform:    platform,
+	}
+
+	summary, err := b.store.GetAnalyticsSummary(ctx, period.Current)
+	if err != nil {
+		return nil, fmt.Errorf("getting analytics summary: %w", err)
+	}
+	var deltas map[string]*Delta
+	if period.Previous != nil {
+		previous, err := b.store.GetAnalyticsSummary(ctx, *period.Previous)
+		if err != nil {
+			return nil, fmt.Errorf("getting previous analytics summary: %w", err)
+		}
+		deltas = SummaryDeltas(summary, previous)
+	}
+	for _, m := range summaryMetrics(summary) {
+		if platform != "" && !strings.HasPrefix(m.Key, string(platform)+".") {
+			continue
+		}
+		m.Delta = deltas[m.Key]
+		d.Metrics = append(d.Metrics, m)
+	}
+
+	for _, ascending := range []bool{false, true} {
+		items, _, err := b.store.ListContent(ctx, data.ContentQuery{
+			Platform:  platform,
+			DateRange: period.Current,
+			Sort:      data.SortByViews,
+			Ascending: ascending,
+			Limit:     promptContentLimit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing content: %w", err)
+		}
+		if ascending {
+			d.BottomContent = excludeContent(items, d.TopContent)
+		} else {
+			d.TopContent = items
+		}
+	}
+
+	for _, p := range promptPlatforms(platform) {
+		for _, metric := range []string{primaryMetric(p), "engagement_rate"} {
+			trend, err := b.store.GetTrendData(ctx, p, metric, period.Days())
+			if err != nil {
+				return nil, fmt.Errorf("getting %s %s trend: %w", p, metric, err)
+			}
+			if trend == nil || len(trend.Points) == 0 {
+				continue
+			}
+			d.Trends = append(d.Trends, trend)
+		}
+	}
+
+	anomalies, err := b.store.GetAnomalies(ctx, data.AnomalyQuery{Platform: platform, DateRange: period.Current})
+	if err != nil {
+		return nil, fmt.Errorf("getting anomalies: %w", err)
+	}
+	for _, a := range anomalies {
+		// Stock anomalies are found in the daily change.
+		label := platformNames[a.Platform] + " " + strings.ReplaceAll(a.Metric, "_", " ")
+		if AggregationFor(a.Metric) == AggregateLast {
+			label += " gained per day"
+		}
+		d.Anomalies = append(d.Anomalies, PromptAnomaly{
+			Platform: a.Platform,
+			Metric:   a.Metric,
+			Label:    label,
+			Date:     a.Timestamp,
+			Value:    a.Value,
+			Expected: a.Expected,
+			Shift:    a.Type == data.AnomalyLevelShift,
+		})
+	}
+	sort.Slice(d.Anomalies, func(i, j int) bool {
+		return d.Anomalies[i].deviation() > d.Anomalies[j].deviation()
+	})
+	if len(d.Anomalies) > promptAnomalyLimit {
+		d.Anomalies = d.Anomalies[:promptAnomalyLimit]
+	}
+
+	goals, err := b.trends.AllGoalProgress(ctx, platform)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range goals {
+		if !g.Goal.Deadline.Before(period.Current.Start) {
+			d.Goals = append(d.Goals, g)
+		}
+	}
+
+	if d.Comments, err = b.commentSummary(ctx, platform, period.Current); err != nil {
+		return nil, err
+	}
+	if d.Feedback.Liked, err = b.store.GetRatedInsights(ctx, data.RatingUp, promptFeedbackLimit); err != nil {
+		return nil, fmt.Errorf("getting liked insights: %w", err)
+	}
+	if d.Feedback.Disliked, err = b.store.GetRatedInsights(ctx, data.RatingDown, promptFeedbackLimit); err != nil {
+		return nil, fmt.Errorf("getting disliked insights: %w", err)
+	}
+	return d, nil
+}
+
+// summaryMetrics lists the headline metrics in summary, keyed as in
+// SummaryDeltas.
+func summaryMetrics(summary *data.AnalyticsSummary) []PromptMetric {
+	if summary == nil {
+		return nil
+	}
+	var metrics []PromptMetric
+	if s := summary.YouTube; s != nil {
+		metrics = append(metrics,
+			PromptMetric{Key: "youtube.views", Label: "YouTube views", Value: float64(s.TotalViews)},
+			PromptMetric{Key: "youtube.engagement_rate", Label: "YouTube engagement rate", Value: s.EngagementRate, Rate: true},
+			PromptMetric{Key: "youtube.subscribers", Label: "YouTube subscriber change", Value: float64(s.SubscriberChange)},
+		)
+	}
+	if s := summary.X; s != nil {
+		metrics = append(metrics,
+			PromptMetric{Key: "x.impressions", Label: "X impressions", Value: float64(s.TotalImpressions)},
+			PromptMetric{Key: "x.engagement_rate", Label: "X engagement rate", Value: s.EngagementRate, Rate: true},
+			PromptMetric{Key: "x.followers", Label: "X follower change", Value: float64(s.FollowerChange)},
+		)
+	}
+	if s := summary.LinkedIn; s != nil {
+		metrics = append(metrics,
+			PromptMetric{Key: "linkedin.impressions", Label: "LinkedIn impressions", Value: float64(s.TotalImpressions)},
+			PromptMetric{Key: "linkedin.engagement_rate", Label: "LinkedIn engagement rate", Value: s.EngagementRate, Rate: true},
+			PromptMetric{Key: "linkedin.connections", Label: "LinkedIn connection change", Value: float64(s.ConnectionChange)},
+		)
+	}
+	return metrics
+}
+
+// excludeContent returns items not in exclude, so short content lists
+// don't show the same item as both best and weakest.
+func excludeContent(items, exclude []*data.ContentItem) []*data.ContentItem {
+	var kept []*data.ContentItem
+	for _, item := range items {
+		found := false
+		for _, e := range exclude {
+			if e.Platform == item.Platform && e.ID == item.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// promptPlatforms returns the platforms covered by a prompt for platform.
+func promptPlatforms(platform data.Platform) []data.Platform {
+	if platform != "" {
+		return []data.Platform{platform}
+	}
+	return []data.Platform{data.PlatformYouTube, data.PlatformX, data.PlatformLinkedIn}
+}
+
+// primaryMetric returns the reach metric tracked for a platform.
+func primaryMetric(platform data.Platform) string {
+	if platform == data.PlatformYouTube {
+		return "views"
+	}
+	return "impressions"
+}
+
+// deviation returns the relative distance of the value from the expected
+// value, for ranking anomalies.
+func (a PromptAnomaly) deviation() float64 {
+	if a.Expected == 0 {
+		return math.Abs(a.Value)
+	}
+	return math.Abs(a.Value-a.Expected) / math.Abs(a.Expected)
+}
+
+// commentSummary counts the comments posted during dateRange and finds
+// recurring terms among the most recent ones.
+func (b *PromptBuilder) commentSummary(ctx context.Context, platform data.Platform, dateRange data.DateRange) (CommentSummary, error) {
+	threads, _, err := b.store.ListInboxComments(ctx, data.InboxFilter{
+		Platform:        platform,
+		IncludeArchived: true,
+		Limit:           promptCommentSample,
+	})
+	if err != nil {
+		return CommentSummary{}, fmt.Errorf("listing comments: %w", err)
+	}
+
+	var summary CommentSummary
+	counts := make(map[string]int)
+	examples := make(map[string]string)
+	var visit func(c *data.InboxComment)
+	visit = func(c *data.InboxComment) {
+		for _, reply := range c.Replies {
+			visit(reply)
+		}
+		if c.CreatedAt.Before(dateRange.Start) || !c.CreatedAt.Before(dateRange.End) {
+			return
+		}
+		summary.Total++
+		if c.Intent == data.IntentQuestion || strings.Contains(c.Text, "?") {
+			summary.Questions++
+		}
+		switch c.Sentiment {
+		case data.SentimentPositive:
+			summary.Positive++
+		case data.SentimentNegative:
+			summary.Negative++
+		}
+		for term := range significantTerms(c.Text) {
+			counts[term]++
+			if e, ok := examples[term]; !ok || len(c.Text) < len(e) {
+				examples[term] = c.Text
+			}
+		}
+	}
+	for _, thread := range threads {
+		visit(thread)
+	}
+
+	for term, count := range counts {
+		if count >= 2 {
+			summary.Themes = append(summary.Themes, CommentTheme{Term: term, Count: count, Example: examples[term]})
+		}
+	}
+	sort.Slice(summary.Themes, func(i, j int) bool {
+		x, y := summary.Themes[i], summary.Themes[j]
+		if x.Count != y.Count {
+			return x.Count > y.Count
+		}
+		return x.Term < y.Term
+	})
+	if len(summary.Themes) > promptThemeLimit {
+		summary.Themes = summary.Themes[:promptThemeLimit]
+	}
+	return summary, nil
+}
+
+// significantTerms returns the distinct meaningful words in text.
+func significantTerms(text string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		word = strings.Trim(word, "'")
+		if utf8.RuneCountInString(word) >= 4 && !stopWords[word] {
+			terms[word] = true
+		}
+	}
+	return terms
+}
+
+// stopWords are common words that don't make themes.
+var stopWords = map[string]bool{
+	"about": true, "after": true, "again": true, "also": true, "been": true,
+	"being": true, "could": true, "does": true, "doing": true, "don't": true,
+	"even": true, "from": true, "have": true, "here": true, "just": true,
+	"know": true, "like": true, "make": true, "more": true, "much": true,
+	"only": true, "other": true, "really": true, "should": true, "some": true,
+	"than": true, "that": true, "that's": true, "their": true, "them": true,
+	"then": true, "there": true, "these": true, "they": true, "thing": true,
+	"think": true, "this": true, "those": true, "very": true, "want": true,
+	"what": true, "when": true, "where": true, "which": true, "will": true,
+	"with": true, "would": true, "your": true, "you're": true, "thanks": true,
+	"great": true, "good": true, "video": true, "post": true,
+}
+
+// periodLabel describes a date range, whose end is exclusive.
+func periodLabel(r data.DateRange) string {
+	last := r.End.Add(-time.Nanosecond)
+	return r.Start.Format("Jan 2, 2006") + " to " + last.Format("Jan 2, 2006")
+}
+
+// formatNumber formats a count compactly, e.g. 1234567 as "1.2M".
+func formatNumber(value interface{}) string {
+	var v float64
+	switch n := value.(type) {
+	case int:
+		v = float64(n)
+	case int64:
+		v = float64(n)
+	case float64:
+		v = n
+	default:
+		return fmt.Sprint(value)
+	}
+	switch abs := math.Abs(v); {
+	case abs >= 1e9:
+		return fmt.Sprintf("%.1fB", v/1e9)
+	case abs >= 1e6:
+		return fmt.Sprintf("%.1fM", v/1e6)
+	case abs >= 1e4:
+		return fmt.Sprintf("%.1fK", v/1e3)
+	case v == math.Trunc(v):
+		return fmt.Sprintf("%.0f", v)
+	default:
+		return fmt.Sprintf("%.1f", v)
+	}
+}
+
+// snippet returns text on one line, cut to at most n characters.
+func snippet(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:max(n-3, 0)])) + "..."
+}
+
+// samplePromptData is used to check that edited templates render.
+func samplePromptData() *PromptData {
+	now := time.Now()
+	period := NewPeriod(now.AddDate(0, 0, -7), now, true)
+	change := NewDelta(120, 100)
+	item := &data.ContentItem{Platform: data.PlatformYouTube, ID: "sample", Title: "Sample video", PublishedAt: now, Views: 1000, EngagementRate: 0.05}
+	return &PromptData{
+		Period:      period,
+		PeriodLabel: periodLabel(period.Current),
+		Platform:    data.PlatformYouTube,
+		Metrics: []PromptMetric{
+			{Key: "youtube.views", Label: "YouTube views", Value: 120, Delta: change},
+		},
+		Trends:        []*data.TrendData{{Platform: data.PlatformYouTube, Metric: "views", Trend: "up", ChangePercent: 20}},
+		TopContent:    []*data.ContentItem{item},
+		BottomContent: []*data.ContentItem{item},
+		Anomalies: []PromptAnomaly{
+			{Platform: data.PlatformYouTube, Metric: "views", Label: "YouTube views", Date: now, Value: 500, Expected: 100},
+		},
+		Goals: []*GoalProgress{{
+			Goal:         &data.Goal{Platform: data.PlatformYouTube, Metric: "subscribers", Target: 50000, Deadline: now.AddDate(0, 3, 0)},
+			Current:      42000,
+			DaysLeft:     90,
+			RequiredRate: 89,
+			ActualRate:   70,
+			Status:       GoalAtRisk,
+		}},
+		Comments: CommentSummary{
+			Total:     3,
+			Questions: 1,
+			Positive:  2,
+			Negative:  1,
+			Themes:    []CommentTheme{{Term: "tutorial", Count: 2, Example: "More tutorials please?"}},
+		},
+		Feedback: PromptFeedback{
+			Liked:    []*data.Insight{{Type: data.InsightTypeRecommendation, Title: "Post tutorials on Tuesdays"}},
+			Disliked: []*data.Insight{{Type: data.InsightTypeSummary, Title: "Views were steady"}},
+		},
+	}
+}
@@ -0,0 +1,445 @@
+// Package insights provides the read-only queries the analytics chat can
+// run against stored data.
+package insights
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// QueryStore is the part of storage.Store the chat queries use. It has no
+// write methods, so a model can't change data however it calls the tools.
+type QueryStore interface {
+	ListContent(ctx context.Context, query data.ContentQuery) ([]*data.ContentItem, int, error)
+	GetAnalyticsSummary(ctx context.Context, dateRange data.DateRange) (*data.AnalyticsSummary, error)
+	GetTrendData(ctx context.Context, platform data.Platform, metric string, days int) (*data.TrendData, error)
+	Search(ctx context.Context, query data.SearchQuery) ([]*data.SearchResult, error)
+}
+
+// Chat query limits.
+const (
+	queryRowLimit    = 20  // Most rows a query may return
+	queryDefaultRows = 10  // Rows returned when the model doesn't say
+	queryMaxDays     = 366 // Longest trend a query may request
+)
+
+// queryDateLayout is the date format of query arguments.
+const queryDateLayout = "2006-01-02"
+
+// Tool is a query the chat model may call.
+type Tool struct {
+	Name        string
+	Description string
+	Arguments   string // The JSON arguments, described for the model
+}
+
+// Chat query names.
+const (
+	ToolListContent    = "list_content"
+	ToolGetTrend       = "get_trend"
+	ToolComparePeriods = "compare_periods"
+	ToolSearchComments = "search_comments"
+)
+
+// queryTools lists the chat queries.
+var queryTools = []Tool{
+	{
+		Name:        ToolListContent,
+		Description: "List videos, tweets and LinkedIn posts with their metrics, optionally filtered by platform and publish date, sorted by date, views or engagement rate.",
+		Arguments:   `{"platform": "youtube" | "x" | "linkedin" (optional), "start": "YYYY-MM-DD" (optional), "end": "YYYY-MM-DD" inclusive (optional), "sort": "date" | "views" | "engagement", "ascending": false, "limit": 1-20}`,
+	},
+	{
+		Name:        ToolGetTrend,
+		Description: "Get the daily trend of an account metric over the last days: direction, change, range and first and last values.",
+		Arguments:   `{"platform": "youtube" | "x" | "linkedin", "metric": "views" (youtube) | "impressions" (x, linkedin) | "engagement_rate", "days": 1-366}`,
+	},
+	{
+		Name:        ToolComparePeriods,
+		Description: "Compare the headline metrics of every platform (reach, engagement rate, audience growth) between a date range and the equally long range before it, or a given previous range.",
+		Arguments:   `{"start": "YYYY-MM-DD", "end": "YYYY-MM-DD" inclusive, "previous_start": "YYYY-MM-DD" (optional), "previous_end": "YYYY-MM-DD" (optional)}`,
+	},
+	{
+		Name:        ToolSearchComments,
+		Description: `Full-text search of audience comments. Use "quotes" for phrases and a trailing * for prefixes; all terms must match.`,
+		Arguments:   `{"query": "text", "platform": "youtube" | "x" | "linkedin" (optional), "limit": 1-20}`,
+	},
+}
+
+// QueryTools runs the chat queries against a QueryStore.
+type QueryTools struct {
+	store QueryStore
+	loc   *time.Location // Location of query dates
+}
+
+// NewQueryTools creates QueryTools reading from store, interpreting dates
+// in the local time zone.
+func NewQueryTools(store QueryStore) *QueryTools {
+	return &QueryTools{store: store, loc: time.Local}
+}
+
+// Tools returns the available queries.
+func (t *QueryTools) Tools() []Tool {
+	return queryTools
+}
+
+// errInvalidArguments marks query errors the model can fix by calling the
+// tool again with different arguments.
+var errInvalidArguments = errors.New("invalid arguments")
+
+// Call runs the named query. Unknown tools and invalid arguments are
+// reported in the returned call's Error, so the model can correct them;
+// the error is for storage failures.
+func (t *QueryTools) Call(ctx context.Context, name string, args json.RawMessage) (*data.ChatToolCall, error) {
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage(`{}`)
+	}
+	call := &data.ChatToolCall{Name: name, Arguments: args}
+
+	var rows []*data.ChatRow
+	var err error
+	switch name {
+	case ToolListContent:
+		rows, err = t.listContent(ctx, args)
+	case ToolGetTrend:
+		rows, err = t.getTrend(ctx, args)
+	case ToolComparePeriods:
+		rows, err = t.comparePeriods(ctx, args)
+	case ToolSearchComments:
+		rows, err = t.searchComments(ctx, args)
+	default:
+		err = fmt.Errorf("%w: unknown tool %q", errInvalidArguments, name)
+	}
+	if errors.Is(err, errInvalidArguments) {
+		call.Error = strings.TrimPrefix(err.Error(), errInvalidArguments.Error()+": ")
+		return call, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("running %s: %w", name, err)
+	}
+	call.Rows = rows
+	return call, nil
+}
+
+func (t *QueryTools) listContent(ctx context.Context, raw json.RawMessage) ([]*data.ChatRow, error) {
+	var args struct {
+		Platform  data.Platform    `json:"platform"`
+		Start     string           `json:"start"`
+		End       string           `json:"end"`
+		Sort      data.ContentSort `json:"sort"`
+		Ascending bool             `json:"ascending"`
+		Limit     int              `json:"limit"`
+	}
+	if err := decodeArguments(raw, &args); err != nil {
+		return nil, err
+	}
+	if err := checkPlatform(args.Platform, true); err != nil {
+		return nil, err
+	}
+	if args.Sort == "" {
+		args.Sort = data.SortByDate
+	}
+	if !args.Sort.Valid() {
+		return nil, fmt.Errorf(`%w: sort must be "date", "views" or "engagement"`, errInvalidArguments)
+	}
+	dateRange, err := t.dateRange(args.Start, args.End, true)
+	if err != nil {
+		return nil, err
+	}
+
+	items, _, err := t.store.ListContent(ctx, data.ContentQuery{
+		Platform:  args.Platform,
+		DateRange: dateRange,
+		Sort:      args.Sort,
+		Ascending: args.Ascending,
+		Limit:     rowLimit(args.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*data.ChatRow, len(items))
+	for i, item := range items {
+		title := item.Title
+		if title == "" {
+			title = snippet(item.Body, 100)
+		}
+		rows[i] = &data.ChatRow{
+			Ref:      fmt.Sprintf("content:%s:%s", item.Platform, item.ID),
+			Kind:     "content",
+			Platform: item.Platform,
+			Title:    title,
+			Date:     item.PublishedAt,
+			Link:     fmt.Sprintf("/content/%s/%s", item.Platform, item.ID),
+			Fields: map[string]string{
+				"views":           fmt.Sprint(item.Views),
+				"likes":           fmt.Sprint(item.Likes),
+				"comments":        fmt.Sprint(item.Comments),
+				"shares":          fmt.Sprint(item.Shares),
+				"engagement_rate": fmt.Sprintf("%.2f%%", item.EngagementRate*100),
+			},
+		}
+	}
+	return rows, nil
+}
+
+func (t *QueryTools) getTrend(ctx context.Context, raw json.RawMessage) ([]*data.ChatRow, error) {
+	var args struct {
+		Platform data.Platform `json:"platform"`
+		Metric   string        `json:"metric"`
+		Days     int           `json:"days"`
+	}
+	if err := decodeArguments(raw, &args); err != nil {
+		return nil, err
+	}
+	if err := checkPlatform(args.Platform, false); err != nil {
+		return nil, err
+	}
+	if args.Metric == "" {
+		args.Metric = primaryMetric(args.Platform)
+	}
+	if args.Metric != primaryMetric(args.Platform) && args.Metric != "engagement_rate" {
+		return nil, fmt.Errorf(`%w: metric for %s must be %q or "engagement_rate"`,
+			errInvalidArguments, args.Platform, primaryMetric(args.Platform))
+	}
+	if args.Days <= 0 {
+		args.Days = 30
+	}
+	args.Days = min(args.Days, queryMaxDays)
+
+	trend, err := t.store.GetTrendData(ctx, args.Platform, args.Metric, args.Days)
+	if err != nil {
+		return nil, err
+	}
+	if trend == nil || len(trend.Points) == 0 {
+		return nil, nil
+	}
+
+	direction, change := calculateTrendMetrics(trend.Points)
+	first, last := trend.Points[0], trend.Points[len(trend.Points)-1]
+	low, high := first.Value, first.Value
+	for _, p := range trend.Points {
+		low, high = min(low, p.Value), max(high, p.Value)
+	}
+	format := func(v float64) string {
+		if args.Metric == "engagement_rate" {
+			return fmt.Sprintf("%.2f%%", v*100)
+		}
+		return fmt.Sprintf("%.0f", v)
+	}
+	return []*data.ChatRow{{
+		Ref:      fmt.Sprintf("trend:%s:%s", args.Platform, args.Metric),
+		Kind:     "trend",
+		Platform: args.Platform,
+		Title:    fmt.Sprintf("%s %s over %d days", args.Platform, args.Metric, args.Days),
+		Date:     last.Timestamp,
+		Fields: map[string]string{
+			"direction":      direction,
+			"change_percent": fmt.Sprintf("%+.1f%%", change),
+			"first":          first.Timestamp.Format(queryDateLayout) + ": " + format(first.Value),
+			"last":           last.Timestamp.Format(queryDateLayout) + ": " + format(last.Value),
+			"min":            format(low),
+			"max":            format(high),
+			"days":           fmt.Sprint(len(trend.Points)),
+		},
+	}}, nil
+}
+
+func (t *QueryTools) comparePeriods(ctx context.Context, raw json.RawMessage) ([]*data.ChatRow, error) {
+	var args struct {
+		Start         string `json:"start"`
+		End           string `json:"end"`
+		PreviousStart string `json:"previous_start"`
+		PreviousEnd   string `json:"previous_end"`
+	}
+	if err := decodeArguments(raw, &args); err != nil {
+		return nil, err
+	}
+	current, err := t.dateRange(args.Start, args.End, false)
+	if err != nil {
+		return nil, err
+	}
+	previous := NewPeriod(current.Start, current.End, true).Previous
+	if args.PreviousStart != "" || args.PreviousEnd != "" {
+		custom, err := t.dateRange(args.PreviousStart, args.PreviousEnd, false)
+		if err != nil {
+			return nil, fmt.Errorf("%w: previous range: %s", errInvalidArguments,
+				strings.TrimPrefix(err.Error(), errInvalidArguments.Error()+": "))
+		}
+		previous = &custom
+	}
+
+	summary, err := t.store.GetAnalyticsSummary(ctx, current)
+	if err != nil {
+		return nil, err
+	}
+	previousSummary, err := t.store.GetAnalyticsSummary(ctx, *previous)
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := SummaryDeltas(summary, previousSummary)
+	var rows []*data.ChatRow
+	for _, m := range summaryMetrics(summary) {
+		row := &data.ChatRow{
+			Ref:      "metric:" + m.Key,
+			Kind:     "metric",
+			Platform: data.Platform(strings.SplitN(m.Key, ".", 2)[0]),
+			Title:    m.Label,
+			Date:     current.Start,
+			Fields: map[string]string{
+				"period":  periodLabel(current),
+				"current": m.Formatted(),
+			},
+		}
+		if d := deltas[m.Key]; d != nil {
+			prev := PromptMetric{Value: d.Previous, Rate: m.Rate}
+			row.Fields["previous_period"] = periodLabel(*previous)
+			row.Fields["previous"] = prev.Formatted()
+			row.Fields["change"] = d.Label()
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (t *QueryTools) searchComments(ctx context.Context, raw json.RawMessage) ([]*data.ChatRow, error) {
+	var args struct {
+		Query    string        `json:"query"`
+		Platform data.Platform `json:"platform"`
+		Limit    int           `json:"limit"`
+	}
+	if err := decodeArguments(raw, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return nil, fmt.Errorf("%w: query is required", errInvalidArguments)
+	}
+	if err := checkPlatform(args.Platform, true); err != nil {
+		return nil, err
+	}
+
+	results, err := t.store.Search(ctx, data.SearchQuery{
+		Text:     args.Query,
+		Platform: args.Platform,
+		Kind:     data.SearchKindComment,
+		Limit:    rowLimit(args.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*data.ChatRow, len(results))
+	for i, r := range results {
+		rows[i] = &data.ChatRow{
+			Ref:      fmt.Sprintf("comment:%s:%s", r.Platform, r.ID),
+			Kind:     "comment",
+			Platform: r.Platform,
+			Title:    r.Title,
+			Date:     r.Date,
+			Link:     fmt.Sprintf("/content/%s/%s", r.Platform, r.ContentID),
+			Fields:   map[string]string{"text": plainSnippet(r.Snippet)},
+		}
+	}
+	return rows, nil
+}
+
+// decodeArguments decodes tool arguments into v, rejecting unknown fields
+// so misspelled arguments aren't silently ignored.
+func decodeArguments(raw json.RawMessage, v interface{}) error {
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidArguments, err)
+	}
+	return nil
+}
+
+// checkPlatform validates a platform argument, which may be empty if
+// optional.
+func checkPlatform(platform data.Platform, optional bool) error {
+	switch platform {
+	case data.PlatformYouTube, data.PlatformX, data.PlatformLinkedIn:
+		return nil
+	case "":
+		if optional {
+			return nil
+		}
+		return fmt.Errorf("%w: platform is required", errInvalidArguments)
+	}
+	return fmt.Errorf(`%w: platform must be "youtube", "x" or "linkedin"`, errInvalidArguments)
+}
+
+// dateRange parses inclusive start and end dates into a range ending at
+// the start of the day after end. If optional, missing dates leave the
+// range open on that side; otherwise both are required.
+func (t *QueryTools) dateRange(start, end string, optional bool) (data.DateRange, error) {
+	var r data.DateRange
+	if !optional && (start == "" || end == "") {
+		return r, fmt.Errorf("%w: start and end dates are required", errInvalidArguments)
+	}
+	if start != "" {
+		s, err := time.ParseInLocation(queryDateLayout, start, t.loc)
+		if err != nil {
+			return r, fmt.Errorf("%w: start must be a YYYY-MM-DD date", errInvalidArguments)
+		}
+		r.Start = s
+	}
+	if end != "" {
+		e, err := time.ParseInLocation(queryDateLayout, end, t.loc)
+		if err != nil {
+			return r, fmt.Errorf("%w: end must be a YYYY-MM-DD date", errInvalidArguments)
+		}
+		r.End = e.AddDate(0, 0, 1)
+	}
+	switch {
+	case !r.Start.IsZero() && r.End.IsZero():
+		r.End = time.Now().In(t.loc).AddDate(0, 0, 1)
+	case r.Start.IsZero() && !r.End.IsZero():
+		r.Start = time.Unix(0, 0).In(t.loc)
+	}
+	if !r.End.After(r.Start) {
+		return r, fmt.Errorf("%w: end must not be before start", errInvalidArguments)
+	}
+	return r, nil
+}
+
+// rowLimit clamps a requested row count.
+func rowLimit(limit int) int {
+	if limit <= 0 {
+		return queryDefaultRows
+	}
+	return min(limit, queryRowLimit)
+}
+
+// plainSnippet converts a search snippet's HTML to plain text.
+func plainSnippet(snippetHTML string) string {
+	text := strings.NewReplacer("<mark>", "", "</mark>", "").Replace(snippetHTML)
+	return html.UnescapeString(text)
+}
+
+// formatRows writes query rows compactly for a prompt, one per line.
+func formatRows(b *strings.Builder, rows []*data.ChatRow) {
+	for _, row := range rows {
+		fmt.Fprintf(b, "  [%s] %s", row.Ref, row.Title)
+		if !row.Date.IsZero() {
+			fmt.Fprintf(b, " (%s)", row.Date.Format(queryDateLayout))
+		}
+		keys := make([]string, 0, len(row.Fields))
+		for k := range row.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(b, "; %s=%s", k, row.Fields[k])
+		}
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,350 @@
+// Package insights provides structured JSON output from the LLM, with
+// validation, repair and retries.
+package insights
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// structuredAttempts is how many times a prompt is sent before giving up on
+// getting valid JSON. Retries include the validation errors so the model
+// can correct itself.
+const structuredAttempts = 3
+
+// errNoValidOutput is returned when no attempt produced usable output.
+var errNoValidOutput = errors.New("LLM returned no valid output")
+
+// insightSchema is the JSON schema requested for analytics insights.
+var insightSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "insights": {
+      "type": "array",
+      "minItems": 1,
+      "items": {
+        "type": "object",
+        "properties": {
+          "title": {"type": "string"},
+          "type": {"type": "string", "enum": ["trend", "recommendation", "alert", "summary"]},
+          "platform": {"type": "string", "enum": ["youtube", "x", "linkedin", ""]},
+          "description": {"type": "string"},
+          "confidence": {"type": "number", "minimum": 0, "maximum": 1},
+          "evidence": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {
+                "metric": {"type": "string"},
+                "platform": {"type": "string"},
+                "value": {"type": "number"},
+                "change_pct": {"type": "number"}
+              },
+              "required": ["metric", "value"]
+            }
+          },
+          "actions": {"type": "array", "items": {"type": "string"}}
+        },
+        "required": ["title", "type", "platform", "description", "confidence", "evidence", "actions"]
+      }
+    }
+  },
+  "required": ["insights"]
+}`)
+
+// insightInstructions describes insightSchema in the prompt, for backends
+// that don't enforce the schema.
+const insightInstructions = `Respond with only a JSON object, no other text, in this form:
+{"insights": [{
+  "title": "short headline",
+  "type": "trend" | "recommendation" | "alert" | "summary",
+  "platform": "youtube" | "x" | "linkedin" | "" (empty for cross-platform),
+  "description": "two or three sentences",
+  "confidence": number from 0 to 1,
+  "evidence": [{"metric": "metric name", "platform": "youtube", "value": 123, "change_pct": -4.5}],
+  "actions": ["specific next step"]
+}]}
+Return between 2 and 5 insights, each citing the metrics it is based on.`
+
+// suggestionSchema is the JSON schema requested for content suggestions.
+var suggestionSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "suggestions": {"type": "array", "minItems": 1, "items": {"type": "string"}}
+  },
+  "required": ["suggestions"]
+}`)
+
+// suggestionInstructions describes suggestionSchema in the prompt.
+const suggestionInstructions = `Respond with only a JSON object, no other text, in this form:
+{"suggestions": ["first idea", "second idea", "third idea"]}`
+
+// GeneratedInsight is an insight with the evidence and actions the LLM
+// gave for it.
+type GeneratedInsight struct {
+	Insight *data.Insight
+	Detail  *data.InsightDetail
+}
+
+// insightOutput is the response format described by insightSchema.
+type insightOutput struct {
+	Insights []insightItem `json:"insights"`
+}
+
+type insightItem struct {
+	Title       string                 `json:"title"`
+	Type        string                 `json:"type"`
+	Platform    string                 `json:"platform"`
+	Description string                 `json:"description"`
+	Confidence  float64                `json:"confidence"`
+	Evidence    []data.InsightEvidence `json:"evidence"`
+	Actions     []string               `json:"actions"`
+}
+
+// RetryFunc is called before a streamed response is retried because the
+// previous one couldn't be used, so the receiver can discard the tokens it
+// was sent for it. attempt counts from 2.
+type RetryFunc func(attempt int, problem error) error
+
+// generateJSON sends prompt with a schema and passes the response to
+// decode, retrying with decode's error until it succeeds or the attempts
+// run out. LLM errors are returned immediately. If onToken is non-nil the
+// responses are streamed to it, including those of retries, and onRetry,
+// if non-nil, is called before each retry.
+func (c *LLMClient) generateJSON(ctx context.Context, prompt string, schema json.RawMessage, onToken StreamFunc, onRetry RetryFunc, decode func(text string) error) error {
+	req := &GenerateRequest{Prompt: prompt, Schema: schema}
+	var lastErr error
+	for attempt := 1; attempt <= structuredAttempts; attempt++ {
+		if attempt > 1 && onToken != nil && onRetry != nil {
+			if err := onRetry(attempt, lastErr); err != nil {
+				return err
+			}
+		}
+		var resp *GenerateResponse
+		var err error
+		if onToken != nil {
+			resp, err = c.llm.Stream(ctx, req, onToken)
+		} else {
+			resp, err = c.llm.Generate(ctx, req)
+		}
+		if err != nil {
+			return err
+		}
+		if lastErr = decode(resp.Text); lastErr == nil {
+			return nil
+		}
+		req = &GenerateRequest{
+			Prompt: repairPrompt(prompt, resp.Text, lastErr),
+			Schema: schema,
+		}
+	}
+	return fmt.Errorf("%w after %d attempts: %v", errNoValidOutput, structuredAttempts, lastErr)
+}
+
+// repairPrompt asks the model to correct an invalid response.
+func repairPrompt(prompt, response string, problem error) string {
+	if len(response) > 2000 {
+		response = response[:2000] + "..."
+	}
+	return fmt.Sprintf(`%s
+
+Your previous response could not be used:
+%s
+
+Previous response:
+%s
+
+Respond again with only the corrected JSON.`, prompt, problem, response)
+}
+
+// decodeInsights parses and validates an insight response. Invalid
+// insights are dropped; an error is returned only if none are valid.
+func decodeInsights(text string) ([]insightItem, error) {
+	raw, err := extractJSON(text)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accept a bare array or a single insight as well as the requested form.
+	var output insightOutput
+	if err := json.Unmarshal(raw, &output); err != nil || len(output.Insights) == 0 {
+		var items []insightItem
+		var item insightItem
+		switch {
+		case json.Unmarshal(raw, &items) == nil && len(items) > 0:
+			output.Insights = items
+		case json.Unmarshal(raw, &item) == nil && item.Title != "":
+			output.Insights = []insightItem{item}
+		case err != nil:
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		default:
+			return nil, errors.New(`"insights" must be a non-empty array`)
+		}
+	}
+
+	var valid []insightItem
+	var problems []string
+	for i, item := range output.Insights {
+		if problem := item.normalize(); problem != "" {
+			problems = append(problems, fmt.Sprintf("insight %d: %s", i+1, problem))
+			continue
+		}
+		valid = append(valid, item)
+	}
+	if len(valid) == 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+	return valid, nil
+}
+
+// normalize repairs common deviations from the schema in place and
+// returns a description of any problem it can't repair.
+func (item *insightItem) normalize() string {
+	item.Title = strings.TrimSpace(item.Title)
+	item.Description = strings.TrimSpace(item.Description)
+	if item.Title == "" {
+		return "title is required"
+	}
+	if item.Description == "" {
+		return "description is required"
+	}
+
+	item.Type = strings.ToLower(strings.TrimSpace(item.Type))
+	if !data.ValidInsightType(item.Type) {
+		// Tolerate plurals such as "recommendations".
+		if singular := strings.TrimSuffix(item.Type, "s"); data.ValidInsightType(singular) {
+			item.Type = singular
+		} else {
+			return fmt.Sprintf("type %q must be one of %s", item.Type, strings.Join(data.InsightTypes, ", "))
+		}
+	}
+
+	platform, ok := normalizePlatform(item.Platform)
+	if !ok {
+		return fmt.Sprintf(`platform %q must be "youtube", "x", "linkedin" or ""`, item.Platform)
+	}
+	item.Platform = platform
+
+	// Models sometimes answer with a percentage.
+	if item.Confidence > 1 && item.Confidence <= 100 {
+		item.Confidence /= 100
+	}
+	item.Confidence = min(max(item.Confidence, 0), 1)
+
+	evidence := item.Evidence[:0]
+	for _, e := range item.Evidence {
+		e.Metric = strings.TrimSpace(e.Metric)
+		if e.Metric == "" {
+			continue
+		}
+		if p, ok := normalizePlatform(string(e.Platform)); ok {
+			e.Platform = data.Platform(p)
+		} else {
+			e.Platform = ""
+		}
+		evidence = append(evidence, e)
+	}
+	item.Evidence = evidence
+
+	actions := item.Actions[:0]
+	for _, action := range item.Actions {
+		if action = strings.TrimSpace(action); action != "" {
+			actions = append(actions, action)
+		}
+	}
+	item.Actions = actions
+	return ""
+}
+
+// normalizePlatform maps the names models use for platforms to
+// data.Platform values, with "" for cross-platform.
+func normalizePlatform(name string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "all", "overall", "cross-platform", "cross platform", "none":
+		return "", true
+	case "youtube":
+		return string(data.PlatformYouTube), true
+	case "x", "twitter", "x (twitter)":
+		return string(data.PlatformX), true
+	case "linkedin":
+		return string(data.PlatformLinkedIn), true
+	}
+	return "", false
+}
+
+// decodeSuggestions parses a content suggestion response.
+func decodeSuggestions(text string) ([]string, error) {
+	raw, err := extractJSON(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var output struct {
+		Suggestions []string `json:"suggestions"`
+	}
+	if err := json.Unmarshal(raw, &output); err != nil {
+		// Accept a bare array of strings.
+		if json.Unmarshal(raw, &output.Suggestions) != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	}
+
+	var suggestions []string
+	for _, s := range output.Suggestions {
+		if s = strings.TrimSpace(s); s != "" {
+			suggestions = append(suggestions, s)
+		}
+	}
+	if len(suggestions) == 0 {
+		return nil, errors.New(`"suggestions" must be a non-empty array of strings`)
+	}
+	return suggestions, nil
+}
+
+// listItem matches a bulleted or numbered list line.
+var listItem = regexp.MustCompile(`^\s*(?:[-*\x{2022}]|\d+[.)])\s+(.+)$`)
+
+// splitList returns the items of a Markdown list in text, or nil if text
+// contains no list.
+func splitList(text string) []string {
+	var items []string
+	for _, line := range strings.Split(text, "\n") {
+		if m := listItem.FindStringSubmatch(line); m != nil {
+			items = append(items, strings.TrimSpace(m[1]))
+		}
+	}
+	return items
+}
+
+// trailingComma matches a comma before a closing bracket, which models
+// often emit and JSON forbids.
+var trailingComma = regexp.MustCompile(`,\s*([}\]])`)
+
+// extractJSON returns the JSON value in an LLM response, tolerating
+// Markdown code fences, surrounding prose and trailing commas.
+func extractJSON(text string) (json.RawMessage, error) {
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return nil, errors.New("response contains no JSON")
+	}
+	closing := "}"
+	if text[start] == '[' {
+		closing = "]"
+	}
+	end := strings.LastIndex(text, closing)
+	if end < start {
+		return nil, errors.New("response contains incomplete JSON")
+	}
+
+	raw := text[start : end+1]
+	if !json.Valid([]byte(raw)) {
+		raw = trailingComma.ReplaceAllString(raw, "$1")
+	}
+	return json.RawMessage(raw), nil
+}
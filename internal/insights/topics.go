@@ -0,0 +1,456 @@
+// Package insights provides topic discovery by clustering the embeddings
+// of content and comments.
+package insights
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/omnipulse/omnipulse/internal/config"
+	"github.com/omnipulse/omnipulse/internal/data"
+	"github.com/omnipulse/omnipulse/internal/storage"
+)
+
+const (
+	// embedBatchSize is the number of texts embedded per request, and
+	// embedBatchesPerRun limits the batches EmbedPending handles at once.
+	embedBatchSize     = 32
+	embedBatchesPerRun = 20
+
+	// embedTextLimit cuts long content before embedding; the start of a
+	// post says most about its topic.
+	embedTextLimit = 1000
+
+	// minTopicSize is the smallest cluster kept as a topic. Smaller ones
+	// are usually unrelated outliers.
+	minTopicSize = 3
+
+	// kmeansIterations limits the refinement of clusters.
+	kmeansIterations = 25
+
+	topicKeywordCount   = 5   // Keywords kept per topic
+	topicLabelExamples  = 5   // Examples per topic in the labeling prompt
+	topicExampleLimit   = 200 // Characters per example in the labeling prompt
+	topicLabelMaxLength = 60
+)
+
+// topicLabelSchema is the JSON schema requested for topic labels.
+var topicLabelSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "topics": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "n": {"type": "integer"},
+          "label": {"type": "string"}
+        },
+        "required": ["n", "label"]
+      }
+    }
+  },
+  "required": ["topics"]
+}`)
+
+// topicLabelInstructions describes topicLabelSchema in the prompt.
+const topicLabelInstructions = `Respond with only a JSON object, no other text, in this form:
+{"topics": [{"n": group number, "label": "short topic label"}]}
+Label every group with 2 to 5 words naming what it is about, such as
+"Home espresso gear" or "Remote work burnout". Don't start with "Topic".`
+
+// TopicModeler embeds content and comments and groups them into topics.
+type TopicModeler struct {
+	store    storage.Store
+	embedder Embedder
+	llm      *LLMClient // nil to label topics with their keywords
+	count    int
+}
+
+// NewTopicModeler creates a TopicModeler. llm may be nil.
+func NewTopicModeler(store storage.Store, embedder Embedder, llm *LLMClient, cfg config.InsightsConfig) *TopicModeler {
+	count := cfg.TopicCount
+	if count <= 0 {
+		count = 8
+	}
+	return &TopicModeler{store: store, embedder: embedder, llm: llm, count: count}
+}
+
+// Run embeds new content and comments and regroups all of them into
+// topics. It is meant to run as a scheduled task.
+func (m *TopicModeler) Run(ctx context.Context) error {
+	if _, err := m.EmbedPending(ctx); err != nil {
+		return err
+	}
+	_, err := m.Cluster(ctx)
+	return err
+}
+
+// EmbedPending embeds the newest content and comments that have no
+// embedding from the current model and returns how many it embedded.
+func (m *TopicModeler) EmbedPending(ctx context.Context) (int, error) {
+	model := m.embedder.EmbeddingModel()
+	sources, err := m.store.ListUnembedded(ctx, model, embedBatchSize*embedBatchesPerRun)
+	if err != nil {
+		return 0, fmt.Errorf("listing unembedded items: %w", err)
+	}
+
+	embedded := 0
+	for start := 0; start < len(sources); start += embedBatchSize {
+		batch := sources[start:min(start+embedBatchSize, len(sources))]
+		texts := make([]string, len(batch))
+		for i, src := range batch {
+			texts[i] = snippet(src.Text, embedTextLimit)
+		}
+
+		vectors, err := m.embedder.Embed(ctx, texts)
+		if err != nil {
+			return embedded, fmt.Errorf("embedding %d items: %w", len(batch), err)
+		}
+
+		embeddings := make([]*data.Embedding, len(batch))
+		for i, src := range batch {
+			embeddings[i] = &data.Embedding{
+				EmbeddingSource: data.EmbeddingSource{Kind: src.Kind, Platform: src.Platform, ItemID: src.ItemID, Text: texts[i]},
+				Model:           model,
+				Vector:          vectors[i],
+			}
+		}
+		if err := m.store.SaveEmbeddings(ctx, embeddings); err != nil {
+			return embedded, fmt.Errorf("saving embeddings: %w", err)
+		}
+		embedded += len(batch)
+	}
+	return embedded, nil
+}
+
+// Cluster groups all embeddings from the current model into at most the
+// configured number of topics, labels them and replaces the saved topics.
+// Existing topics are kept if there are too few embeddings to cluster.
+func (m *TopicModeler) Cluster(ctx context.Context) ([]*data.Topic, error) {
+	embeddings, err := m.store.ListEmbeddings(ctx, m.embedder.EmbeddingModel())
+	if err != nil {
+		return nil, fmt.Errorf("listing embeddings: %w", err)
+	}
+	if len(embeddings) < minTopicSize {
+		return nil, nil
+	}
+
+	vectors := make([][]float64, len(embeddings))
+	for i, e := range embeddings {
+		vectors[i] = unitVector(e.Vector)
+	}
+	k := min(m.count, max(len(embeddings)/minTopicSize, 1))
+	assignments, centroids := kmeans(vectors, k)
+
+	clusters := make([][]int, k)
+	for i, c := range assignments {
+		clusters[c] = append(clusters[c], i)
+	}
+
+	docFreq := make(map[string]int)
+	terms := make([]map[string]bool, len(embeddings))
+	for i, e := range embeddings {
+		terms[i] = significantTerms(e.Text)
+		for t := range terms[i] {
+			docFreq[t]++
+		}
+	}
+
+	type cluster struct {
+		topic    *data.Topic
+		examples []string
+	}
+	var found []cluster
+	for c, members := range clusters {
+		if len(members) < minTopicSize {
+			continue
+		}
+		similarity := make(map[int]float64, len(members))
+		for _, i := range members {
+			similarity[i] = dot(vectors[i], centroids[c])
+		}
+		sort.Slice(members, func(a, b int) bool { return similarity[members[a]] > similarity[members[b]] })
+
+		cl := cluster{topic: &data.Topic{
+			Keywords: topicKeywords(members, terms, docFreq, len(embeddings)),
+			Size:     len(members),
+		}}
+		for n, i := range members {
+			e := embeddings[i]
+			cl.topic.Members = append(cl.topic.Members, &data.TopicMember{
+				Kind:       e.Kind,
+				Platform:   e.Platform,
+				ItemID:     e.ItemID,
+				Similarity: similarity[i],
+			})
+			if n < topicLabelExamples {
+				cl.examples = append(cl.examples, e.Text)
+			}
+		}
+		found = append(found, cl)
+	}
+	sort.SliceStable(found, func(a, b int) bool { return found[a].topic.Size > found[b].topic.Size })
+
+	topics := make([]*data.Topic, len(found))
+	examples := make([][]string, len(found))
+	for i, cl := range found {
+		topics[i], examples[i] = cl.topic, cl.examples
+	}
+	m.labelTopics(ctx, topics, examples)
+
+	if err := m.store.ReplaceTopics(ctx, topics); err != nil {
+		return nil, fmt.Errorf("saving topics: %w", err)
+	}
+	return topics, nil
+}
+
+// labelTopics names topics with the LLM, given the texts of their most
+// representative members. Topics the LLM didn't label, or all of them when
+// there's no LLM or it fails, are named after their keywords.
+func (m *TopicModeler) labelTopics(ctx context.Context, topics []*data.Topic, examples [][]string) {
+	if m.llm != nil && len(topics) > 0 {
+		labels, err := m.llm.LabelTopics(ctx, topics, examples)
+		if err != nil {
+			log.Printf("topic labeling falling back to keywords: %v", err)
+		}
+		for i, label := range labels {
+			topics[i].Label = label
+		}
+	}
+	for _, topic := range topics {
+		if topic.Label == "" {
+			topic.Label = keywordLabel(topic.Keywords)
+		}
+	}
+}
+
+// LabelTopics asks the LLM for a short label for each topic, given its
+// keywords and examples of its members. Labels the model skipped or
+// couldn't give are empty; an error is returned only if none were given.
+func (c *LLMClient) LabelTopics(ctx context.Context, topics []*data.Topic, examples [][]string) ([]string, error) {
+	var prompt strings.Builder
+	prompt.WriteString("These are groups of social media posts and audience comments that are about the same theme. Name the theme of each group.\n")
+	for i, topic := range topics {
+		fmt.Fprintf(&prompt, "\nGroup %d", i+1)
+		if len(topic.Keywords) > 0 {
+			fmt.Fprintf(&prompt, " (keywords: %s)", strings.Join(topic.Keywords, ", "))
+		}
+		prompt.WriteString(":\n")
+		if i < len(examples) {
+			for _, text := range examples[i] {
+				fmt.Fprintf(&prompt, "- %s\n", snippet(text, topicExampleLimit))
+			}
+		}
+	}
+	prompt.WriteString("\n" + topicLabelInstructions)
+
+	var labels []string
+	err := c.generateJSON(ctx, prompt.String(), topicLabelSchema, nil, nil, func(text string) error {
+		var err error
+		labels, err = decodeTopicLabels(text, len(topics))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("labeling topics: %w", err)
+	}
+	return labels, nil
+}
+
+// decodeTopicLabels parses a label response for n topics into a label per
+// topic, empty where the response has no valid label.
+func decodeTopicLabels(text string, n int) ([]string, error) {
+	raw, err := extractJSON(text)
+	if err != nil {
+		return nil, err
+	}
+	var output struct {
+		Topics []struct {
+			N     int    `json:"n"`
+			Label string `json:"label"`
+		} `json:"topics"`
+	}
+	if err := json.Unmarshal(raw, &output); err != nil || len(output.Topics) == 0 {
+		return nil, errors.New(`"topics" must be a non-empty array`)
+	}
+
+	labels := make([]string, n)
+	found := 0
+	for _, item := range output.Topics {
+		label := strings.Trim(strings.TrimSpace(item.Label), `".`)
+		if item.N < 1 || item.N > n || label == "" || labels[item.N-1] != "" {
+			continue
+		}
+		labels[item.N-1] = snippet(label, topicLabelMaxLength)
+		found++
+	}
+	if found == 0 {
+		return nil, fmt.Errorf("no valid labels: each needs n between 1 and %d and a non-empty label", n)
+	}
+	return labels, nil
+}
+
+// topicKeywords returns the terms most specific to a cluster: frequent in
+// its members and rare elsewhere, scored by count times inverse document
+// frequency. Terms used by only one member are ignored.
+func topicKeywords(members []int, terms []map[string]bool, docFreq map[string]int, total int) []string {
+	counts := make(map[string]int)
+	for _, i := range members {
+		for t := range terms[i] {
+			counts[t]++
+		}
+	}
+
+	type scored struct {
+		term  string
+		score float64
+	}
+	var candidates []scored
+	for t, count := range counts {
+		if count < 2 {
+			continue
+		}
+		idf := math.Log(float64(total) / float64(docFreq[t]))
+		candidates = append(candidates, scored{t, float64(count) * (idf + 0.1)})
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		if candidates[a].score != candidates[b].score {
+			return candidates[a].score > candidates[b].score
+		}
+		return candidates[a].term < candidates[b].term
+	})
+
+	keywords := make([]string, 0, topicKeywordCount)
+	for _, c := range candidates[:min(topicKeywordCount, len(candidates))] {
+		keywords = append(keywords, c.term)
+	}
+	return keywords
+}
+
+// keywordLabel names a topic after its top keywords.
+func keywordLabel(keywords []string) string {
+	if len(keywords) == 0 {
+		return "Miscellaneous"
+	}
+	label := []rune(strings.Join(keywords[:min(3, len(keywords))], ", "))
+	label[0] = unicode.ToUpper(label[0])
+	return string(label)
+}
+
+// kmeans clusters unit vectors into k groups by cosine similarity
+// (spherical k-means) and returns each vector's cluster and the unit
+// centroids. Centroids are seeded with k-means++ from a fixed seed, so the
+// same embeddings always give the same topics.
+func kmeans(vectors [][]float64, k int) ([]int, [][]float64) {
+	rng := rand.New(rand.NewSource(1))
+	centroids := make([][]float64, 0, k)
+	centroids = append(centroids, vectors[rng.Intn(len(vectors))])
+
+	// k-means++: pick each further centroid with probability proportional
+	// to its squared distance from the nearest centroid so far.
+	distances := make([]float64, len(vectors))
+	for len(centroids) < k {
+		var sum float64
+		for i, v := range vectors {
+			nearest := math.Inf(1)
+			for _, c := range centroids {
+				nearest = math.Min(nearest, 1-dot(v, c))
+			}
+			distances[i] = nearest * nearest
+			sum += distances[i]
+		}
+		if sum == 0 {
+			break // Fewer distinct vectors than clusters
+		}
+		target := rng.Float64() * sum
+		next := len(vectors) - 1
+		for i, d := range distances {
+			if target < d {
+				next = i
+				break
+			}
+			target -= d
+		}
+		centroids = append(centroids, vectors[next])
+	}
+
+	assignments := make([]int, len(vectors))
+	for iter := 0; iter < kmeansIterations; iter++ {
+		changed := false
+		for i, v := range vectors {
+			best, bestSim := 0, math.Inf(-1)
+			for c, centroid := range centroids {
+				if sim := dot(v, centroid); sim > bestSim {
+					best, bestSim = c, sim
+				}
+			}
+			if assignments[i] != best {
+				assignments[i] = best
+				changed = true
+			}
+		}
+		if !changed && iter > 0 {
+			break
+		}
+
+		// Move each centroid to the normalized mean of its vectors. An
+		// empty cluster keeps its centroid.
+		sums := make([][]float64, len(centroids))
+		for i, v := range vectors {
+			c := assignments[i]
+			if sums[c] == nil {
+				sums[c] = make([]float64, len(v))
+			}
+			for d, x := range v {
+				sums[c][d] += x
+			}
+		}
+		for c, s := range sums {
+			if s != nil {
+				centroids[c] = normalize(s)
+			}
+		}
+	}
+	return assignments, centroids
+}
+
+// unitVector converts v to float64 with length 1.
+func unitVector(v []float32) []float64 {
+	u := make([]float64, len(v))
+	for i, x := range v {
+		u[i] = float64(x)
+	}
+	return normalize(u)
+}
+
+// normalize scales v in place to length 1, leaving a zero vector as is.
+func normalize(v []float64) []float64 {
+	norm := math.Sqrt(dot(v, v))
+	if norm == 0 {
+		return v
+	}
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
+
+// dot returns the dot product of a and b, or 0 if their lengths differ.
+func dot(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -4,7 +4,7 @@ package insights
 import (
 	"context"
 	"math"
-	"time"
+	"sort"
 
 	"github.com/omnipulse/omnipulse/internal/data"
 	"github.com/omnipulse/omnipulse/internal/storage"
@@ -37,122 +37,102 @@ func (t *TrendAnalyzer) AnalyzeTrend(ctx context.Context, platform data.Platform
 	return trend, nil
 }
 
-// calculateTrendMetrics calculates trend direction and percentage change.
+// calculateTrendMetrics calculates trend direction and percentage change
+// from a least-squares line through the points, so a noisy first or last
+// point doesn't decide the trend. The change is relative to the line's
+// starting value, or to the mean when the line starts at or below zero.
 func calculateTrendMetrics(points []data.DataPoint) (string, float64) {
 	if len(points) < 2 {
 		return "stable", 0
 	}
 
-	// Sort points by timestamp (should already be sorted, but ensure)
-	// Use first and last points for overall trend
-	firstValue := points[0].Value
-	lastValue := points[len(points)-1].Value
-
-	if firstValue == 0 {
-		if lastValue > 0 {
-			return "up", 100
-		}
+	sorted := append([]data.DataPoint(nil), points...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	// Fit value = intercept + slope*days since the first point.
+	first := sorted[0].Timestamp
+	n := float64(len(sorted))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, p := range sorted {
+		x := p.Timestamp.Sub(first).Hours() / 24
+		sumX += x
+		sumY += p.Value
+		sumXY += x * p.Value
+		sumXX += x * x
+	}
+	meanX, meanY := sumX/n, sumY/n
+	variance := sumXX/n - meanX*meanX
+	if variance <= 0 {
 		return "stable", 0
 	}
-
-	changePercent := ((lastValue - firstValue) / firstValue) * 100
-
-	var trend string
-	switch {
-	case changePercent > 5:
-		trend = "up"
-	case changePercent < -5:
-		trend = "down"
-	default:
-		trend = "stable"
+	slope := (sumXY/n - meanX*meanY) / variance
+	span := sorted[len(sorted)-1].Timestamp.Sub(first).Hours() / 24
+	start := meanY - slope*meanX
+	change := slope * span
+
+	base := start
+	if base <= 0 {
+		base = meanY
 	}
-
-	return trend, math.Round(changePercent*100) / 100
-}
-
-// DetectAnomalies identifies unusual patterns in the data.
-func (t *TrendAnalyzer) DetectAnomalies(ctx context.Context, platform data.Platform, metric string, days int) ([]*Anomaly, error) {
-	trend, err := t.store.GetTrendData(ctx, platform, metric, days)
-	if err != nil || trend == nil {
-		return nil, err
+	if base <= 0 {
+		return "stable", 0
 	}
 
-	anomalies := detectAnomaliesInPoints(trend.Points)
-	return anomalies, nil
-}
-
-// Anomaly represents an unusual data point or pattern.
-type Anomaly struct {
-	Timestamp   time.Time `json:"timestamp"`
-	Value       float64   `json:"value"`
-	ExpectedMin float64   `json:"expected_min"`
-	ExpectedMax float64   `json:"expected_max"`
-	Type        string    `json:"type"` // "spike", "drop", "unusual_pattern"
-	Severity    string    `json:"severity"` // "low", "medium", "high"
+	changePercent := change / base * 100
+	return trendDirection(changePercent), math.Round(changePercent*100) / 100
 }
 
-// detectAnomaliesInPoints uses simple statistical analysis to find anomalies.
-func detectAnomaliesInPoints(points []data.DataPoint) []*Anomaly {
-	if len(points) < 7 { // Need enough data for meaningful analysis
-		return nil
+// ComparePeriods compares a metric between period1 and the earlier
+// period2 it's measured against. Each period is aggregated by the
+// metric's MetricAggregation. It returns nil if either period lacks the
+// data to compare.
+func (t *TrendAnalyzer) ComparePeriods(ctx context.Context, platform data.Platform, metric string, period1, period2 data.DateRange) (*PeriodComparison, error) {
+	aggregation := AggregationFor(metric)
+	value1, coverage1, ok1, err := t.periodValue(ctx, platform, metric, aggregation, period1)
+	if err != nil {
+		return nil, err
 	}
-
-	// Calculate mean and standard deviation
-	var sum, sumSquares float64
-	for _, p := range points {
-		sum += p.Value
-		sumSquares += p.Value * p.Value
+	value2, coverage2, ok2, err := t.periodValue(ctx, platform, metric, aggregation, period2)
+	if err != nil {
+		return nil, err
 	}
-	mean := sum / float64(len(points))
-	variance := (sumSquares / float64(len(points))) - (mean * mean)
-	stdDev := math.Sqrt(variance)
-
-	// Find points outside 2 standard deviations
-	var anomalies []*Anomaly
-	threshold := 2.0
-	for _, p := range points {
-		deviation := math.Abs(p.Value - mean)
-		if deviation > threshold*stdDev {
-			anomalyType := "spike"
-			if p.Value < mean {
-				anomalyType = "drop"
-			}
-
-			severity := "low"
-			if deviation > 3*stdDev {
-				severity = "high"
-			} else if deviation > 2.5*stdDev {
-				severity = "medium"
-			}
-
-			anomalies = append(anomalies, &Anomaly{
-				Timestamp:   p.Timestamp,
-				Value:       p.Value,
-				ExpectedMin: mean - threshold*stdDev,
-				ExpectedMax: mean + threshold*stdDev,
-				Type:        anomalyType,
-				Severity:    severity,
-			})
-		}
+	if !ok1 || !ok2 {
+		return nil, nil
 	}
 
-	return anomalies
-}
-
-// ComparePerioods compares metrics between two time periods.
-func (t *TrendAnalyzer) ComparePeriods(ctx context.Context, platform data.Platform, metric string, period1, period2 data.DateRange) (*PeriodComparison, error) {
-	// TODO: Implement period-over-period comparison
-	// Useful for week-over-week or month-over-month analysis
-	return nil, nil
+	delta := NewDelta(value1, value2)
+	return &PeriodComparison{
+		Platform:        platform,
+		Metric:          metric,
+		Aggregation:     aggregation,
+		Period1:         period1,
+		Period2:         period2,
+		Period1Value:    value1,
+		Period2Value:    value2,
+		Period1Coverage: coverage1,
+		Period2Coverage: coverage2,
+		Change:          delta.Change,
+		ChangePercent:   delta.ChangePercent,
+		Trend:           trendDirection(delta.ChangePercent),
+	}, nil
 }
 
 // PeriodComparison holds comparison data between two time periods.
 type PeriodComparison struct {
-	Platform     data.Platform `json:"platform"`
-	Metric       string        `json:"metric"`
-	Period1Value float64       `json:"period1_value"`
-	Period2Value float64       `json:"period2_value"`
-	Change       float64       `json:"change"`
-	ChangePercent float64      `json:"change_percent"`
-	Trend        string        `json:"trend"`
+	Platform      data.Platform     `json:"platform"`
+	Metric        string            `json:"metric"`
+	Aggregation   MetricAggregation `json:"aggregation"`
+	Period1       data.DateRange    `json:"period1"`
+	Period2       data.DateRange    `json:"period2"`
+	Period1Value  float64           `json:"period1_value"`
+	Period2Value  float64           `json:"period2_value"`
+	Change        float64           `json:"change"`
+	ChangePercent float64           `json:"change_percent"`
+	Trend         string            `json:"trend"`
+
+	// Share of each period covered by data, from 0 to 1. Flow totals of
+	// partly covered periods are extrapolated to the whole period, and a
+	// stock carried forward from before its period has coverage 0.
+	Period1Coverage float64 `json:"period1_coverage"`
+	Period2Coverage float64 `json:"period2_coverage"`
 }
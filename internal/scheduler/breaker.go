@@ -0,0 +1,146 @@
+// Package scheduler provides circuit breakers that pause failing tasks.
+package scheduler
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when a task is not run because its circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState string
+
+// Circuit breaker states.
+const (
+	// BreakerClosed lets runs through normally.
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen skips runs until the cooldown has passed.
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen lets a single trial run through after the cooldown;
+	// its result closes or re-opens the breaker.
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// CircuitBreaker pauses a group of tasks, such as all of one platform's
+// fetches, after repeated consecutive failures.
+type CircuitBreaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	lastError string
+	trial     bool // A half-open trial run is in progress
+}
+
+// NewCircuitBreaker creates a breaker that opens after threshold
+// consecutive failures and allows a trial run after cooldown.
+func NewCircuitBreaker(name string, threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+	}
+}
+
+// Allow reports whether a run may proceed. Callers that are allowed must
+// report the outcome with Success or Failure.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.trial = true
+		return true
+	case BreakerHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	}
+	return true
+}
+
+// Success records a successful run, closing the breaker.
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.trial = false
+	b.lastError = ""
+}
+
+// Failure records a failed run, opening the breaker if the failure
+// threshold is reached or a half-open trial fails.
+func (b *CircuitBreaker) Failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if err != nil {
+		b.lastError = err.Error()
+	}
+	if b.state == BreakerHalfOpen || (b.threshold > 0 && b.failures >= b.threshold) {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Abandon records that an allowed run ended without an outcome, such as
+// when the scheduler is stopped mid-run, so another trial may proceed.
+func (b *CircuitBreaker) Abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+// Status returns a snapshot of the breaker's state.
+func (b *CircuitBreaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{
+		Name:      b.name,
+		State:     b.state,
+		Failures:  b.failures,
+		LastError: b.lastError,
+	}
+	if b.state != BreakerClosed {
+		status.OpenedAt = b.openedAt
+		status.RetryAt = b.openedAt.Add(b.cooldown)
+	}
+	return status
+}
+
+// BreakerStatus describes a circuit breaker's state.
+type BreakerStatus struct {
+	Name      string       `json:"name"`
+	State     BreakerState `json:"state"`
+	Failures  int          `json:"failures"` // Consecutive failures
+	LastError string       `json:"last_error,omitempty"`
+	OpenedAt  time.Time    `json:"opened_at,omitempty"`
+	RetryAt   time.Time    `json:"retry_at,omitempty"` // When the next trial run is allowed
+}
+
+// IsOpen reports whether runs are currently being skipped.
+func (s BreakerStatus) IsOpen() bool {
+	return s.State != BreakerClosed
+}
@@ -0,0 +1,41 @@
+// Package scheduler provides task run recording and reporting.
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// RunRecorder persists task run history. storage.Store implements it.
+type RunRecorder interface {
+	StartTaskRun(ctx context.Context, run *data.TaskRun) error
+	FinishTaskRun(ctx context.Context, run *data.TaskRun) error
+	GetLatestTaskRuns(ctx context.Context) ([]*data.TaskRun, error)
+}
+
+// runStatsKey is the context key for the current run's statistics.
+type runStatsKey struct{}
+
+// runStats accumulates the counts tasks report during a run.
+type runStats struct {
+	items    atomic.Int64
+	apiUnits atomic.Int64
+}
+
+// ReportItems adds n to the number of items fetched by the task running
+// with ctx. It does nothing outside a scheduled run.
+func ReportItems(ctx context.Context, n int) {
+	if stats, ok := ctx.Value(runStatsKey{}).(*runStats); ok {
+		stats.items.Add(int64(n))
+	}
+}
+
+// ReportAPIUnits adds n to the API quota units used by the task running
+// with ctx. It does nothing outside a scheduled run.
+func ReportAPIUnits(ctx context.Context, n int) {
+	if stats, ok := ctx.Value(runStatsKey{}).(*runStats); ok {
+		stats.apiUnits.Add(int64(n))
+	}
+}
@@ -0,0 +1,231 @@
+// Package scheduler provides lease-based leader election between replicas.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/config"
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// LeaseName is the lease whose holder runs scheduled tasks.
+const LeaseName = "scheduler"
+
+// ErrNotLeader is returned when a task is run on a replica that does not
+// hold the scheduler lease.
+var ErrNotLeader = errors.New("not the scheduler leader")
+
+// errLeaseLost is the cause of run contexts cancelled by a lost lease.
+var errLeaseLost = errors.New("scheduler lease lost")
+
+// LeaseStore persists leases. storage.Store implements it.
+type LeaseStore interface {
+	AcquireLease(ctx context.Context, name, holder, address string, ttl time.Duration) (*data.Lease, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+	GetLease(ctx context.Context, name string) (*data.Lease, error)
+}
+
+// Elector holds the scheduler lease on behalf of one replica, renewing it
+// every third of its TTL. Leadership is timed by the local clock from when
+// the last successful renewal was sent, so a replica whose renewals stall
+// steps down no later than the lease expires in the database and passes to
+// another replica. Replica clocks must agree to well within the TTL.
+type Elector struct {
+	store   LeaseStore
+	holder  string
+	address string
+	ttl     time.Duration
+
+	mu       sync.Mutex // Guards the fields below
+	lease    *data.Lease
+	deadline time.Time     // When this replica stops considering itself leader
+	expiry   *time.Timer   // Steps down at deadline unless renewed
+	lost     chan struct{} // Closed when the current leadership ends
+}
+
+// NewElector creates an Elector for this replica, or returns nil if leader
+// election is disabled by a zero LeaseTTL.
+func NewElector(store LeaseStore, cfg config.SchedulerConfig) *Elector {
+	if cfg.LeaseTTL <= 0 {
+		return nil
+	}
+	return &Elector{
+		store:   store,
+		holder:  cfg.InstanceID,
+		address: cfg.AdvertiseURL,
+		ttl:     cfg.LeaseTTL,
+	}
+}
+
+// run renews or tries to acquire the lease every third of its TTL until
+// ctx is cancelled, then releases it so another replica can take over
+// immediately.
+func (e *Elector) run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.renew(ctx)
+		case <-ctx.Done():
+			e.release()
+			return
+		}
+	}
+}
+
+// renew makes one attempt to acquire or renew the lease.
+func (e *Elector) renew(ctx context.Context) {
+	sent := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, e.ttl/3)
+	defer cancel()
+
+	lease, err := e.store.AcquireLease(ctx, LeaseName, e.holder, e.address, e.ttl)
+	if err != nil {
+		// Leadership lasts until the deadline; the next renewal may succeed.
+		log.Printf("error renewing scheduler lease: %v", err)
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	held := lease != nil && lease.Holder == e.holder
+	if e.lost != nil && (!held || !e.isLeaderLocked(sent) || lease.Token != e.lease.Token) {
+		// Leadership lapsed, or another replica held the lease in between.
+		e.stepDownLocked()
+	}
+	e.lease = lease
+	if !held {
+		return
+	}
+
+	e.deadline = sent.Add(e.ttl)
+	if e.expiry != nil {
+		e.expiry.Stop()
+	}
+	e.expiry = time.AfterFunc(time.Until(e.deadline), e.stepDownIfExpired)
+	if e.lost == nil {
+		e.lost = make(chan struct{})
+		log.Printf("Acquired scheduler lease as %s (token %d)", e.holder, lease.Token)
+	}
+}
+
+// release gives up the lease on shutdown.
+func (e *Elector) release() {
+	e.stepDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.store.ReleaseLease(ctx, LeaseName, e.holder); err != nil {
+		log.Printf("error releasing scheduler lease: %v", err)
+	}
+}
+
+// stepDown ends this replica's leadership, cancelling runs in progress.
+func (e *Elector) stepDown() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stepDownLocked()
+}
+
+// stepDownIfExpired steps down once the local deadline has passed.
+func (e *Elector) stepDownIfExpired() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.isLeaderLocked(time.Now()) {
+		e.stepDownLocked()
+	}
+}
+
+func (e *Elector) stepDownLocked() {
+	if e.expiry != nil {
+		e.expiry.Stop()
+		e.expiry = nil
+	}
+	if e.lost != nil {
+		close(e.lost)
+		e.lost = nil
+		log.Printf("Lost scheduler lease as %s", e.holder)
+	}
+	e.deadline = time.Time{}
+}
+
+func (e *Elector) isLeaderLocked(now time.Time) bool {
+	return e.lost != nil && now.Before(e.deadline)
+}
+
+// IsLeader reports whether this replica currently holds the lease.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.isLeaderLocked(time.Now())
+}
+
+// leaseContext returns a context for a task run that is cancelled if this
+// replica loses the lease. As a fencing check it confirms in the database
+// that the lease is still held with the same token before the run starts,
+// so a replica that was paused past its deadline cannot start work.
+func (e *Elector) leaseContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	e.mu.Lock()
+	if !e.isLeaderLocked(time.Now()) {
+		e.mu.Unlock()
+		return nil, nil, ErrNotLeader
+	}
+	lost, token := e.lost, e.lease.Token
+	e.mu.Unlock()
+
+	current, err := e.store.GetLease(ctx, LeaseName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !current.HeldBy(e.holder, time.Now()) || current.Token != token {
+		e.stepDown()
+		return nil, nil, ErrNotLeader
+	}
+
+	runCtx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		select {
+		case <-lost:
+			cancel(errLeaseLost)
+		case <-runCtx.Done():
+		}
+	}()
+	return runCtx, func() { cancel(nil) }, nil
+}
+
+// LeaderStatus describes which replica runs scheduled tasks.
+type LeaderStatus struct {
+	Enabled       bool      `json:"enabled"` // False when leader election is disabled
+	Instance      string    `json:"instance"`
+	IsLeader      bool      `json:"is_leader"`
+	Leader        string    `json:"leader,omitempty"`         // Instance ID of the current leader, if known
+	LeaderAddress string    `json:"leader_address,omitempty"` // Where to forward manual runs
+	Token         int64     `json:"token,omitempty"`
+	ExpiresAt     time.Time `json:"expires_at,omitempty"`
+}
+
+// Status returns this replica's view of the lease as of its last renewal.
+func (e *Elector) Status() LeaderStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	status := LeaderStatus{
+		Enabled:  true,
+		Instance: e.holder,
+		IsLeader: e.isLeaderLocked(time.Now()),
+	}
+	if e.lease != nil && time.Now().Before(e.lease.ExpiresAt) {
+		status.Leader = e.lease.Holder
+		status.LeaderAddress = e.lease.Address
+		status.Token = e.lease.Token
+		status.ExpiresAt = e.lease.ExpiresAt
+	}
+	return status
+}
@@ -0,0 +1,105 @@
+// Package scheduler provides retry policies for failed task runs.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RetryPolicy controls how a failed task run is retried before the
+// scheduler waits for its next scheduled time.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per run, including the
+	// first. Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after each retry. Values below 1 are
+	// treated as 2.
+	Multiplier float64
+	// Retryable decides whether an error is worth retrying. If nil,
+	// IsRetryable is used.
+	Retryable func(error) bool
+}
+
+// NoRetry is a policy that never retries.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// backoff returns the delay before the given retry (1 for the first
+// retry), using full jitter: a random duration up to the exponential
+// backoff for that attempt.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	d := float64(p.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		d *= multiplier
+		if p.MaxBackoff > 0 && d >= float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	return jitter(time.Duration(d))
+}
+
+// retryable reports whether err should be retried under this policy.
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// permanentError marks an error as not worth retrying.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that IsRetryable reports false for it. Tasks can
+// use it for failures that retrying cannot fix, such as missing
+// configuration.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsRetryable classifies task errors. Errors wrapped with Permanent,
+// cancellations and errors whose HTTPStatus is a client error (such as 401
+// or 404, but not 408 or 429) are not retryable. Errors with a Temporary
+// method are retried if it reports true, and all other errors, including
+// server errors such as 503 and timeouts, are retried.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) {
+		return temporary.Temporary()
+	}
+
+	var status interface{ HTTPStatus() int }
+	if errors.As(err, &status) {
+		code := status.HTTPStatus()
+		return code >= 500 || code == 408 || code == 425 || code == 429
+	}
+	return true
+}
@@ -3,30 +3,70 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"math/rand/v2"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/omnipulse/omnipulse/internal/config"
+	"github.com/omnipulse/omnipulse/internal/data"
 )
 
+// ErrTaskRunning is returned by RunTaskNow when the task is already running.
+var ErrTaskRunning = errors.New("task is already running")
+
+// ErrTaskNotFound is returned by RunTaskNow for an unknown task name.
+var ErrTaskNotFound = errors.New("task not found")
+
 // Scheduler manages periodic background tasks.
 type Scheduler struct {
 	config   config.SchedulerConfig
 	tasks    []*Task
+	breakers map[string]*CircuitBreaker
+	recorder RunRecorder
+	elector  *Elector
 	mu       sync.RWMutex
 	running  bool
-	stopChan chan struct{}
+	ctx      context.Context // Cancelled by Stop
+	cancel   context.CancelFunc
 	wg       sync.WaitGroup
 }
 
 // Task represents a scheduled task.
 type Task struct {
 	Name     string
-	Interval time.Duration
+	Schedule Schedule
+	Options  TaskOptions
 	Fn       func(ctx context.Context) error
-	ticker   *time.Ticker
-	stopChan chan struct{}
+	breaker  *CircuitBreaker
+	running  atomic.Bool
+
+	mu        sync.Mutex // Guards the fields below
+	lastRun   *data.TaskRun
+	lastError *data.TaskRun // Most recent failed run
+	nextRun   time.Time
+}
+
+// TaskOptions controls how a task is run. Zero values fall back to the
+// scheduler's configured defaults.
+type TaskOptions struct {
+	// Jitter is the maximum random delay added to each run so tasks
+	// scheduled for the same time don't all start at once.
+	Jitter time.Duration
+	// Timeout bounds each run of the task.
+	Timeout time.Duration
+	// RunOnStart runs the task (after jitter) as soon as the scheduler
+	// starts, rather than waiting for its first scheduled time.
+	RunOnStart bool
+	// Retry controls retries of failed runs. Use &NoRetry to disable them.
+	Retry *RetryPolicy
+	// Breaker names the circuit breaker the task shares, usually its
+	// platform. Tasks without one are never paused.
+	Breaker string
 }
 
 // NewScheduler creates a new Scheduler.
@@ -34,20 +74,76 @@ func NewScheduler(cfg config.SchedulerConfig) *Scheduler {
 	return &Scheduler{
 		config:   cfg,
 		tasks:    make([]*Task, 0),
-		stopChan: make(chan struct{}),
+		breakers: make(map[string]*CircuitBreaker),
 	}
 }
 
-// AddTask adds a new task to the scheduler.
+// SetRecorder sets where task runs are recorded. It must be called before
+// Start.
+func (s *Scheduler) SetRecorder(recorder RunRecorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recorder = recorder
+}
+
+// SetElector makes the scheduler run tasks only while this replica holds
+// the scheduler lease, so replicas sharing a database don't run every task
+// twice. It must be called before Start.
+func (s *Scheduler) SetElector(elector *Elector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.elector = elector
+}
+
+// AddTask adds a task that runs at a fixed interval, starting immediately
+// when the scheduler starts.
 func (s *Scheduler) AddTask(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	s.AddScheduledTask(name, Every(interval), TaskOptions{RunOnStart: true}, fn)
+}
+
+// AddCronTask adds a task that runs on a cron expression evaluated in local
+// time, such as "0 7 * * *" for 07:00 every day.
+func (s *Scheduler) AddCronTask(name, expr string, opts TaskOptions, fn func(ctx context.Context) error) error {
+	schedule, err := ParseCron(expr, nil)
+	if err != nil {
+		return fmt.Errorf("adding task %s: %w", name, err)
+	}
+	s.AddScheduledTask(name, schedule, opts, fn)
+	return nil
+}
+
+// AddScheduledTask adds a task that runs on the given schedule.
+func (s *Scheduler) AddScheduledTask(name string, schedule Schedule, opts TaskOptions, fn func(ctx context.Context) error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if opts.Jitter == 0 {
+		opts.Jitter = s.config.Jitter
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = s.config.TaskTimeout
+	}
+	if opts.Retry == nil {
+		opts.Retry = &RetryPolicy{
+			MaxAttempts:    s.config.MaxAttempts,
+			InitialBackoff: s.config.RetryBackoff,
+			MaxBackoff:     s.config.RetryMaxBackoff,
+		}
+	}
+
 	task := &Task{
 		Name:     name,
-		Interval: interval,
+		Schedule: schedule,
+		Options:  opts,
 		Fn:       fn,
-		stopChan: make(chan struct{}),
+	}
+	if opts.Breaker != "" {
+		breaker, ok := s.breakers[opts.Breaker]
+		if !ok {
+			breaker = NewCircuitBreaker(opts.Breaker, s.config.BreakerThreshold, s.config.BreakerCooldown)
+			s.breakers[opts.Breaker] = breaker
+		}
+		task.breaker = breaker
 	}
 	s.tasks = append(s.tasks, task)
 
@@ -57,7 +153,8 @@ func (s *Scheduler) AddTask(name string, interval time.Duration, fn func(ctx con
 	}
 }
 
-// Start begins executing all scheduled tasks.
+// Start begins executing all scheduled tasks. Tasks run with contexts
+// derived from ctx, which are also cancelled when Stop is called.
 func (s *Scheduler) Start(ctx context.Context) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -67,6 +164,17 @@ func (s *Scheduler) Start(ctx context.Context) {
 	}
 
 	s.running = true
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.loadHistory(ctx)
+	if s.elector != nil {
+		// Try for the lease before tasks that run on start are due.
+		s.elector.renew(s.ctx)
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.elector.run(s.ctx)
+		}()
+	}
 	for _, task := range s.tasks {
 		s.startTask(task)
 	}
@@ -74,38 +182,165 @@ func (s *Scheduler) Start(ctx context.Context) {
 	log.Printf("Scheduler started with %d tasks", len(s.tasks))
 }
 
-// startTask starts a single task's ticker loop.
+// startTask starts a single task's scheduling loop.
 func (s *Scheduler) startTask(task *Task) {
-	task.ticker = time.NewTicker(task.Interval)
+	ctx := s.ctx
 	s.wg.Add(1)
 
 	go func() {
 		defer s.wg.Done()
-		defer task.ticker.Stop()
 
-		// Run immediately on start
-		if err := task.Fn(context.Background()); err != nil {
-			log.Printf("Task %s error: %v", task.Name, err)
+		next := time.Now()
+		if !task.Options.RunOnStart {
+			next = task.Schedule.Next(next)
 		}
 
-		for {
+		for !next.IsZero() {
+			at := next.Add(jitter(task.Options.Jitter))
+			task.setNextRun(at)
+			timer := time.NewTimer(time.Until(at))
 			select {
-			case <-task.ticker.C:
-				log.Printf("Running task: %s", task.Name)
-				if err := task.Fn(context.Background()); err != nil {
-					log.Printf("Task %s error: %v", task.Name, err)
+			case <-timer.C:
+				s.wg.Add(1)
+				go func() {
+					defer s.wg.Done()
+					switch err := s.runTask(ctx, task, false); {
+					case err == nil, errors.Is(err, ErrNotLeader):
+						// Followers leave scheduled runs to the leader.
+					case errors.Is(err, ErrTaskRunning):
+						log.Printf("Task %s skipped: previous run still in progress", task.Name)
+					case errors.Is(err, ErrCircuitOpen):
+						log.Printf("Task %s skipped: %s circuit breaker is open", task.Name, task.Options.Breaker)
+					default:
+						log.Printf("Task %s error: %v", task.Name, err)
+					}
+				}()
+				// Schedule from the planned time so jitter doesn't accumulate,
+				// skipping any runs missed while the process was suspended.
+				if next = task.Schedule.Next(next); !next.IsZero() && next.Before(time.Now()) {
+					next = task.Schedule.Next(time.Now())
 				}
-			case <-task.stopChan:
+			case <-ctx.Done():
+				timer.Stop()
+				task.setNextRun(time.Time{})
 				log.Printf("Task %s stopped", task.Name)
 				return
-			case <-s.stopChan:
-				return
 			}
 		}
+		task.setNextRun(time.Time{})
+		log.Printf("Task %s has no further scheduled runs", task.Name)
 	}()
 }
 
-// Stop stops all scheduled tasks.
+// runTask runs a task, retrying failures according to its retry policy,
+// unless a previous run is still in progress, its circuit breaker is open
+// or another replica holds the scheduler lease. Runs under a lease are
+// cancelled if it is lost. Each run is recorded in the task's history.
+func (s *Scheduler) runTask(ctx context.Context, task *Task, manual bool) error {
+	if !task.running.CompareAndSwap(false, true) {
+		return ErrTaskRunning
+	}
+	defer task.running.Store(false)
+
+	if s.elector != nil {
+		leaseCtx, cancel, err := s.elector.leaseContext(ctx)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+		ctx = leaseCtx
+	}
+
+	if task.breaker != nil && !task.breaker.Allow() {
+		return ErrCircuitOpen
+	}
+
+	log.Printf("Running task: %s", task.Name)
+	run := &data.TaskRun{
+		Task:      task.Name,
+		Status:    data.TaskRunRunning,
+		Manual:    manual,
+		StartedAt: time.Now(),
+	}
+	s.startRun(ctx, task, run)
+
+	stats := &runStats{}
+	attempts, err := s.runWithRetry(context.WithValue(ctx, runStatsKey{}, stats), task)
+
+	run.FinishedAt = time.Now()
+	run.Attempts = attempts
+	run.ItemsFetched = int(stats.items.Load())
+	run.APIUnits = int(stats.apiUnits.Load())
+	switch {
+	case err == nil:
+		run.Status = data.TaskRunSuccess
+	case ctx.Err() != nil:
+		run.Status = data.TaskRunCancelled
+		run.Error = err.Error()
+	default:
+		run.Status = data.TaskRunFailed
+		run.Error = err.Error()
+	}
+	s.finishRun(task, run)
+
+	if task.breaker != nil {
+		switch {
+		case err == nil:
+			task.breaker.Success()
+		case ctx.Err() != nil:
+			task.breaker.Abandon()
+		default:
+			task.breaker.Failure(err)
+		}
+	}
+	return err
+}
+
+// runWithRetry runs a task until it succeeds, fails with an error that
+// isn't retryable, or runs out of attempts. It returns the number of
+// attempts made.
+func (s *Scheduler) runWithRetry(ctx context.Context, task *Task) (int, error) {
+	policy := task.Options.Retry
+	for attempt := 1; ; attempt++ {
+		err := s.runAttempt(ctx, task)
+		if err == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil || !policy.retryable(err) {
+			return attempt, err
+		}
+
+		delay := policy.backoff(attempt)
+		log.Printf("Task %s attempt %d/%d failed: %v; retrying in %s",
+			task.Name, attempt, policy.MaxAttempts, err, delay.Round(time.Millisecond))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, err
+		}
+	}
+}
+
+// runAttempt runs a task once with its timeout.
+func (s *Scheduler) runAttempt(ctx context.Context, task *Task) error {
+	if task.Options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, task.Options.Timeout)
+		defer cancel()
+	}
+	return task.Fn(ctx)
+}
+
+// jitter returns a random duration in [0, limit).
+func jitter(limit time.Duration) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	return rand.N(limit)
+}
+
+// Stop stops all scheduled tasks, cancelling any runs in progress, and
+// waits for them to return.
 func (s *Scheduler) Stop() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -115,10 +350,7 @@ func (s *Scheduler) Stop() {
 	}
 
 	// Signal all tasks to stop
-	close(s.stopChan)
-	for _, task := range s.tasks {
-		close(task.stopChan)
-	}
+	s.cancel()
 
 	// Wait for all tasks to finish
 	s.wg.Wait()
@@ -134,7 +366,7 @@ func (s *Scheduler) IsRunning() bool {
 	return s.running
 }
 
-// GetTasks returns a list of task names and their intervals.
+// GetTasks returns information about each scheduled task.
 func (s *Scheduler) GetTasks() []TaskInfo {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -143,27 +375,212 @@ func (s *Scheduler) GetTasks() []TaskInfo {
 	for i, task := range s.tasks {
 		info[i] = TaskInfo{
 			Name:     task.Name,
-			Interval: task.Interval,
+			Schedule: task.Schedule.String(),
+			Timeout:  task.Options.Timeout,
+			Running:  task.running.Load(),
+			Breaker:  task.Options.Breaker,
+		}
+		if interval, ok := task.Schedule.(intervalSchedule); ok {
+			info[i].Interval = time.Duration(interval)
+		}
+
+		task.mu.Lock()
+		info[i].LastRun = task.lastRun
+		info[i].NextRun = task.nextRun
+		if task.lastError != nil {
+			info[i].LastError = task.lastError.Error
+			info[i].LastErrorAt = task.lastError.StartedAt
 		}
+		task.mu.Unlock()
 	}
 	return info
 }
 
+// GetBreakers returns the state of each circuit breaker, sorted by name.
+func (s *Scheduler) GetBreakers() []BreakerStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]BreakerStatus, 0, len(s.breakers))
+	for _, breaker := range s.breakers {
+		statuses = append(statuses, breaker.Status())
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
 // TaskInfo contains information about a scheduled task.
 type TaskInfo struct {
 	Name     string        `json:"name"`
-	Interval time.Duration `json:"interval"`
+	Schedule string        `json:"schedule"`
+	Interval time.Duration `json:"interval,omitempty"` // Zero for cron tasks
+	Timeout  time.Duration `json:"timeout"`
+	Running  bool          `json:"running"`
+	Breaker  string        `json:"breaker,omitempty"`
+
+	LastRun     *data.TaskRun `json:"last_run,omitempty"`
+	NextRun     time.Time     `json:"next_run,omitempty"` // Zero when the scheduler is stopped
+	LastError   string        `json:"last_error,omitempty"`
+	LastErrorAt time.Time     `json:"last_error_at,omitempty"`
 }
 
-// RunTaskNow executes a specific task immediately.
+// RunTaskNow executes a specific task immediately. It returns
+// ErrTaskNotFound for an unknown task, ErrTaskRunning if the task is
+// already running, ErrCircuitOpen if its circuit breaker is open and
+// ErrNotLeader if another replica holds the scheduler lease; such runs
+// should be forwarded to the leader given by LeaderStatus.
 func (s *Scheduler) RunTaskNow(ctx context.Context, name string) error {
+	s.mu.RLock()
+	var found *Task
+	for _, task := range s.tasks {
+		if task.Name == name {
+			found = task
+			break
+		}
+	}
+	s.mu.RUnlock()
+
+	if found == nil {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+	return s.runTask(ctx, found, true)
+}
+
+// TriggerTask starts a task immediately in the background, as RunTaskNow
+// does, without waiting for it to finish. It returns ErrTaskNotFound for
+// an unknown task and ErrNotLeader if another replica holds the scheduler
+// lease; other errors are logged when the run ends.
+func (s *Scheduler) TriggerTask(name string) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	var found *Task
 	for _, task := range s.tasks {
 		if task.Name == name {
-			return task.Fn(ctx)
+			found = task
+			break
 		}
 	}
+	if found == nil {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+	if s.elector != nil && !s.elector.IsLeader() {
+		return ErrNotLeader
+	}
+
+	// Runs started while the scheduler is running are cancelled by Stop.
+	ctx := context.Background()
+	if s.running {
+		ctx = s.ctx
+		s.wg.Add(1)
+	}
+	go func(running bool) {
+		if running {
+			defer s.wg.Done()
+		}
+		if err := s.runTask(ctx, found, true); err != nil {
+			log.Printf("Task %s error: %v", found.Name, err)
+		}
+	}(s.running)
 	return nil
 }
+
+// LeaderStatus reports which replica runs scheduled tasks. Without an
+// elector this replica always does.
+func (s *Scheduler) LeaderStatus() LeaderStatus {
+	s.mu.RLock()
+	elector := s.elector
+	s.mu.RUnlock()
+
+	if elector == nil {
+		return LeaderStatus{IsLeader: true}
+	}
+	return elector.Status()
+}
+
+// HasTask reports whether a task with the given name is registered.
+func (s *Scheduler) HasTask(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, task := range s.tasks {
+		if task.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// loadHistory restores each task's last run from the recorder so status
+// survives restarts.
+func (s *Scheduler) loadHistory(ctx context.Context) {
+	if s.recorder == nil {
+		return
+	}
+	runs, err := s.recorder.GetLatestTaskRuns(ctx)
+	if err != nil {
+		log.Printf("error loading task history: %v", err)
+		return
+	}
+	for _, run := range runs {
+		for _, task := range s.tasks {
+			if task.Name != run.Task {
+				continue
+			}
+			task.mu.Lock()
+			task.lastRun = run
+			if run.Status == data.TaskRunFailed {
+				task.lastError = run
+			}
+			task.mu.Unlock()
+		}
+	}
+}
+
+// startRun records the start of a run. The recorder is read without
+// locking because Stop holds the lock while waiting for runs to finish;
+// SetRecorder must not be called once the scheduler has started.
+func (s *Scheduler) startRun(ctx context.Context, task *Task, run *data.TaskRun) {
+	if s.recorder != nil {
+		if err := s.recorder.StartTaskRun(ctx, run); err != nil {
+			log.Printf("error recording start of task %s: %v", task.Name, err)
+		}
+	}
+
+	// Store a copy so GetTasks never reads the run while it's updated.
+	started := *run
+	task.mu.Lock()
+	task.lastRun = &started
+	task.mu.Unlock()
+}
+
+// finishRun records the outcome of a run.
+func (s *Scheduler) finishRun(task *Task, run *data.TaskRun) {
+	finished := *run
+	task.mu.Lock()
+	task.lastRun = &finished
+	if finished.Status == data.TaskRunFailed {
+		task.lastError = &finished
+	}
+	task.mu.Unlock()
+
+	recorder := s.recorder
+	if recorder == nil || run.ID == 0 {
+		return
+	}
+	// Record the outcome even if the run was cancelled by Stop.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := recorder.FinishTaskRun(ctx, &finished); err != nil {
+		log.Printf("error recording end of task %s: %v", task.Name, err)
+	}
+}
+
+// setNextRun records when the task is next due.
+func (t *Task) setNextRun(at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextRun = at
+}
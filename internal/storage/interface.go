@@ -3,6 +3,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/omnipulse/omnipulse/internal/data"
 )
@@ -30,6 +31,41 @@ type Store interface {
 	// Comment operations
 	SaveComment(ctx context.Context, comment *data.Comment) error
 	GetComments(ctx context.Context, platform data.Platform, contentID string) ([]*data.Comment, error)
+	SaveCommentReply(ctx context.Context, parentID string, comment *data.Comment) error
+
+	// Comment inbox operations
+	ListInboxComments(ctx context.Context, filter data.InboxFilter) ([]*data.InboxComment, int, error)
+	GetInboxComment(ctx context.Context, id string) (*data.InboxComment, error)
+	UpdateCommentState(ctx context.Context, id string, update data.CommentStateUpdate) error
+
+	// Comment classification operations
+	ListUnlabeledComments(ctx context.Context, limit int) ([]*data.Comment, error)
+	ListLexiconLabeledComments(ctx context.Context, limit int) ([]*data.Comment, error)
+	SaveCommentLabels(ctx context.Context, labels []*data.CommentLabel) error
+	GetSentimentTrend(ctx context.Context, query data.SentimentQuery) ([]*data.SentimentPoint, error)
+
+	// Embedding and topic operations
+	ListUnembedded(ctx context.Context, model string, limit int) ([]*data.EmbeddingSource, error)
+	SaveEmbeddings(ctx context.Context, embeddings []*data.Embedding) error
+	ListEmbeddings(ctx context.Context, model string) ([]*data.Embedding, error)
+	ReplaceTopics(ctx context.Context, topics []*data.Topic) error
+	ListTopics(ctx context.Context) ([]*data.Topic, error)
+	GetTopicStats(ctx context.Context, dateRange data.DateRange, examples int) ([]*data.TopicStats, error)
+
+	// Chat operations
+	SaveConversation(ctx context.Context, conversation *data.Conversation) error
+	GetConversation(ctx context.Context, id string) (*data.Conversation, error)
+	ListConversations(ctx context.Context, limit int) ([]*data.Conversation, error)
+	SaveChatMessages(ctx context.Context, messages []*data.ChatMessage) error
+	GetChatMessages(ctx context.Context, conversationID string) ([]*data.ChatMessage, error)
+
+	// Content operations (cross-platform)
+	ListContent(ctx context.Context, query data.ContentQuery) ([]*data.ContentItem, int, error)
+	GetContentItem(ctx context.Context, platform data.Platform, id string) (*data.ContentItem, error)
+	GetContentRank(ctx context.Context, platform data.Platform, id string, sort data.ContentSort) (rank, total int, err error)
+	SaveContentSnapshot(ctx context.Context, snapshot *data.ContentSnapshot) error
+	GetContentSnapshots(ctx context.Context, platform data.Platform, contentID string, dateRange data.DateRange) ([]*data.ContentSnapshot, error)
+	GetEarlySnapshots(ctx context.Context, platform data.Platform, dateRange data.DateRange, window time.Duration) (map[string]*data.ContentSnapshot, error)
 
 	// Channel/User stats operations
 	SaveChannelStats(ctx context.Context, stats *data.ChannelStats) error
@@ -43,6 +79,15 @@ type Store interface {
 	SaveInsight(ctx context.Context, insight *data.Insight) error
 	GetInsights(ctx context.Context, platform data.Platform, limit int) ([]*data.Insight, error)
 	GetRecentInsights(ctx context.Context, limit int) ([]*data.Insight, error)
+	LinkInsightContent(ctx context.Context, insightID string, platform data.Platform, contentID string) error
+	GetContentInsights(ctx context.Context, platform data.Platform, contentID string, limit int) ([]*data.Insight, error)
+	SaveInsightDetail(ctx context.Context, detail *data.InsightDetail) error
+	GetInsightDetails(ctx context.Context, insightIDs []string) (map[string]*data.InsightDetail, error)
+	GetInsight(ctx context.Context, id string) (*data.Insight, error)
+	ListInsights(ctx context.Context, filter data.InsightFilter) ([]*data.Insight, error)
+	UpdateInsightFeedback(ctx context.Context, id string, update data.InsightFeedbackUpdate) error
+	GetInsightFeedback(ctx context.Context, insightIDs []string) (map[string]*data.InsightFeedback, error)
+	GetRatedInsights(ctx context.Context, rating, limit int) ([]*data.Insight, error)
 
 	// Analytics summary operations
 	GetAnalyticsSummary(ctx context.Context, dateRange data.DateRange) (*data.AnalyticsSummary, error)
@@ -50,6 +95,61 @@ type Store interface {
 	// Trend operations
 	GetTrendData(ctx context.Context, platform data.Platform, metric string, days int) (*data.TrendData, error)
 
+	// Metric series operations
+	GetMetricSeries(ctx context.Context, platform data.Platform, metric string, dateRange data.DateRange) ([]data.DataPoint, error)
+	GetMetricStart(ctx context.Context, platform data.Platform, metric string) (time.Time, error)
+
+	// Anomaly operations
+	ReplaceAnomalies(ctx context.Context, platform data.Platform, metric string, dateRange data.DateRange, anomalies []*data.Anomaly) error
+	GetAnomalies(ctx context.Context, query data.AnomalyQuery) ([]*data.Anomaly, error)
+
+	// Goal operations
+	CreateGoal(ctx context.Context, goal *data.Goal) error
+	GetGoal(ctx context.Context, id int64) (*data.Goal, error)
+	ListGoals(ctx context.Context, platform data.Platform) ([]*data.Goal, error)
+	DeleteGoal(ctx context.Context, id int64) error
+
+	// Content entity operations
+	SaveContentEntities(ctx context.Context, platform data.Platform, contentID string, entities []data.ContentEntity) error
+	ListUnscannedContent(ctx context.Context, platform data.Platform, limit int) ([]*data.ContentItem, error)
+	GetEntityStats(ctx context.Context, query data.EntityQuery) ([]*data.EntityStats, error)
+	ListContentEntities(ctx context.Context, entityType data.EntityType, dateRange data.DateRange) ([]data.ContentEntity, error)
+
+	// Campaign operations
+	CreateCampaign(ctx context.Context, campaign *data.Campaign, members []*data.CampaignMember) error
+	GetCampaign(ctx context.Context, id int64) (*data.Campaign, error)
+	ListCampaigns(ctx context.Context) ([]*data.Campaign, error)
+	DeleteCampaign(ctx context.Context, id int64) error
+	AddCampaignContent(ctx context.Context, members []*data.CampaignMember) error
+	RemoveCampaignContent(ctx context.Context, campaignID int64, platform data.Platform, contentID string) error
+	GetCampaignContent(ctx context.Context, campaignID int64) ([]*data.CampaignItem, error)
+	ListCampaignMembers(ctx context.Context) ([]*data.CampaignMember, error)
+
+	// Search operations
+	Search(ctx context.Context, query data.SearchQuery) ([]*data.SearchResult, error)
+
+	// Task run history operations
+	StartTaskRun(ctx context.Context, run *data.TaskRun) error
+	FinishTaskRun(ctx context.Context, run *data.TaskRun) error
+	GetTaskRuns(ctx context.Context, task string, limit int) ([]*data.TaskRun, error)
+	GetLatestTaskRuns(ctx context.Context) ([]*data.TaskRun, error)
+
+	// Backfill job operations
+	CreateBackfillJob(ctx context.Context, job *data.BackfillJob) error
+	UpdateBackfillJob(ctx context.Context, job *data.BackfillJob) error
+	GetBackfillJobs(ctx context.Context, limit int) ([]*data.BackfillJob, error)
+	GetActiveBackfillJobs(ctx context.Context) ([]*data.BackfillJob, error)
+
+	// Leader election operations
+	AcquireLease(ctx context.Context, name, holder, address string, ttl time.Duration) (*data.Lease, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+	GetLease(ctx context.Context, name string) (*data.Lease, error)
+
+	// Settings operations
+	GetSetting(ctx context.Context, key string) (*data.Setting, error)
+	SaveSetting(ctx context.Context, key, value string) error
+	DeleteSetting(ctx context.Context, key string) error
+
 	// Database management
 	Migrate(ctx context.Context) error
 	Close() error
@@ -0,0 +1,91 @@
+-- OmniPulse Content Detail Schema
+-- Migration: 0002_content_detail.sql
+-- Description: Per-content metric history, a unified content view and
+-- links between insights and the content they discuss
+
+-- =============================================================================
+-- Content Metrics History (For per-item trend charts)
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS content_metrics_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    platform TEXT NOT NULL CHECK(platform IN ('youtube', 'x', 'linkedin')),
+    content_id TEXT NOT NULL,
+    views INTEGER DEFAULT 0,
+    likes INTEGER DEFAULT 0,
+    comments INTEGER DEFAULT 0,
+    shares INTEGER DEFAULT 0,
+    recorded_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_metrics_content
+ON content_metrics_history(platform, content_id, recorded_at);
+
+-- =============================================================================
+-- Insight Content Links
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS insight_content (
+    insight_id TEXT NOT NULL REFERENCES insights(id) ON DELETE CASCADE,
+    platform TEXT NOT NULL CHECK(platform IN ('youtube', 'x', 'linkedin')),
+    content_id TEXT NOT NULL,
+    PRIMARY KEY (insight_id, platform, content_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_insight_content_content
+ON insight_content(platform, content_id);
+
+-- =============================================================================
+-- Unified Content View (For cross-platform listing and ranking)
+-- =============================================================================
+
+-- Views are YouTube views or X/LinkedIn impressions. Engagement rate is
+-- engagements per view, matching data.ContentItem.
+CREATE VIEW IF NOT EXISTS content_items AS
+SELECT
+    'youtube' AS platform,
+    id,
+    title,
+    COALESCE(description, '') AS body,
+    published_at,
+    view_count AS views,
+    like_count AS likes,
+    comment_count AS comments,
+    0 AS shares,
+    CASE WHEN view_count > 0
+        THEN CAST(like_count + comment_count AS REAL) / view_count
+        ELSE 0 END AS engagement_rate
+FROM youtube_videos
+UNION ALL
+SELECT
+    'x',
+    id,
+    substr(text, 1, 100),
+    text,
+    created_at,
+    impression_count,
+    like_count,
+    reply_count,
+    retweet_count + quote_count,
+    CASE WHEN impression_count > 0
+        THEN CAST(like_count + reply_count + retweet_count + quote_count AS REAL) / impression_count
+        ELSE 0 END
+FROM x_tweets
+UNION ALL
+SELECT
+    'linkedin',
+    id,
+    substr(text, 1, 100),
+    text,
+    created_at,
+    impression_count,
+    like_count,
+    comment_count,
+    share_count,
+    CASE WHEN impression_count > 0
+        THEN CAST(like_count + comment_count + share_count AS REAL) / impression_count
+        ELSE 0 END
+FROM linkedin_posts;
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (2, '0002_content_detail.sql');
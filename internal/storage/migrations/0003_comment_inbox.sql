@@ -0,0 +1,43 @@
+-- OmniPulse Comment Inbox Schema
+-- Migration: 0003_comment_inbox.sql
+-- Description: Comment threading, per-comment triage state and labels
+
+-- =============================================================================
+-- Comment Threading
+-- =============================================================================
+
+-- Top-level comments have a NULL parent_id
+ALTER TABLE comments ADD COLUMN parent_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id
+ON comments(parent_id);
+
+-- =============================================================================
+-- Comment Triage State
+-- =============================================================================
+
+-- Kept separate from comments so re-fetching a comment never resets
+-- its read/archived/flagged state
+CREATE TABLE IF NOT EXISTS comment_state (
+    comment_id TEXT PRIMARY KEY REFERENCES comments(id) ON DELETE CASCADE,
+    read_at DATETIME,
+    archived_at DATETIME,
+    flagged INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- =============================================================================
+-- Comment Labels
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS comment_labels (
+    comment_id TEXT PRIMARY KEY REFERENCES comments(id) ON DELETE CASCADE,
+    sentiment TEXT CHECK(sentiment IN ('positive', 'neutral', 'negative')),
+    labeled_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_labels_sentiment
+ON comment_labels(sentiment);
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (3, '0003_comment_inbox.sql');
@@ -0,0 +1,151 @@
+-- OmniPulse Full-Text Search Schema
+-- Migration: 0004_search.sql
+-- Description: FTS5 indexes over content, comments and insights
+
+-- Each index is an external-content FTS5 table keyed by the source table's
+-- rowid, kept in sync by triggers. Writers must update rows in place
+-- (INSERT ... ON CONFLICT DO UPDATE) rather than INSERT OR REPLACE, which
+-- deletes rows without firing the delete triggers.
+
+-- =============================================================================
+-- YouTube Videos
+-- =============================================================================
+
+CREATE VIRTUAL TABLE IF NOT EXISTS youtube_videos_fts USING fts5(
+    title,
+    description,
+    content='youtube_videos',
+    content_rowid='rowid',
+    tokenize='porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS youtube_videos_fts_insert AFTER INSERT ON youtube_videos BEGIN
+    INSERT INTO youtube_videos_fts (rowid, title, description)
+    VALUES (new.rowid, new.title, new.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS youtube_videos_fts_delete AFTER DELETE ON youtube_videos BEGIN
+    INSERT INTO youtube_videos_fts (youtube_videos_fts, rowid, title, description)
+    VALUES ('delete', old.rowid, old.title, old.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS youtube_videos_fts_update AFTER UPDATE OF title, description ON youtube_videos BEGIN
+    INSERT INTO youtube_videos_fts (youtube_videos_fts, rowid, title, description)
+    VALUES ('delete', old.rowid, old.title, old.description);
+    INSERT INTO youtube_videos_fts (rowid, title, description)
+    VALUES (new.rowid, new.title, new.description);
+END;
+
+-- =============================================================================
+-- X Tweets
+-- =============================================================================
+
+CREATE VIRTUAL TABLE IF NOT EXISTS x_tweets_fts USING fts5(
+    text,
+    content='x_tweets',
+    content_rowid='rowid',
+    tokenize='porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS x_tweets_fts_insert AFTER INSERT ON x_tweets BEGIN
+    INSERT INTO x_tweets_fts (rowid, text) VALUES (new.rowid, new.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS x_tweets_fts_delete AFTER DELETE ON x_tweets BEGIN
+    INSERT INTO x_tweets_fts (x_tweets_fts, rowid, text) VALUES ('delete', old.rowid, old.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS x_tweets_fts_update AFTER UPDATE OF text ON x_tweets BEGIN
+    INSERT INTO x_tweets_fts (x_tweets_fts, rowid, text) VALUES ('delete', old.rowid, old.text);
+    INSERT INTO x_tweets_fts (rowid, text) VALUES (new.rowid, new.text);
+END;
+
+-- =============================================================================
+-- LinkedIn Posts
+-- =============================================================================
+
+CREATE VIRTUAL TABLE IF NOT EXISTS linkedin_posts_fts USING fts5(
+    text,
+    content='linkedin_posts',
+    content_rowid='rowid',
+    tokenize='porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS linkedin_posts_fts_insert AFTER INSERT ON linkedin_posts BEGIN
+    INSERT INTO linkedin_posts_fts (rowid, text) VALUES (new.rowid, new.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS linkedin_posts_fts_delete AFTER DELETE ON linkedin_posts BEGIN
+    INSERT INTO linkedin_posts_fts (linkedin_posts_fts, rowid, text) VALUES ('delete', old.rowid, old.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS linkedin_posts_fts_update AFTER UPDATE OF text ON linkedin_posts BEGIN
+    INSERT INTO linkedin_posts_fts (linkedin_posts_fts, rowid, text) VALUES ('delete', old.rowid, old.text);
+    INSERT INTO linkedin_posts_fts (rowid, text) VALUES (new.rowid, new.text);
+END;
+
+-- =============================================================================
+-- Comments
+-- =============================================================================
+
+CREATE VIRTUAL TABLE IF NOT EXISTS comments_fts USING fts5(
+    text,
+    content='comments',
+    content_rowid='rowid',
+    tokenize='porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS comments_fts_insert AFTER INSERT ON comments BEGIN
+    INSERT INTO comments_fts (rowid, text) VALUES (new.rowid, new.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS comments_fts_delete AFTER DELETE ON comments BEGIN
+    INSERT INTO comments_fts (comments_fts, rowid, text) VALUES ('delete', old.rowid, old.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS comments_fts_update AFTER UPDATE OF text ON comments BEGIN
+    INSERT INTO comments_fts (comments_fts, rowid, text) VALUES ('delete', old.rowid, old.text);
+    INSERT INTO comments_fts (rowid, text) VALUES (new.rowid, new.text);
+END;
+
+-- =============================================================================
+-- AI Insights
+-- =============================================================================
+
+CREATE VIRTUAL TABLE IF NOT EXISTS insights_fts USING fts5(
+    title,
+    description,
+    content='insights',
+    content_rowid='rowid',
+    tokenize='porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS insights_fts_insert AFTER INSERT ON insights BEGIN
+    INSERT INTO insights_fts (rowid, title, description)
+    VALUES (new.rowid, new.title, new.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS insights_fts_delete AFTER DELETE ON insights BEGIN
+    INSERT INTO insights_fts (insights_fts, rowid, title, description)
+    VALUES ('delete', old.rowid, old.title, old.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS insights_fts_update AFTER UPDATE OF title, description ON insights BEGIN
+    INSERT INTO insights_fts (insights_fts, rowid, title, description)
+    VALUES ('delete', old.rowid, old.title, old.description);
+    INSERT INTO insights_fts (rowid, title, description)
+    VALUES (new.rowid, new.title, new.description);
+END;
+
+-- =============================================================================
+-- Index Existing Rows
+-- =============================================================================
+
+INSERT INTO youtube_videos_fts (youtube_videos_fts) VALUES ('rebuild');
+INSERT INTO x_tweets_fts (x_tweets_fts) VALUES ('rebuild');
+INSERT INTO linkedin_posts_fts (linkedin_posts_fts) VALUES ('rebuild');
+INSERT INTO comments_fts (comments_fts) VALUES ('rebuild');
+INSERT INTO insights_fts (insights_fts) VALUES ('rebuild');
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (4, '0004_search.sql');
@@ -0,0 +1,29 @@
+-- OmniPulse Task Run History Schema
+-- Migration: 0005_task_runs.sql
+-- Description: Record every run of a scheduled task
+
+-- =============================================================================
+-- Task Runs
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS task_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task TEXT NOT NULL,
+    status TEXT NOT NULL CHECK(status IN ('running', 'success', 'failed', 'cancelled')),
+    manual INTEGER NOT NULL DEFAULT 0,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    items_fetched INTEGER NOT NULL DEFAULT 0,
+    api_units INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_runs_task_started
+ON task_runs(task, started_at);
+
+CREATE INDEX IF NOT EXISTS idx_task_runs_started_at
+ON task_runs(started_at);
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (5, '0005_task_runs.sql');
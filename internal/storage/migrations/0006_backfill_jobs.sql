@@ -0,0 +1,29 @@
+-- OmniPulse Backfill Jobs Schema
+-- Migration: 0006_backfill_jobs.sql
+-- Description: Resumable historical backfill jobs
+
+-- =============================================================================
+-- Backfill Jobs
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS backfill_jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    platform TEXT NOT NULL CHECK(platform IN ('youtube', 'x', 'linkedin')),
+    status TEXT NOT NULL CHECK(status IN ('pending', 'running', 'completed', 'failed')),
+    cursor TEXT,
+    pages INTEGER NOT NULL DEFAULT 0,
+    items INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    units_used INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME
+);
+
+-- At most one unfinished backfill per platform
+CREATE UNIQUE INDEX IF NOT EXISTS idx_backfill_jobs_active_platform
+ON backfill_jobs(platform) WHERE status IN ('pending', 'running');
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (6, '0006_backfill_jobs.sql');
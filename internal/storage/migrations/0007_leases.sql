@@ -0,0 +1,22 @@
+-- OmniPulse Leases Schema
+-- Migration: 0007_leases.sql
+-- Description: Leases for leader election between replicas
+
+-- =============================================================================
+-- Leases
+-- =============================================================================
+
+-- Times are Unix milliseconds so expiry can be compared in SQL regardless
+-- of how the driver formats DATETIME values.
+CREATE TABLE IF NOT EXISTS leases (
+    name TEXT PRIMARY KEY,
+    holder TEXT NOT NULL,
+    address TEXT,
+    token INTEGER NOT NULL,
+    acquired_at INTEGER NOT NULL,
+    renewed_at INTEGER NOT NULL,
+    expires_at INTEGER NOT NULL
+);
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (7, '0007_leases.sql');
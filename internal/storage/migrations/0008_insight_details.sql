@@ -0,0 +1,16 @@
+-- OmniPulse Insight Details Schema
+-- Migration: 0008_insight_details.sql
+-- Description: Evidence metrics and suggested actions for structured insights
+
+-- =============================================================================
+-- Insight Details
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS insight_details (
+    insight_id TEXT PRIMARY KEY REFERENCES insights(id) ON DELETE CASCADE,
+    evidence TEXT NOT NULL DEFAULT '[]', -- JSON array of data.InsightEvidence
+    actions TEXT NOT NULL DEFAULT '[]'   -- JSON array of strings
+);
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (8, '0008_insight_details.sql');
@@ -0,0 +1,21 @@
+-- OmniPulse Insight Feedback Schema
+-- Migration: 0009_insight_feedback.sql
+-- Description: Ratings and dismissals of generated insights
+
+-- =============================================================================
+-- Insight Feedback
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS insight_feedback (
+    insight_id TEXT PRIMARY KEY REFERENCES insights(id) ON DELETE CASCADE,
+    rating INTEGER NOT NULL DEFAULT 0 CHECK(rating IN (-1, 0, 1)),
+    dismissed_at DATETIME, -- NULL unless dismissed
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Finding rated insights to use as prompt examples
+CREATE INDEX IF NOT EXISTS idx_insight_feedback_rating
+ON insight_feedback(rating, updated_at);
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (9, '0009_insight_feedback.sql');
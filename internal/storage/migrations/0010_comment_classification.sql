@@ -0,0 +1,26 @@
+-- OmniPulse Comment Classification Schema
+-- Migration: 0010_comment_classification.sql
+-- Description: Intent, language and sentiment score labels for comments
+
+-- =============================================================================
+-- Comment Labels
+-- =============================================================================
+
+-- Columns are nullable so labels written before this migration stay valid
+ALTER TABLE comment_labels ADD COLUMN intent TEXT
+    CHECK(intent IN ('question', 'praise', 'complaint', 'request', 'spam', 'other'));
+
+-- ISO 639-1 code, or 'und' when the language couldn't be determined
+ALTER TABLE comment_labels ADD COLUMN language TEXT;
+
+-- Sentiment strength from -1 (most negative) to 1 (most positive)
+ALTER TABLE comment_labels ADD COLUMN score REAL;
+
+-- What assigned the labels: 'llm' or the offline 'lexicon' fallback
+ALTER TABLE comment_labels ADD COLUMN source TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_comment_labels_intent
+ON comment_labels(intent);
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (10, '0010_comment_classification.sql');
@@ -0,0 +1,53 @@
+-- OmniPulse Topics Schema
+-- Migration: 0011_topics.sql
+-- Description: Text embeddings of content and comments, and the topics they cluster into
+
+-- =============================================================================
+-- Embeddings
+-- =============================================================================
+
+-- One vector per content item or comment. Items are re-embedded when the
+-- embedding model changes, since vectors from different models can't be
+-- compared.
+CREATE TABLE IF NOT EXISTS embeddings (
+    kind TEXT NOT NULL CHECK(kind IN ('content', 'comment')),
+    platform TEXT NOT NULL,
+    item_id TEXT NOT NULL,
+    model TEXT NOT NULL,
+    dims INTEGER NOT NULL,
+    vector BLOB NOT NULL, -- dims little-endian float32 values
+    text TEXT NOT NULL,   -- The embedded text, shown as a topic example
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (kind, platform, item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_embeddings_model
+ON embeddings(model);
+
+-- =============================================================================
+-- Topics
+-- =============================================================================
+
+-- Topics are replaced as a whole each time embeddings are clustered
+CREATE TABLE IF NOT EXISTS topics (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    label TEXT NOT NULL,
+    keywords TEXT NOT NULL DEFAULT '[]', -- JSON array
+    size INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS topic_members (
+    topic_id INTEGER NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK(kind IN ('content', 'comment')),
+    platform TEXT NOT NULL,
+    item_id TEXT NOT NULL,
+    similarity REAL NOT NULL DEFAULT 0, -- Cosine similarity to the topic's centre
+    PRIMARY KEY (kind, platform, item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_topic_members_topic
+ON topic_members(topic_id, similarity DESC);
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (11, '0011_topics.sql');
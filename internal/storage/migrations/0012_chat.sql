@@ -0,0 +1,37 @@
+-- OmniPulse Analytics Chat Schema
+-- Migration: 0012_chat.sql
+-- Description: Conversations with the analytics chat and their messages
+
+-- =============================================================================
+-- Chat Conversations
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS chat_conversations (
+    id TEXT PRIMARY KEY,
+    title TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_conversations_updated
+ON chat_conversations(updated_at DESC);
+
+-- =============================================================================
+-- Chat Messages
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS chat_messages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    conversation_id TEXT NOT NULL REFERENCES chat_conversations(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK(role IN ('user', 'assistant')),
+    content TEXT NOT NULL,
+    tool_calls TEXT NOT NULL DEFAULT '[]', -- JSON array of queries run for an answer
+    citations TEXT NOT NULL DEFAULT '[]',  -- JSON array of rows the answer cites
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_messages_conversation
+ON chat_messages(conversation_id, id);
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (12, '0012_chat.sql');
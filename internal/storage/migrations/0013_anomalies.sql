@@ -0,0 +1,30 @@
+-- OmniPulse Anomaly Schema
+-- Migration: 0013_anomalies.sql
+-- Description: Anomalies detected in daily platform metrics, stored so
+-- charts and prompts don't recompute them
+
+-- =============================================================================
+-- Metric Anomalies
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS metric_anomalies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    platform TEXT NOT NULL CHECK(platform IN ('youtube', 'x', 'linkedin')),
+    metric TEXT NOT NULL,
+    method TEXT NOT NULL CHECK(method IN ('mad', 'stl', 'changepoint')),
+    occurred_at DATETIME NOT NULL,
+    value REAL NOT NULL,
+    expected REAL NOT NULL,
+    expected_min REAL NOT NULL,
+    expected_max REAL NOT NULL,
+    type TEXT NOT NULL CHECK(type IN ('spike', 'drop', 'level_shift')),
+    severity TEXT NOT NULL CHECK(severity IN ('low', 'medium', 'high')),
+    detected_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(platform, metric, occurred_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_metric_anomalies_occurred
+ON metric_anomalies(occurred_at);
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (13, '0013_anomalies.sql');
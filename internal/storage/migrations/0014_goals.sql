@@ -0,0 +1,27 @@
+-- OmniPulse Goals Schema
+-- Migration: 0014_goals.sql
+-- Description: Targets for platform metrics to reach by a deadline
+
+-- =============================================================================
+-- Goals
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS goals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    platform TEXT NOT NULL CHECK(platform IN ('youtube', 'x', 'linkedin')),
+    metric TEXT NOT NULL,
+    target REAL NOT NULL,
+    baseline REAL NOT NULL DEFAULT 0,
+    start_date DATETIME NOT NULL,
+    deadline DATETIME NOT NULL,
+    note TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK(deadline >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_goals_deadline
+ON goals(deadline);
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (14, '0014_goals.sql');
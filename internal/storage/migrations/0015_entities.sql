@@ -0,0 +1,36 @@
+-- OmniPulse Content Entity Schema
+-- Migration: 0015_entities.sql
+-- Description: Hashtags, mentions and link domains extracted from content
+-- when it is saved, for ranking them by the performance of their content
+
+-- =============================================================================
+-- Content Entities
+-- =============================================================================
+
+-- value is the normalized form entities are grouped by; text is one way it
+-- was written, or a link's full URL.
+CREATE TABLE IF NOT EXISTS content_entities (
+    platform TEXT NOT NULL CHECK(platform IN ('youtube', 'x', 'linkedin')),
+    content_id TEXT NOT NULL,
+    type TEXT NOT NULL CHECK(type IN ('hashtag', 'mention', 'domain')),
+    value TEXT NOT NULL,
+    text TEXT NOT NULL,
+    PRIMARY KEY (platform, content_id, type, value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_entities_value
+ON content_entities(type, value);
+
+-- =============================================================================
+-- Entity Scans (So content without entities isn't scanned again)
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS content_entity_scans (
+    platform TEXT NOT NULL CHECK(platform IN ('youtube', 'x', 'linkedin')),
+    content_id TEXT NOT NULL,
+    scanned_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (platform, content_id)
+);
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (15, '0015_entities.sql');
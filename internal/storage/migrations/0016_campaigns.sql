@@ -0,0 +1,32 @@
+-- OmniPulse Campaigns Schema
+-- Migration: 0016_campaigns.sql
+-- Description: Campaigns grouping related content across platforms
+
+-- =============================================================================
+-- Campaigns
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS campaigns (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    note TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A content item can be in more than one campaign. source records whether
+-- it was added by hand or from a suggestion.
+CREATE TABLE IF NOT EXISTS campaign_members (
+    campaign_id INTEGER NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    platform TEXT NOT NULL CHECK(platform IN ('youtube', 'x', 'linkedin')),
+    content_id TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT 'manual' CHECK(source IN ('manual', 'link', 'utm', 'similar')),
+    added_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (campaign_id, platform, content_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_members_content
+ON campaign_members(platform, content_id);
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (16, '0016_campaigns.sql');
@@ -0,0 +1,250 @@
+-- OmniPulse Stable Search Keys
+-- Migration: 0017_search_keys.sql
+-- Description: Key the FTS5 indexes on stable integer IDs
+
+-- The FTS5 indexes from 0004_search.sql were keyed by the rowids of tables
+-- with TEXT primary keys. Those rowids are not stable: VACUUM may renumber
+-- them, leaving the indexes pointing at the wrong rows. search_keys gives
+-- each indexed row an INTEGER PRIMARY KEY, which VACUUM preserves, and
+-- each index now reads its columns through a view keyed on it.
+--
+-- Writers must still update rows in place (INSERT ... ON CONFLICT DO
+-- UPDATE) rather than INSERT OR REPLACE, which deletes rows without firing
+-- the delete triggers.
+
+CREATE TABLE IF NOT EXISTS search_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    source TEXT NOT NULL, -- Indexed table
+    key TEXT NOT NULL,    -- The row's primary key in source
+    UNIQUE(source, key)
+);
+
+-- =============================================================================
+-- YouTube Videos
+-- =============================================================================
+
+DROP TRIGGER IF EXISTS youtube_videos_fts_insert;
+DROP TRIGGER IF EXISTS youtube_videos_fts_delete;
+DROP TRIGGER IF EXISTS youtube_videos_fts_update;
+DROP TABLE IF EXISTS youtube_videos_fts;
+
+INSERT OR IGNORE INTO search_keys (source, key) SELECT 'youtube_videos', id FROM youtube_videos;
+
+CREATE VIEW IF NOT EXISTS youtube_videos_search AS
+SELECT k.id AS search_id, s.title, s.description
+FROM search_keys k
+JOIN youtube_videos s ON s.id = k.key
+WHERE k.source = 'youtube_videos';
+
+CREATE VIRTUAL TABLE IF NOT EXISTS youtube_videos_fts USING fts5(
+    title,
+    description,
+    content='youtube_videos_search',
+    content_rowid='search_id',
+    tokenize='porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS youtube_videos_fts_insert AFTER INSERT ON youtube_videos BEGIN
+    INSERT OR IGNORE INTO search_keys (source, key) VALUES ('youtube_videos', new.id);
+    INSERT INTO youtube_videos_fts (rowid, title, description)
+    VALUES ((SELECT id FROM search_keys WHERE source = 'youtube_videos' AND key = new.id), new.title, new.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS youtube_videos_fts_delete AFTER DELETE ON youtube_videos BEGIN
+    INSERT INTO youtube_videos_fts (youtube_videos_fts, rowid, title, description)
+    VALUES ('delete', (SELECT id FROM search_keys WHERE source = 'youtube_videos' AND key = old.id), old.title, old.description);
+    DELETE FROM search_keys WHERE source = 'youtube_videos' AND key = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS youtube_videos_fts_update AFTER UPDATE OF title, description ON youtube_videos BEGIN
+    INSERT INTO youtube_videos_fts (youtube_videos_fts, rowid, title, description)
+    VALUES ('delete', (SELECT id FROM search_keys WHERE source = 'youtube_videos' AND key = old.id), old.title, old.description);
+    INSERT INTO youtube_videos_fts (rowid, title, description)
+    VALUES ((SELECT id FROM search_keys WHERE source = 'youtube_videos' AND key = new.id), new.title, new.description);
+END;
+
+-- =============================================================================
+-- X Tweets
+-- =============================================================================
+
+DROP TRIGGER IF EXISTS x_tweets_fts_insert;
+DROP TRIGGER IF EXISTS x_tweets_fts_delete;
+DROP TRIGGER IF EXISTS x_tweets_fts_update;
+DROP TABLE IF EXISTS x_tweets_fts;
+
+INSERT OR IGNORE INTO search_keys (source, key) SELECT 'x_tweets', id FROM x_tweets;
+
+CREATE VIEW IF NOT EXISTS x_tweets_search AS
+SELECT k.id AS search_id, s.text
+FROM search_keys k
+JOIN x_tweets s ON s.id = k.key
+WHERE k.source = 'x_tweets';
+
+CREATE VIRTUAL TABLE IF NOT EXISTS x_tweets_fts USING fts5(
+    text,
+    content='x_tweets_search',
+    content_rowid='search_id',
+    tokenize='porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS x_tweets_fts_insert AFTER INSERT ON x_tweets BEGIN
+    INSERT OR IGNORE INTO search_keys (source, key) VALUES ('x_tweets', new.id);
+    INSERT INTO x_tweets_fts (rowid, text)
+    VALUES ((SELECT id FROM search_keys WHERE source = 'x_tweets' AND key = new.id), new.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS x_tweets_fts_delete AFTER DELETE ON x_tweets BEGIN
+    INSERT INTO x_tweets_fts (x_tweets_fts, rowid, text)
+    VALUES ('delete', (SELECT id FROM search_keys WHERE source = 'x_tweets' AND key = old.id), old.text);
+    DELETE FROM search_keys WHERE source = 'x_tweets' AND key = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS x_tweets_fts_update AFTER UPDATE OF text ON x_tweets BEGIN
+    INSERT INTO x_tweets_fts (x_tweets_fts, rowid, text)
+    VALUES ('delete', (SELECT id FROM search_keys WHERE source = 'x_tweets' AND key = old.id), old.text);
+    INSERT INTO x_tweets_fts (rowid, text)
+    VALUES ((SELECT id FROM search_keys WHERE source = 'x_tweets' AND key = new.id), new.text);
+END;
+
+-- =============================================================================
+-- LinkedIn Posts
+-- =============================================================================
+
+DROP TRIGGER IF EXISTS linkedin_posts_fts_insert;
+DROP TRIGGER IF EXISTS linkedin_posts_fts_delete;
+DROP TRIGGER IF EXISTS linkedin_posts_fts_update;
+DROP TABLE IF EXISTS linkedin_posts_fts;
+
+INSERT OR IGNORE INTO search_keys (source, key) SELECT 'linkedin_posts', id FROM linkedin_posts;
+
+CREATE VIEW IF NOT EXISTS linkedin_posts_search AS
+SELECT k.id AS search_id, s.text
+FROM search_keys k
+JOIN linkedin_posts s ON s.id = k.key
+WHERE k.source = 'linkedin_posts';
+
+CREATE VIRTUAL TABLE IF NOT EXISTS linkedin_posts_fts USING fts5(
+    text,
+    content='linkedin_posts_search',
+    content_rowid='search_id',
+    tokenize='porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS linkedin_posts_fts_insert AFTER INSERT ON linkedin_posts BEGIN
+    INSERT OR IGNORE INTO search_keys (source, key) VALUES ('linkedin_posts', new.id);
+    INSERT INTO linkedin_posts_fts (rowid, text)
+    VALUES ((SELECT id FROM search_keys WHERE source = 'linkedin_posts' AND key = new.id), new.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS linkedin_posts_fts_delete AFTER DELETE ON linkedin_posts BEGIN
+    INSERT INTO linkedin_posts_fts (linkedin_posts_fts, rowid, text)
+    VALUES ('delete', (SELECT id FROM search_keys WHERE source = 'linkedin_posts' AND key = old.id), old.text);
+    DELETE FROM search_keys WHERE source = 'linkedin_posts' AND key = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS linkedin_posts_fts_update AFTER UPDATE OF text ON linkedin_posts BEGIN
+    INSERT INTO linkedin_posts_fts (linkedin_posts_fts, rowid, text)
+    VALUES ('delete', (SELECT id FROM search_keys WHERE source = 'linkedin_posts' AND key = old.id), old.text);
+    INSERT INTO linkedin_posts_fts (rowid, text)
+    VALUES ((SELECT id FROM search_keys WHERE source = 'linkedin_posts' AND key = new.id), new.text);
+END;
+
+-- =============================================================================
+-- Comments
+-- =============================================================================
+
+DROP TRIGGER IF EXISTS comments_fts_insert;
+DROP TRIGGER IF EXISTS comments_fts_delete;
+DROP TRIGGER IF EXISTS comments_fts_update;
+DROP TABLE IF EXISTS comments_fts;
+
+INSERT OR IGNORE INTO search_keys (source, key) SELECT 'comments', id FROM comments;
+
+CREATE VIEW IF NOT EXISTS comments_search AS
+SELECT k.id AS search_id, s.text
+FROM search_keys k
+JOIN comments s ON s.id = k.key
+WHERE k.source = 'comments';
+
+CREATE VIRTUAL TABLE IF NOT EXISTS comments_fts USING fts5(
+    text,
+    content='comments_search',
+    content_rowid='search_id',
+    tokenize='porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS comments_fts_insert AFTER INSERT ON comments BEGIN
+    INSERT OR IGNORE INTO search_keys (source, key) VALUES ('comments', new.id);
+    INSERT INTO comments_fts (rowid, text)
+    VALUES ((SELECT id FROM search_keys WHERE source = 'comments' AND key = new.id), new.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS comments_fts_delete AFTER DELETE ON comments BEGIN
+    INSERT INTO comments_fts (comments_fts, rowid, text)
+    VALUES ('delete', (SELECT id FROM search_keys WHERE source = 'comments' AND key = old.id), old.text);
+    DELETE FROM search_keys WHERE source = 'comments' AND key = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS comments_fts_update AFTER UPDATE OF text ON comments BEGIN
+    INSERT INTO comments_fts (comments_fts, rowid, text)
+    VALUES ('delete', (SELECT id FROM search_keys WHERE source = 'comments' AND key = old.id), old.text);
+    INSERT INTO comments_fts (rowid, text)
+    VALUES ((SELECT id FROM search_keys WHERE source = 'comments' AND key = new.id), new.text);
+END;
+
+-- =============================================================================
+-- AI Insights
+-- =============================================================================
+
+DROP TRIGGER IF EXISTS insights_fts_insert;
+DROP TRIGGER IF EXISTS insights_fts_delete;
+DROP TRIGGER IF EXISTS insights_fts_update;
+DROP TABLE IF EXISTS insights_fts;
+
+INSERT OR IGNORE INTO search_keys (source, key) SELECT 'insights', id FROM insights;
+
+CREATE VIEW IF NOT EXISTS insights_search AS
+SELECT k.id AS search_id, s.title, s.description
+FROM search_keys k
+JOIN insights s ON s.id = k.key
+WHERE k.source = 'insights';
+
+CREATE VIRTUAL TABLE IF NOT EXISTS insights_fts USING fts5(
+    title,
+    description,
+    content='insights_search',
+    content_rowid='search_id',
+    tokenize='porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS insights_fts_insert AFTER INSERT ON insights BEGIN
+    INSERT OR IGNORE INTO search_keys (source, key) VALUES ('insights', new.id);
+    INSERT INTO insights_fts (rowid, title, description)
+    VALUES ((SELECT id FROM search_keys WHERE source = 'insights' AND key = new.id), new.title, new.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS insights_fts_delete AFTER DELETE ON insights BEGIN
+    INSERT INTO insights_fts (insights_fts, rowid, title, description)
+    VALUES ('delete', (SELECT id FROM search_keys WHERE source = 'insights' AND key = old.id), old.title, old.description);
+    DELETE FROM search_keys WHERE source = 'insights' AND key = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS insights_fts_update AFTER UPDATE OF title, description ON insights BEGIN
+    INSERT INTO insights_fts (insights_fts, rowid, title, description)
+    VALUES ('delete', (SELECT id FROM search_keys WHERE source = 'insights' AND key = old.id), old.title, old.description);
+    INSERT INTO insights_fts (rowid, title, description)
+    VALUES ((SELECT id FROM search_keys WHERE source = 'insights' AND key = new.id), new.title, new.description);
+END;
+
+-- =============================================================================
+-- Index Existing Rows
+-- =============================================================================
+
+INSERT INTO youtube_videos_fts (youtube_videos_fts) VALUES ('rebuild');
+INSERT INTO x_tweets_fts (x_tweets_fts) VALUES ('rebuild');
+INSERT INTO linkedin_posts_fts (linkedin_posts_fts) VALUES ('rebuild');
+INSERT INTO comments_fts (comments_fts) VALUES ('rebuild');
+INSERT INTO insights_fts (insights_fts) VALUES ('rebuild');
+
+-- Record this migration
+INSERT INTO schema_migrations (version, name) VALUES (17, '0017_search_keys.sql');
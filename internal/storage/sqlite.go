@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/omnipulse/omnipulse/internal/data"
 )
@@ -132,9 +133,22 @@ func (s *SQLiteStore) GetLinkedInPostsByDateRange(ctx context.Context, dateRange
 	return nil, nil
 }
 
-// SaveComment saves a comment to the database.
+// SaveComment saves a top-level comment to the database. Saving a comment
+// again updates its text and likes; a parent set by SaveCommentReply is
+// kept.
 func (s *SQLiteStore) SaveComment(ctx context.Context, comment *data.Comment) error {
-	// TODO: Implement
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO comments (id, platform, content_id, author_id, author_name, text, like_count, created_at, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(id) DO UPDATE SET
+			text = excluded.text,
+			like_count = excluded.like_count,
+			fetched_at = excluded.fetched_at`,
+		comment.ID, string(comment.Platform), comment.ContentID, comment.AuthorID,
+		comment.AuthorName, comment.Text, comment.LikeCount, comment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("saving comment: %w", err)
+	}
 	return nil
 }
 
@@ -180,32 +194,63 @@ func (s *SQLiteStore) GetLatestLinkedInProfileStats(ctx context.Context) (*data.
 	return nil, nil
 }
 
-// SaveInsight saves an AI-generated insight.
-func (s *SQLiteStore) SaveInsight(ctx context.Context, insight *data.Insight) error {
+// GetAnalyticsSummary retrieves an analytics summary for a date range.
+func (s *SQLiteStore) GetAnalyticsSummary(ctx context.Context, dateRange data.DateRange) (*data.AnalyticsSummary, error) {
 	// TODO: Implement
-	return nil
+	return nil, nil
 }
 
-// GetInsights retrieves insights for a platform.
-func (s *SQLiteStore) GetInsights(ctx context.Context, platform data.Platform, limit int) ([]*data.Insight, error) {
+// GetTrendData retrieves trend data for a specific metric.
+func (s *SQLiteStore) GetTrendData(ctx context.Context, platform data.Platform, metric string, days int) (*data.TrendData, error) {
 	// TODO: Implement
 	return nil, nil
 }
 
-// GetRecentInsights retrieves the most recent insights across all platforms.
-func (s *SQLiteStore) GetRecentInsights(ctx context.Context, limit int) ([]*data.Insight, error) {
-	// TODO: Implement
-	return nil, nil
+// sqlTime scans SQLite DATETIME values, which may be returned as
+// time.Time, text or Unix seconds depending on the driver and whether
+// the column's declared type survives (e.g. through a view).
+type sqlTime struct {
+	time.Time
 }
 
-// GetAnalyticsSummary retrieves an analytics summary for a date range.
-func (s *SQLiteStore) GetAnalyticsSummary(ctx context.Context, dateRange data.DateRange) (*data.AnalyticsSummary, error) {
-	// TODO: Implement
-	return nil, nil
+// sqliteTimeLayouts are the text formats SQLite and its drivers produce.
+var sqliteTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05Z",
+	"2006-01-02",
 }
 
-// GetTrendData retrieves trend data for a specific metric.
-func (s *SQLiteStore) GetTrendData(ctx context.Context, platform data.Platform, metric string, days int) (*data.TrendData, error) {
-	// TODO: Implement
-	return nil, nil
+// Scan implements sql.Scanner.
+func (t *sqlTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		t.Time = time.Time{}
+		return nil
+	case time.Time:
+		t.Time = v
+		return nil
+	case int64:
+		t.Time = time.Unix(v, 0)
+		return nil
+	case []byte:
+		return t.parse(string(v))
+	case string:
+		return t.parse(v)
+	}
+	return fmt.Errorf("cannot scan %T into time", value)
+}
+
+// parse parses a textual SQLite timestamp.
+func (t *sqlTime) parse(s string) error {
+	for _, layout := range sqliteTimeLayouts {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("parsing time %q", s)
 }
@@ -0,0 +1,121 @@
+// Package storage provides SQLite persistence for detected metric
+// anomalies.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// ReplaceAnomalies replaces the stored anomalies of a platform metric
+// within dateRange with anomalies, so re-running detection, possibly with
+// another method, doesn't leave stale ones behind.
+func (s *SQLiteStore) ReplaceAnomalies(ctx context.Context, platform data.Platform, metric string, dateRange data.DateRange, anomalies []*data.Anomaly) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM metric_anomalies
+		WHERE platform = ? AND metric = ? AND occurred_at >= ? AND occurred_at < ?`,
+		string(platform), metric, dateRange.Start, dateRange.End); err != nil {
+		return fmt.Errorf("deleting anomalies: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO metric_anomalies (platform, metric, method, occurred_at, value, expected,
+			expected_min, expected_max, type, severity, detected_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("preparing anomaly insert: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, a := range anomalies {
+		if a.DetectedAt.IsZero() {
+			a.DetectedAt = now
+		}
+		result, err := stmt.ExecContext(ctx, string(platform), metric, a.Method, a.Timestamp, a.Value,
+			a.Expected, a.ExpectedMin, a.ExpectedMax, a.Type, a.Severity, a.DetectedAt)
+		if err != nil {
+			return fmt.Errorf("saving anomaly: %w", err)
+		}
+		if a.ID, err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("getting anomaly ID: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing anomalies: %w", err)
+	}
+	return nil
+}
+
+// GetAnomalies returns the stored anomalies matching query in
+// chronological order.
+func (s *SQLiteStore) GetAnomalies(ctx context.Context, query data.AnomalyQuery) ([]*data.Anomaly, error) {
+	var where []string
+	var args []interface{}
+	if query.Platform != "" {
+		where = append(where, "platform = ?")
+		args = append(args, string(query.Platform))
+	}
+	if query.Metric != "" {
+		where = append(where, "metric = ?")
+		args = append(args, query.Metric)
+	}
+	if !query.DateRange.Start.IsZero() {
+		where = append(where, "occurred_at >= ?")
+		args = append(args, query.DateRange.Start)
+	}
+	if !query.DateRange.End.IsZero() {
+		where = append(where, "occurred_at < ?")
+		args = append(args, query.DateRange.End)
+	}
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = " WHERE " + strings.Join(where, " AND ")
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, platform, metric, method, occurred_at, value, expected, expected_min,
+			expected_max, type, severity, detected_at
+		FROM metric_anomalies`+whereClause+`
+		ORDER BY occurred_at, platform, metric
+		LIMIT ?`,
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying anomalies: %w", err)
+	}
+	defer rows.Close()
+
+	var anomalies []*data.Anomaly
+	for rows.Next() {
+		var a data.Anomaly
+		var platform string
+		var occurredAt, detectedAt sqlTime
+		if err := rows.Scan(&a.ID, &platform, &a.Metric, &a.Method, &occurredAt, &a.Value,
+			&a.Expected, &a.ExpectedMin, &a.ExpectedMax, &a.Type, &a.Severity, &detectedAt); err != nil {
+			return nil, fmt.Errorf("scanning anomaly: %w", err)
+		}
+		a.Platform = data.Platform(platform)
+		a.Timestamp = occurredAt.Time
+		a.DetectedAt = detectedAt.Time
+		anomalies = append(anomalies, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating anomalies: %w", err)
+	}
+	return anomalies, nil
+}
@@ -0,0 +1,92 @@
+// Package storage provides SQLite persistence for backfill jobs.
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+const backfillJobColumns = `id, platform, status, COALESCE(cursor, ''), pages, items, total,
+	units_used, COALESCE(error, ''), created_at, updated_at, completed_at`
+
+// CreateBackfillJob saves a new backfill job and sets its ID. It fails if
+// the platform already has a pending or running job.
+func (s *SQLiteStore) CreateBackfillJob(ctx context.Context, job *data.BackfillJob) error {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO backfill_jobs (platform, status, cursor, total, created_at, updated_at)
+		VALUES (?, ?, NULLIF(?, ''), ?, ?, ?)`,
+		string(job.Platform), string(job.Status), job.Cursor, job.Total, job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("saving backfill job: %w", err)
+	}
+	if job.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("getting backfill job ID: %w", err)
+	}
+	return nil
+}
+
+// UpdateBackfillJob checkpoints a backfill job's state and cursor.
+func (s *SQLiteStore) UpdateBackfillJob(ctx context.Context, job *data.BackfillJob) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE backfill_jobs
+		SET status = ?, cursor = NULLIF(?, ''), pages = ?, items = ?, total = ?,
+		    units_used = ?, error = NULLIF(?, ''), updated_at = ?, completed_at = ?
+		WHERE id = ?`,
+		string(job.Status), job.Cursor, job.Pages, job.Items, job.Total,
+		job.UnitsUsed, job.Error, job.UpdatedAt, nullTime(!job.CompletedAt.IsZero(), job.CompletedAt), job.ID)
+	if err != nil {
+		return fmt.Errorf("updating backfill job: %w", err)
+	}
+	return nil
+}
+
+// GetBackfillJobs retrieves the most recent backfill jobs, newest first.
+func (s *SQLiteStore) GetBackfillJobs(ctx context.Context, limit int) ([]*data.BackfillJob, error) {
+	return s.queryBackfillJobs(ctx, `
+		SELECT `+backfillJobColumns+`
+		FROM backfill_jobs
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?`, limit)
+}
+
+// GetActiveBackfillJobs retrieves the pending and running backfill jobs,
+// oldest first.
+func (s *SQLiteStore) GetActiveBackfillJobs(ctx context.Context) ([]*data.BackfillJob, error) {
+	return s.queryBackfillJobs(ctx, `
+		SELECT `+backfillJobColumns+`
+		FROM backfill_jobs
+		WHERE status IN ('pending', 'running')
+		ORDER BY created_at, id`)
+}
+
+// queryBackfillJobs runs a query selecting backfillJobColumns.
+func (s *SQLiteStore) queryBackfillJobs(ctx context.Context, query string, args ...interface{}) ([]*data.BackfillJob, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying backfill jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*data.BackfillJob
+	for rows.Next() {
+		var job data.BackfillJob
+		var platform, status string
+		var createdAt, updatedAt, completedAt sqlTime
+		if err := rows.Scan(&job.ID, &platform, &status, &job.Cursor, &job.Pages, &job.Items,
+			&job.Total, &job.UnitsUsed, &job.Error, &createdAt, &updatedAt, &completedAt); err != nil {
+			return nil, fmt.Errorf("scanning backfill job: %w", err)
+		}
+		job.Platform = data.Platform(platform)
+		job.Status = data.BackfillStatus(status)
+		job.CreatedAt = createdAt.Time
+		job.UpdatedAt = updatedAt.Time
+		job.CompletedAt = completedAt.Time
+		jobs = append(jobs, &job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating backfill jobs: %w", err)
+	}
+	return jobs, nil
+}
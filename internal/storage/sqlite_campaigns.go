@@ -0,0 +1,278 @@
+// Package storage provides SQLite persistence for campaigns.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+const campaignColumns = `c.id, c.name, COALESCE(c.note, ''), c.created_at, c.updated_at,
+	(SELECT COUNT(*) FROM campaign_members m WHERE m.campaign_id = c.id)`
+
+// CreateCampaign saves a new campaign with its initial members and sets its
+// ID. The members' campaign IDs are set to it.
+func (s *SQLiteStore) CreateCampaign(ctx context.Context, campaign *data.Campaign, members []*data.CampaignMember) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if campaign.CreatedAt.IsZero() {
+		campaign.CreatedAt = now
+	}
+	campaign.UpdatedAt = campaign.CreatedAt
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO campaigns (name, note, created_at, updated_at)
+		VALUES (?, NULLIF(?, ''), ?, ?)`,
+		campaign.Name, campaign.Note, campaign.CreatedAt, campaign.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("saving campaign: %w", err)
+	}
+	if campaign.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("getting campaign ID: %w", err)
+	}
+
+	for _, m := range members {
+		m.CampaignID = campaign.ID
+	}
+	if err := insertCampaignMembers(ctx, tx, members, now); err != nil {
+		return err
+	}
+	campaign.Items = len(members)
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing campaign: %w", err)
+	}
+	return nil
+}
+
+// GetCampaign retrieves a campaign by ID, or nil if it doesn't exist.
+func (s *SQLiteStore) GetCampaign(ctx context.Context, id int64) (*data.Campaign, error) {
+	campaign, err := scanCampaign(s.db.QueryRowContext(ctx, `
+		SELECT `+campaignColumns+`
+		FROM campaigns c
+		WHERE c.id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting campaign: %w", err)
+	}
+	return campaign, nil
+}
+
+// ListCampaigns retrieves all campaigns, most recently updated first.
+func (s *SQLiteStore) ListCampaigns(ctx context.Context) ([]*data.Campaign, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+campaignColumns+`
+		FROM campaigns c
+		ORDER BY c.updated_at DESC, c.id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("querying campaigns: %w", err)
+	}
+	defer rows.Close()
+
+	var campaigns []*data.Campaign
+	for rows.Next() {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning campaign: %w", err)
+		}
+		campaigns = append(campaigns, campaign)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating campaigns: %w", err)
+	}
+	return campaigns, nil
+}
+
+// DeleteCampaign deletes a campaign and its members. The content items
+// themselves are kept.
+func (s *SQLiteStore) DeleteCampaign(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Members are deleted explicitly in case foreign keys aren't enforced.
+	if _, err := tx.ExecContext(ctx, "DELETE FROM campaign_members WHERE campaign_id = ?", id); err != nil {
+		return fmt.Errorf("deleting campaign members: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM campaigns WHERE id = ?", id); err != nil {
+		return fmt.Errorf("deleting campaign: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing campaign deletion: %w", err)
+	}
+	return nil
+}
+
+// AddCampaignContent adds content items to their campaigns. Items already
+// in a campaign keep their original source.
+func (s *SQLiteStore) AddCampaignContent(ctx context.Context, members []*data.CampaignMember) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertCampaignMembers(ctx, tx, members, time.Now()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing campaign content: %w", err)
+	}
+	return nil
+}
+
+// RemoveCampaignContent removes a content item from a campaign.
+func (s *SQLiteStore) RemoveCampaignContent(ctx context.Context, campaignID int64, platform data.Platform, contentID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM campaign_members
+		WHERE campaign_id = ? AND platform = ? AND content_id = ?`,
+		campaignID, string(platform), contentID); err != nil {
+		return fmt.Errorf("removing campaign content: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE campaigns SET updated_at = ? WHERE id = ?", time.Now(), campaignID); err != nil {
+		return fmt.Errorf("updating campaign: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing campaign content removal: %w", err)
+	}
+	return nil
+}
+
+// GetCampaignContent retrieves a campaign's content items, oldest first.
+// Members whose content is no longer stored are left out.
+func (s *SQLiteStore) GetCampaignContent(ctx context.Context, campaignID int64) ([]*data.CampaignItem, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT c.platform, c.id, c.title, c.body, c.published_at, c.views, c.likes,
+			c.comments, c.shares, c.engagement_rate, m.source, m.added_at
+		FROM campaign_members m
+		JOIN content_items c ON c.platform = m.platform AND c.id = m.content_id
+		WHERE m.campaign_id = ?
+		ORDER BY c.published_at, c.id`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("querying campaign content: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*data.CampaignItem
+	for rows.Next() {
+		var content data.ContentItem
+		var platform, source string
+		var publishedAt, addedAt sqlTime
+		if err := rows.Scan(&platform, &content.ID, &content.Title, &content.Body, &publishedAt,
+			&content.Views, &content.Likes, &content.Comments, &content.Shares, &content.EngagementRate,
+			&source, &addedAt); err != nil {
+			return nil, fmt.Errorf("scanning campaign content: %w", err)
+		}
+		content.Platform = data.Platform(platform)
+		content.PublishedAt = publishedAt.Time
+		item := data.CampaignItem{
+			ContentItem: &content,
+			Source:      data.CampaignSource(source),
+			AddedAt:     addedAt.Time,
+		}
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating campaign content: %w", err)
+	}
+	return items, nil
+}
+
+// ListCampaignMembers retrieves the members of all campaigns.
+func (s *SQLiteStore) ListCampaignMembers(ctx context.Context) ([]*data.CampaignMember, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT campaign_id, platform, content_id, source, added_at
+		FROM campaign_members
+		ORDER BY campaign_id, added_at`)
+	if err != nil {
+		return nil, fmt.Errorf("querying campaign members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*data.CampaignMember
+	for rows.Next() {
+		var m data.CampaignMember
+		var platform, source string
+		var addedAt sqlTime
+		if err := rows.Scan(&m.CampaignID, &platform, &m.ContentID, &source, &addedAt); err != nil {
+			return nil, fmt.Errorf("scanning campaign member: %w", err)
+		}
+		m.Platform = data.Platform(platform)
+		m.Source = data.CampaignSource(source)
+		m.AddedAt = addedAt.Time
+		members = append(members, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating campaign members: %w", err)
+	}
+	return members, nil
+}
+
+// insertCampaignMembers adds members in tx, ignoring ones already in their
+// campaign, and marks their campaigns updated.
+func insertCampaignMembers(ctx context.Context, tx *sql.Tx, members []*data.CampaignMember, now time.Time) error {
+	if len(members) == 0 {
+		return nil
+	}
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR IGNORE INTO campaign_members (campaign_id, platform, content_id, source, added_at)
+		VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("preparing campaign member insert: %w", err)
+	}
+	defer stmt.Close()
+
+	updated := make(map[int64]bool)
+	for _, m := range members {
+		if m.Source == "" {
+			m.Source = data.CampaignSourceManual
+		}
+		if m.AddedAt.IsZero() {
+			m.AddedAt = now
+		}
+		if _, err := stmt.ExecContext(ctx, m.CampaignID, string(m.Platform), m.ContentID, string(m.Source), m.AddedAt); err != nil {
+			return fmt.Errorf("saving campaign member: %w", err)
+		}
+		updated[m.CampaignID] = true
+	}
+	for id := range updated {
+		if _, err := tx.ExecContext(ctx, "UPDATE campaigns SET updated_at = ? WHERE id = ?", now, id); err != nil {
+			return fmt.Errorf("updating campaign: %w", err)
+		}
+	}
+	return nil
+}
+
+// scanCampaign scans a row selecting campaignColumns.
+func scanCampaign(row rowScanner) (*data.Campaign, error) {
+	var campaign data.Campaign
+	var createdAt, updatedAt sqlTime
+	if err := row.Scan(&campaign.ID, &campaign.Name, &campaign.Note, &createdAt, &updatedAt, &campaign.Items); err != nil {
+		return nil, err
+	}
+	campaign.CreatedAt = createdAt.Time
+	campaign.UpdatedAt = updatedAt.Time
+	return &campaign, nil
+}
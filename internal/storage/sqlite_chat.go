@@ -0,0 +1,182 @@
+// Package storage provides SQLite persistence for analytics chat
+// conversations.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// SaveConversation creates a conversation or updates its title and
+// UpdatedAt.
+func (s *SQLiteStore) SaveConversation(ctx context.Context, conversation *data.Conversation) error {
+	now := time.Now()
+	if conversation.CreatedAt.IsZero() {
+		conversation.CreatedAt = now
+	}
+	if conversation.UpdatedAt.IsZero() {
+		conversation.UpdatedAt = now
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO chat_conversations (id, title, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			title = excluded.title,
+			updated_at = excluded.updated_at`,
+		conversation.ID, conversation.Title, conversation.CreatedAt, conversation.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("saving conversation: %w", err)
+	}
+	return nil
+}
+
+// GetConversation retrieves a conversation, or nil if it doesn't exist.
+func (s *SQLiteStore) GetConversation(ctx context.Context, id string) (*data.Conversation, error) {
+	var c data.Conversation
+	var createdAt, updatedAt sqlTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, title, created_at, updated_at
+		FROM chat_conversations
+		WHERE id = ?`,
+		id).Scan(&c.ID, &c.Title, &createdAt, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting conversation: %w", err)
+	}
+	c.CreatedAt = createdAt.Time
+	c.UpdatedAt = updatedAt.Time
+	return &c, nil
+}
+
+// ListConversations returns up to limit conversations, most recently
+// active first.
+func (s *SQLiteStore) ListConversations(ctx context.Context, limit int) ([]*data.Conversation, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, title, created_at, updated_at
+		FROM chat_conversations
+		ORDER BY updated_at DESC
+		LIMIT ?`,
+		limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var conversations []*data.Conversation
+	for rows.Next() {
+		var c data.Conversation
+		var createdAt, updatedAt sqlTime
+		if err := rows.Scan(&c.ID, &c.Title, &createdAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scanning conversation: %w", err)
+		}
+		c.CreatedAt = createdAt.Time
+		c.UpdatedAt = updatedAt.Time
+		conversations = append(conversations, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating conversations: %w", err)
+	}
+	return conversations, nil
+}
+
+// SaveChatMessages adds messages to their conversations, setting their
+// IDs, and marks the conversations as updated.
+func (s *SQLiteStore) SaveChatMessages(ctx context.Context, messages []*data.ChatMessage) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, m := range messages {
+		// Encode missing lists as [] rather than null.
+		toolCalls, citations := m.ToolCalls, m.Citations
+		if toolCalls == nil {
+			toolCalls = []*data.ChatToolCall{}
+		}
+		if citations == nil {
+			citations = []*data.ChatRow{}
+		}
+		encodedCalls, err := json.Marshal(toolCalls)
+		if err != nil {
+			return fmt.Errorf("encoding tool calls: %w", err)
+		}
+		encodedCitations, err := json.Marshal(citations)
+		if err != nil {
+			return fmt.Errorf("encoding citations: %w", err)
+		}
+		if m.CreatedAt.IsZero() {
+			m.CreatedAt = now
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO chat_messages (conversation_id, role, content, tool_calls, citations, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			m.ConversationID, m.Role, m.Content, string(encodedCalls), string(encodedCitations), m.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("saving chat message: %w", err)
+		}
+		if m.ID, err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("getting chat message ID: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE chat_conversations SET updated_at = ? WHERE id = ?`,
+			m.CreatedAt, m.ConversationID); err != nil {
+			return fmt.Errorf("updating conversation: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing chat messages: %w", err)
+	}
+	return nil
+}
+
+// GetChatMessages returns the messages of a conversation in order.
+func (s *SQLiteStore) GetChatMessages(ctx context.Context, conversationID string) ([]*data.ChatMessage, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, conversation_id, role, content, tool_calls, citations, created_at
+		FROM chat_messages
+		WHERE conversation_id = ?
+		ORDER BY id`,
+		conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("querying chat messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*data.ChatMessage
+	for rows.Next() {
+		var m data.ChatMessage
+		var toolCalls, citations string
+		var createdAt sqlTime
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.Role, &m.Content, &toolCalls,
+			&citations, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning chat message: %w", err)
+		}
+		if err := json.Unmarshal([]byte(toolCalls), &m.ToolCalls); err != nil {
+			return nil, fmt.Errorf("decoding tool calls: %w", err)
+		}
+		if err := json.Unmarshal([]byte(citations), &m.Citations); err != nil {
+			return nil, fmt.Errorf("decoding citations: %w", err)
+		}
+		m.CreatedAt = createdAt.Time
+		messages = append(messages, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating chat messages: %w", err)
+	}
+	return messages, nil
+}
@@ -0,0 +1,182 @@
+// Package storage provides SQLite persistence for comment classification.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// ListUnlabeledComments returns up to limit of the most recent comments
+// that haven't been classified, including those labeled with sentiment
+// only before intents were added.
+func (s *SQLiteStore) ListUnlabeledComments(ctx context.Context, limit int) ([]*data.Comment, error) {
+	comments, err := s.queryLabelComments(ctx, `
+		WHERE l.comment_id IS NULL OR l.intent IS NULL
+		ORDER BY c.created_at DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing unlabeled comments: %w", err)
+	}
+	return comments, nil
+}
+
+// ListLexiconLabeledComments returns up to limit of the comments labeled
+// by the lexicon, least recently labeled first, so they can be labeled
+// again by the LLM.
+func (s *SQLiteStore) ListLexiconLabeledComments(ctx context.Context, limit int) ([]*data.Comment, error) {
+	comments, err := s.queryLabelComments(ctx, `
+		WHERE l.source = ?
+		ORDER BY l.labeled_at, c.created_at DESC
+		LIMIT ?`, data.LabelSourceLexicon, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing lexicon-labeled comments: %w", err)
+	}
+	return comments, nil
+}
+
+// queryLabelComments selects comments joined with their labels, filtered
+// and ordered by the rest of the query.
+func (s *SQLiteStore) queryLabelComments(ctx context.Context, rest string, args ...interface{}) ([]*data.Comment, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT c.id, c.platform, c.content_id, COALESCE(c.author_id, ''),
+		       COALESCE(c.author_name, ''), c.text, c.like_count, c.created_at
+		FROM comments c
+		LEFT JOIN comment_labels l ON l.comment_id = c.id`+rest,
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []*data.Comment
+	for rows.Next() {
+		var c data.Comment
+		var platform string
+		var createdAt sqlTime
+		if err := rows.Scan(&c.ID, &platform, &c.ContentID, &c.AuthorID, &c.AuthorName,
+			&c.Text, &c.LikeCount, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning comment: %w", err)
+		}
+		c.Platform = data.Platform(platform)
+		c.CreatedAt = createdAt.Time
+		comments = append(comments, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating comments: %w", err)
+	}
+	return comments, nil
+}
+
+// SaveCommentLabels saves comment classifications, replacing earlier
+// labels for the same comments.
+func (s *SQLiteStore) SaveCommentLabels(ctx context.Context, labels []*data.CommentLabel) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO comment_labels (comment_id, sentiment, score, intent, language, source, labeled_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(comment_id) DO UPDATE SET
+			sentiment = excluded.sentiment,
+			score = excluded.score,
+			intent = excluded.intent,
+			language = excluded.language,
+			source = excluded.source,
+			labeled_at = excluded.labeled_at`)
+	if err != nil {
+		return fmt.Errorf("preparing comment label insert: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, l := range labels {
+		labeledAt := l.LabeledAt
+		if labeledAt.IsZero() {
+			labeledAt = now
+		}
+		if _, err := stmt.ExecContext(ctx, l.CommentID, l.Sentiment, l.Score, l.Intent,
+			l.Language, l.Source, labeledAt); err != nil {
+			return fmt.Errorf("saving label for comment %s: %w", l.CommentID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing comment labels: %w", err)
+	}
+	return nil
+}
+
+// GetSentimentTrend counts the labeled comments posted each day of the
+// query's date range by sentiment. Days are in the location of the range's
+// start, and days without labeled comments are omitted.
+func (s *SQLiteStore) GetSentimentTrend(ctx context.Context, query data.SentimentQuery) ([]*data.SentimentPoint, error) {
+	where := []string{"c.created_at >= ?", "c.created_at < ?"}
+	args := []interface{}{query.DateRange.Start, query.DateRange.End}
+	if query.Platform != "" {
+		where = append(where, "c.platform = ?")
+		args = append(args, string(query.Platform))
+	}
+	if query.ContentID != "" {
+		where = append(where, "c.content_id = ?")
+		args = append(args, query.ContentID)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT c.created_at, l.sentiment, COALESCE(l.score, 0)
+		FROM comments c
+		JOIN comment_labels l ON l.comment_id = c.id
+		WHERE l.sentiment IS NOT NULL AND `+strings.Join(where, " AND ")+`
+		ORDER BY c.created_at`,
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying sentiment trend: %w", err)
+	}
+	defer rows.Close()
+
+	loc := query.DateRange.Start.Location()
+	var points []*data.SentimentPoint
+	var scoreSum float64
+	for rows.Next() {
+		var createdAt sqlTime
+		var sentiment string
+		var score float64
+		if err := rows.Scan(&createdAt, &sentiment, &score); err != nil {
+			return nil, fmt.Errorf("scanning sentiment: %w", err)
+		}
+
+		t := createdAt.In(loc)
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		if len(points) == 0 || !points[len(points)-1].Date.Equal(day) {
+			if len(points) > 0 {
+				last := points[len(points)-1]
+				last.Score = scoreSum / float64(last.Total())
+			}
+			points = append(points, &data.SentimentPoint{Date: day})
+			scoreSum = 0
+		}
+		p := points[len(points)-1]
+		switch sentiment {
+		case data.SentimentPositive:
+			p.Positive++
+		case data.SentimentNegative:
+			p.Negative++
+		default:
+			p.Neutral++
+		}
+		scoreSum += score
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating sentiment trend: %w", err)
+	}
+	if len(points) > 0 {
+		last := points[len(points)-1]
+		last.Score = scoreSum / float64(last.Total())
+	}
+	return points, nil
+}
@@ -0,0 +1,281 @@
+// Package storage provides SQLite persistence for cross-platform content.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// contentSortColumns maps sort fields to content_items columns.
+var contentSortColumns = map[data.ContentSort]string{
+	data.SortByDate:       "published_at",
+	data.SortByViews:      "views",
+	data.SortByEngagement: "engagement_rate",
+}
+
+// contentItemColumns is the column list scanned by scanContentItem.
+const contentItemColumns = `platform, id, title, body, published_at, views, likes, comments, shares, engagement_rate`
+
+// ListContent retrieves content items matching the query, along with the
+// total number of matches before pagination.
+func (s *SQLiteStore) ListContent(ctx context.Context, query data.ContentQuery) ([]*data.ContentItem, int, error) {
+	var where []string
+	var args []interface{}
+	if query.Platform != "" {
+		where = append(where, "platform = ?")
+		args = append(args, string(query.Platform))
+	}
+	if !query.DateRange.Start.IsZero() {
+		where = append(where, "published_at >= ?")
+		args = append(args, query.DateRange.Start)
+	}
+	if !query.DateRange.End.IsZero() {
+		where = append(where, "published_at < ?")
+		args = append(args, query.DateRange.End)
+	}
+
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM content_items"+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting content: %w", err)
+	}
+
+	column, ok := contentSortColumns[query.Sort]
+	if !ok {
+		column = contentSortColumns[data.SortByDate]
+	}
+	direction := "DESC"
+	if query.Ascending {
+		direction = "ASC"
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1 // No limit
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		fmt.Sprintf("SELECT %s FROM content_items%s ORDER BY %s %s, id LIMIT ? OFFSET ?",
+			contentItemColumns, whereClause, column, direction),
+		append(args, limit, query.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("querying content: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*data.ContentItem
+	for rows.Next() {
+		item, err := scanContentItem(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterating content: %w", err)
+	}
+
+	return items, total, nil
+}
+
+// GetContentItem retrieves a single content item. It returns nil if the
+// item does not exist.
+func (s *SQLiteStore) GetContentItem(ctx context.Context, platform data.Platform, id string) (*data.ContentItem, error) {
+	row := s.db.QueryRowContext(ctx,
+		"SELECT "+contentItemColumns+" FROM content_items WHERE platform = ? AND id = ?",
+		string(platform), id)
+
+	item, err := scanContentItem(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return item, err
+}
+
+// GetContentRank returns the 1-based rank of a content item among all of
+// the platform's content when sorted descending by the given field.
+func (s *SQLiteStore) GetContentRank(ctx context.Context, platform data.Platform, id string, sort data.ContentSort) (int, int, error) {
+	column, ok := contentSortColumns[sort]
+	if !ok {
+		return 0, 0, fmt.Errorf("unsupported sort field: %q", sort)
+	}
+
+	var rank, total int
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT
+			(SELECT COUNT(*) FROM content_items o WHERE o.platform = c.platform AND o.%[1]s > c.%[1]s) + 1,
+			(SELECT COUNT(*) FROM content_items o WHERE o.platform = c.platform)
+		FROM content_items c
+		WHERE c.platform = ? AND c.id = ?`, column),
+		string(platform), id).Scan(&rank, &total)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("ranking content: %w", err)
+	}
+	return rank, total, nil
+}
+
+// SaveContentSnapshot records a content item's current metrics.
+func (s *SQLiteStore) SaveContentSnapshot(ctx context.Context, snapshot *data.ContentSnapshot) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO content_metrics_history (platform, content_id, views, likes, comments, shares, recorded_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		string(snapshot.Platform), snapshot.ContentID, snapshot.Views, snapshot.Likes,
+		snapshot.Comments, snapshot.Shares, snapshot.RecordedAt)
+	if err != nil {
+		return fmt.Errorf("saving content snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetContentSnapshots retrieves a content item's metric history in
+// chronological order. A zero date range returns the full history.
+func (s *SQLiteStore) GetContentSnapshots(ctx context.Context, platform data.Platform, contentID string, dateRange data.DateRange) ([]*data.ContentSnapshot, error) {
+	query := `
+		SELECT platform, content_id, views, likes, comments, shares, recorded_at
+		FROM content_metrics_history
+		WHERE platform = ? AND content_id = ?`
+	args := []interface{}{string(platform), contentID}
+	if !dateRange.Start.IsZero() {
+		query += " AND recorded_at >= ?"
+		args = append(args, dateRange.Start)
+	}
+	if !dateRange.End.IsZero() {
+		query += " AND recorded_at < ?"
+		args = append(args, dateRange.End)
+	}
+	query += " ORDER BY recorded_at"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying content snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*data.ContentSnapshot
+	for rows.Next() {
+		var snap data.ContentSnapshot
+		var platform string
+		var recordedAt sqlTime
+		if err := rows.Scan(&platform, &snap.ContentID, &snap.Views, &snap.Likes,
+			&snap.Comments, &snap.Shares, &recordedAt); err != nil {
+			return nil, fmt.Errorf("scanning content snapshot: %w", err)
+		}
+		snap.Platform = data.Platform(platform)
+		snap.RecordedAt = recordedAt.Time
+		snapshots = append(snapshots, &snap)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating content snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// GetEarlySnapshots retrieves, for each of the platform's content items
+// published in the date range, the last snapshot recorded within window
+// of publishing, keyed by content ID. Items without one are left out.
+func (s *SQLiteStore) GetEarlySnapshots(ctx context.Context, platform data.Platform, dateRange data.DateRange, window time.Duration) (map[string]*data.ContentSnapshot, error) {
+	query := `
+		SELECT h.content_id, h.views, h.likes, h.comments, h.shares, h.recorded_at, c.published_at
+		FROM content_metrics_history h
+		JOIN content_items c ON c.platform = h.platform AND c.id = h.content_id
+		WHERE h.platform = ?`
+	args := []interface{}{string(platform)}
+	if !dateRange.Start.IsZero() {
+		query += " AND c.published_at >= ? AND h.recorded_at >= ?"
+		args = append(args, dateRange.Start, dateRange.Start)
+	}
+	if !dateRange.End.IsZero() {
+		query += " AND c.published_at < ? AND h.recorded_at < ?"
+		args = append(args, dateRange.End, dateRange.End.Add(window))
+	}
+	query += " ORDER BY h.content_id, h.recorded_at"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying early snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	// Timestamps are compared here rather than in SQL, since the view and
+	// the history table may store them in different text formats.
+	snapshots := make(map[string]*data.ContentSnapshot)
+	for rows.Next() {
+		snap := data.ContentSnapshot{Platform: platform}
+		var recordedAt, publishedAt sqlTime
+		if err := rows.Scan(&snap.ContentID, &snap.Views, &snap.Likes, &snap.Comments,
+			&snap.Shares, &recordedAt, &publishedAt); err != nil {
+			return nil, fmt.Errorf("scanning early snapshot: %w", err)
+		}
+		age := recordedAt.Time.Sub(publishedAt.Time)
+		if age < 0 || age > window {
+			continue
+		}
+		snap.RecordedAt = recordedAt.Time
+		snapshots[snap.ContentID] = &snap
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating early snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// LinkInsightContent records that an insight discusses a content item.
+func (s *SQLiteStore) LinkInsightContent(ctx context.Context, insightID string, platform data.Platform, contentID string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO insight_content (insight_id, platform, content_id)
+		VALUES (?, ?, ?)`,
+		insightID, string(platform), contentID)
+	if err != nil {
+		return fmt.Errorf("linking insight to content: %w", err)
+	}
+	return nil
+}
+
+// GetContentInsights retrieves the most recent insights linked to a
+// content item.
+func (s *SQLiteStore) GetContentInsights(ctx context.Context, platform data.Platform, contentID string, limit int) ([]*data.Insight, error) {
+	return s.queryInsights(ctx, `
+		SELECT `+insightColumns+`
+		FROM insights i
+		JOIN insight_content ic ON ic.insight_id = i.id
+		WHERE ic.platform = ? AND ic.content_id = ?
+		ORDER BY i.generated_at DESC
+		LIMIT ?`,
+		string(platform), contentID, limit)
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanContentItem scans a row selected with contentItemColumns.
+func scanContentItem(row rowScanner) (*data.ContentItem, error) {
+	var item data.ContentItem
+	var platform string
+	var publishedAt sqlTime
+	err := row.Scan(&platform, &item.ID, &item.Title, &item.Body, &publishedAt,
+		&item.Views, &item.Likes, &item.Comments, &item.Shares, &item.EngagementRate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning content item: %w", err)
+	}
+	item.Platform = data.Platform(platform)
+	item.PublishedAt = publishedAt.Time
+	return &item, nil
+}
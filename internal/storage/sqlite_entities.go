@@ -0,0 +1,195 @@
+// Package storage provides SQLite persistence for the hashtags, mentions
+// and link domains in content.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// entitySortColumns maps sort fields to GetEntityStats result columns.
+var entitySortColumns = map[data.EntitySort]string{
+	data.EntitySortEngagement: "avg_engagement_rate",
+	data.EntitySortReach:      "avg_views",
+}
+
+// SaveContentEntities replaces a content item's entities and records that
+// it has been scanned, even if it has none.
+func (s *SQLiteStore) SaveContentEntities(ctx context.Context, platform data.Platform, contentID string, entities []data.ContentEntity) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM content_entities WHERE platform = ? AND content_id = ?`,
+		string(platform), contentID); err != nil {
+		return fmt.Errorf("deleting content entities: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR IGNORE INTO content_entities (platform, content_id, type, value, text)
+		VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("preparing entity insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, e := range entities {
+		if _, err := stmt.ExecContext(ctx, string(platform), contentID, string(e.Type), e.Value, e.Text); err != nil {
+			return fmt.Errorf("saving content entity: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO content_entity_scans (platform, content_id, scanned_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(platform, content_id) DO UPDATE SET scanned_at = excluded.scanned_at`,
+		string(platform), contentID); err != nil {
+		return fmt.Errorf("recording entity scan: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing content entities: %w", err)
+	}
+	return nil
+}
+
+// ListUnscannedContent retrieves up to limit of the platform's content
+// items whose entities haven't been extracted, newest first.
+func (s *SQLiteStore) ListUnscannedContent(ctx context.Context, platform data.Platform, limit int) ([]*data.ContentItem, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+contentItemColumns+`
+		FROM content_items c
+		WHERE platform = ? AND NOT EXISTS (
+			SELECT 1 FROM content_entity_scans s
+			WHERE s.platform = c.platform AND s.content_id = c.id)
+		ORDER BY published_at DESC, id
+		LIMIT ?`,
+		string(platform), limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying unscanned content: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*data.ContentItem
+	for rows.Next() {
+		item, err := scanContentItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating unscanned content: %w", err)
+	}
+	return items, nil
+}
+
+// GetEntityStats ranks the entities of the query's type by the average
+// performance of the content they appear in, leaving out entities in
+// fewer than the query's minimum number of content items.
+func (s *SQLiteStore) GetEntityStats(ctx context.Context, query data.EntityQuery) ([]*data.EntityStats, error) {
+	where := []string{"e.type = ?"}
+	args := []interface{}{string(query.Type)}
+	if query.Platform != "" {
+		where = append(where, "e.platform = ?")
+		args = append(args, string(query.Platform))
+	}
+	if !query.DateRange.Start.IsZero() {
+		where = append(where, "c.published_at >= ?")
+		args = append(args, query.DateRange.Start)
+	}
+	if !query.DateRange.End.IsZero() {
+		where = append(where, "c.published_at < ?")
+		args = append(args, query.DateRange.End)
+	}
+
+	column, ok := entitySortColumns[query.Sort]
+	if !ok {
+		column = entitySortColumns[data.EntitySortEngagement]
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1 // No limit
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT e.type, e.value, MAX(e.text), COUNT(*) AS posts,
+			AVG(c.engagement_rate) AS avg_engagement_rate,
+			AVG(c.views) AS avg_views,
+			SUM(c.views)
+		FROM content_entities e
+		JOIN content_items c ON c.platform = e.platform AND c.id = e.content_id
+		WHERE %s
+		GROUP BY e.type, e.value
+		HAVING COUNT(*) >= ?
+		ORDER BY %s DESC, posts DESC, e.value
+		LIMIT ?`, strings.Join(where, " AND "), column),
+		append(args, max(query.MinPosts, 1), limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("querying entity stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*data.EntityStats
+	for rows.Next() {
+		var st data.EntityStats
+		var entityType string
+		if err := rows.Scan(&entityType, &st.Value, &st.Text, &st.Posts,
+			&st.AvgEngagementRate, &st.AvgViews, &st.TotalViews); err != nil {
+			return nil, fmt.Errorf("scanning entity stats: %w", err)
+		}
+		st.Type = data.EntityType(entityType)
+		stats = append(stats, &st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating entity stats: %w", err)
+	}
+	return stats, nil
+}
+
+// ListContentEntities retrieves the entities of a type in content
+// published in dateRange, or in all content if it is the zero value.
+func (s *SQLiteStore) ListContentEntities(ctx context.Context, entityType data.EntityType, dateRange data.DateRange) ([]data.ContentEntity, error) {
+	where := []string{"e.type = ?"}
+	args := []interface{}{string(entityType)}
+	if !dateRange.Start.IsZero() {
+		where = append(where, "c.published_at >= ?")
+		args = append(args, dateRange.Start)
+	}
+	if !dateRange.End.IsZero() {
+		where = append(where, "c.published_at < ?")
+		args = append(args, dateRange.End)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT e.platform, e.content_id, e.value, e.text
+		FROM content_entities e
+		JOIN content_items c ON c.platform = e.platform AND c.id = e.content_id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY e.platform, e.content_id, e.value`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying content entities: %w", err)
+	}
+	defer rows.Close()
+
+	var entities []data.ContentEntity
+	for rows.Next() {
+		e := data.ContentEntity{Type: entityType}
+		var platform string
+		if err := rows.Scan(&platform, &e.ContentID, &e.Value, &e.Text); err != nil {
+			return nil, fmt.Errorf("scanning content entity: %w", err)
+		}
+		e.Platform = data.Platform(platform)
+		entities = append(entities, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating content entities: %w", err)
+	}
+	return entities, nil
+}
@@ -0,0 +1,98 @@
+// Package storage provides SQLite persistence for metric goals.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+const goalColumns = `id, platform, metric, target, baseline, start_date, deadline,
+	COALESCE(note, ''), created_at, updated_at`
+
+// CreateGoal saves a new goal and sets its ID.
+func (s *SQLiteStore) CreateGoal(ctx context.Context, goal *data.Goal) error {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO goals (platform, metric, target, baseline, start_date, deadline, note, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?)`,
+		string(goal.Platform), goal.Metric, goal.Target, goal.Baseline, goal.Start, goal.Deadline,
+		goal.Note, goal.CreatedAt, goal.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("saving goal: %w", err)
+	}
+	if goal.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("getting goal ID: %w", err)
+	}
+	return nil
+}
+
+// GetGoal retrieves a goal by ID, or nil if it doesn't exist.
+func (s *SQLiteStore) GetGoal(ctx context.Context, id int64) (*data.Goal, error) {
+	goal, err := scanGoal(s.db.QueryRowContext(ctx, `
+		SELECT `+goalColumns+`
+		FROM goals
+		WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting goal: %w", err)
+	}
+	return goal, nil
+}
+
+// ListGoals retrieves the goals for platform, or for all platforms if it
+// is empty, soonest deadline first.
+func (s *SQLiteStore) ListGoals(ctx context.Context, platform data.Platform) ([]*data.Goal, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+goalColumns+`
+		FROM goals
+		WHERE ? = '' OR platform = ?
+		ORDER BY deadline, id`,
+		string(platform), string(platform))
+	if err != nil {
+		return nil, fmt.Errorf("querying goals: %w", err)
+	}
+	defer rows.Close()
+
+	var goals []*data.Goal
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning goal: %w", err)
+		}
+		goals = append(goals, goal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating goals: %w", err)
+	}
+	return goals, nil
+}
+
+// DeleteGoal deletes a goal.
+func (s *SQLiteStore) DeleteGoal(ctx context.Context, id int64) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM goals WHERE id = ?", id); err != nil {
+		return fmt.Errorf("deleting goal: %w", err)
+	}
+	return nil
+}
+
+// scanGoal scans a row selecting goalColumns.
+func scanGoal(row rowScanner) (*data.Goal, error) {
+	var goal data.Goal
+	var platform string
+	var start, deadline, createdAt, updatedAt sqlTime
+	if err := row.Scan(&goal.ID, &platform, &goal.Metric, &goal.Target, &goal.Baseline, &start,
+		&deadline, &goal.Note, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	goal.Platform = data.Platform(platform)
+	goal.Start = start.Time
+	goal.Deadline = deadline.Time
+	goal.CreatedAt = createdAt.Time
+	goal.UpdatedAt = updatedAt.Time
+	return &goal, nil
+}
@@ -0,0 +1,274 @@
+// Package storage provides SQLite persistence for the comment inbox.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// inboxSelect selects comments with their state, labels and content title,
+// in the column order expected by scanInboxComment.
+const inboxSelect = `
+	SELECT c.id, c.platform, c.content_id, COALESCE(c.parent_id, ''),
+	       COALESCE(c.author_id, ''), COALESCE(c.author_name, ''), c.text,
+	       c.like_count, c.created_at, COALESCE(ci.title, ''),
+	       s.read_at IS NOT NULL, s.archived_at IS NOT NULL, COALESCE(s.flagged, 0),
+	       COALESCE(l.sentiment, ''), COALESCE(l.intent, '')
+	FROM comments c
+	LEFT JOIN comment_state s ON s.comment_id = c.id
+	LEFT JOIN comment_labels l ON l.comment_id = c.id
+	LEFT JOIN content_items ci ON ci.platform = c.platform AND ci.id = c.content_id`
+
+// SaveCommentReply saves a comment as a reply to parentID.
+func (s *SQLiteStore) SaveCommentReply(ctx context.Context, parentID string, comment *data.Comment) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO comments (id, platform, content_id, parent_id, author_id, author_name, text, like_count, created_at, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(id) DO UPDATE SET
+			parent_id = excluded.parent_id,
+			text = excluded.text,
+			like_count = excluded.like_count,
+			fetched_at = excluded.fetched_at`,
+		comment.ID, string(comment.Platform), comment.ContentID, parentID, comment.AuthorID,
+		comment.AuthorName, comment.Text, comment.LikeCount, comment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("saving comment reply: %w", err)
+	}
+	return nil
+}
+
+// ListInboxComments retrieves comments matching the filter, grouped into
+// threads. Each matching reply is returned under its parent, and every
+// thread includes all of its replies so the conversation can be read in
+// context; comments included only for context have Matched set to false.
+// The returned total counts matching comments before pagination.
+func (s *SQLiteStore) ListInboxComments(ctx context.Context, filter data.InboxFilter) ([]*data.InboxComment, int, error) {
+	var where []string
+	var args []interface{}
+	if filter.Platform != "" {
+		where = append(where, "c.platform = ?")
+		args = append(args, string(filter.Platform))
+	}
+	if filter.ContentID != "" {
+		where = append(where, "c.content_id = ?")
+		args = append(args, filter.ContentID)
+	}
+	if filter.UnreadOnly {
+		where = append(where, "s.read_at IS NULL")
+	}
+	if filter.FlaggedOnly {
+		where = append(where, "s.flagged = 1")
+	}
+	if filter.QuestionsOnly {
+		where = append(where, "(instr(c.text, '?') > 0 OR l.intent = 'question')")
+	}
+	if filter.Sentiment != "" {
+		where = append(where, "l.sentiment = ?")
+		args = append(args, filter.Sentiment)
+	}
+	if filter.Intent != "" {
+		where = append(where, "l.intent = ?")
+		args = append(args, filter.Intent)
+	}
+	if !filter.IncludeArchived {
+		where = append(where, "s.archived_at IS NULL")
+	}
+
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM comments c
+		LEFT JOIN comment_state s ON s.comment_id = c.id
+		LEFT JOIN comment_labels l ON l.comment_id = c.id`+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting inbox comments: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1 // No limit
+	}
+	matches, err := s.queryInboxComments(ctx,
+		inboxSelect+whereClause+" ORDER BY c.created_at DESC LIMIT ? OFFSET ?",
+		append(args, limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(matches) == 0 {
+		return nil, total, nil
+	}
+
+	// Load every comment in the matched threads for context.
+	var rootIDs []string
+	seenRoot := make(map[string]bool)
+	for _, c := range matches {
+		root := c.ID
+		if c.ParentID != "" {
+			root = c.ParentID
+		}
+		if !seenRoot[root] {
+			seenRoot[root] = true
+			rootIDs = append(rootIDs, root)
+		}
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(rootIDs)), ",")
+	threadArgs := make([]interface{}, 0, len(rootIDs)*2)
+	for _, id := range rootIDs {
+		threadArgs = append(threadArgs, id)
+	}
+	threadArgs = append(threadArgs, threadArgs...)
+
+	threadComments, err := s.queryInboxComments(ctx,
+		inboxSelect+" WHERE c.id IN ("+placeholders+") OR c.parent_id IN ("+placeholders+") ORDER BY c.created_at",
+		threadArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	matched := make(map[string]bool, len(matches))
+	for _, c := range matches {
+		matched[c.ID] = true
+	}
+	byID := make(map[string]*data.InboxComment, len(threadComments))
+	for _, c := range threadComments {
+		c.Matched = matched[c.ID]
+		byID[c.ID] = c
+	}
+	for _, c := range threadComments {
+		if parent, ok := byID[c.ParentID]; ok && c.ParentID != "" {
+			parent.Replies = append(parent.Replies, c)
+		}
+	}
+
+	threads := make([]*data.InboxComment, 0, len(rootIDs))
+	for _, id := range rootIDs {
+		if root, ok := byID[id]; ok {
+			threads = append(threads, root)
+			continue
+		}
+		// The parent hasn't been fetched; show its replies on their own.
+		for _, c := range threadComments {
+			if c.ParentID == id {
+				threads = append(threads, c)
+			}
+		}
+	}
+	return threads, total, nil
+}
+
+// GetInboxComment retrieves a single comment with its state. It returns
+// nil if the comment does not exist.
+func (s *SQLiteStore) GetInboxComment(ctx context.Context, id string) (*data.InboxComment, error) {
+	comments, err := s.queryInboxComments(ctx, inboxSelect+" WHERE c.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(comments) == 0 {
+		return nil, nil
+	}
+	comments[0].Matched = true
+	return comments[0], nil
+}
+
+// UpdateCommentState changes a comment's read, archived or flagged state.
+func (s *SQLiteStore) UpdateCommentState(ctx context.Context, id string, update data.CommentStateUpdate) error {
+	var sets []string
+	var args []interface{}
+	now := time.Now()
+
+	if update.Read != nil {
+		sets = append(sets, "read_at = ?")
+		args = append(args, nullTime(*update.Read, now))
+	}
+	if update.Archived != nil {
+		sets = append(sets, "archived_at = ?")
+		args = append(args, nullTime(*update.Archived, now))
+	}
+	if update.Flagged != nil {
+		sets = append(sets, "flagged = ?")
+		args = append(args, *update.Flagged)
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT OR IGNORE INTO comment_state (comment_id) VALUES (?)", id); err != nil {
+		return fmt.Errorf("creating comment state: %w", err)
+	}
+
+	sets = append(sets, "updated_at = CURRENT_TIMESTAMP")
+	args = append(args, id)
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE comment_state SET "+strings.Join(sets, ", ")+" WHERE comment_id = ?", args...); err != nil {
+		return fmt.Errorf("updating comment state: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// queryInboxComments runs a query selecting inboxSelect columns.
+func (s *SQLiteStore) queryInboxComments(ctx context.Context, query string, args ...interface{}) ([]*data.InboxComment, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying inbox comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []*data.InboxComment
+	for rows.Next() {
+		c, err := scanInboxComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating inbox comments: %w", err)
+	}
+	return comments, nil
+}
+
+// scanInboxComment scans a row selected with inboxSelect.
+func scanInboxComment(row rowScanner) (*data.InboxComment, error) {
+	var c data.InboxComment
+	var platform string
+	var createdAt sqlTime
+	err := row.Scan(&c.ID, &platform, &c.ContentID, &c.ParentID, &c.AuthorID, &c.AuthorName,
+		&c.Text, &c.LikeCount, &createdAt, &c.ContentTitle, &c.Read, &c.Archived, &c.Flagged,
+		&c.Sentiment, &c.Intent)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning inbox comment: %w", err)
+	}
+	c.Platform = data.Platform(platform)
+	c.CreatedAt = createdAt.Time
+	return &c, nil
+}
+
+// nullTime returns now if set is true and NULL otherwise.
+func nullTime(set bool, now time.Time) interface{} {
+	if set {
+		return now
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+// Package storage provides SQLite persistence for AI-generated insights.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+const insightColumns = `i.id, COALESCE(i.platform, ''), i.type, i.title, i.description,
+	i.confidence, i.generated_at, COALESCE(i.data_range, '')`
+
+// SaveInsight saves an AI-generated insight.
+func (s *SQLiteStore) SaveInsight(ctx context.Context, insight *data.Insight) error {
+	// Upsert rather than INSERT OR REPLACE so the FTS triggers see an update.
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO insights (id, platform, type, title, description, confidence, generated_at, data_range)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			platform = excluded.platform,
+			type = excluded.type,
+			title = excluded.title,
+			description = excluded.description,
+			confidence = excluded.confidence,
+			generated_at = excluded.generated_at,
+			data_range = excluded.data_range`,
+		insight.ID, string(insight.Platform), insight.Type, insight.Title, insight.Description,
+		insight.Confidence, insight.GeneratedAt, insight.DataRange)
+	if err != nil {
+		return fmt.Errorf("saving insight: %w", err)
+	}
+	return nil
+}
+
+// GetInsights retrieves insights for a platform.
+func (s *SQLiteStore) GetInsights(ctx context.Context, platform data.Platform, limit int) ([]*data.Insight, error) {
+	return s.queryInsights(ctx, `
+		SELECT `+insightColumns+`
+		FROM insights i
+		WHERE i.platform = ?
+		ORDER BY i.generated_at DESC
+		LIMIT ?`,
+		string(platform), limit)
+}
+
+// GetRecentInsights retrieves the most recent insights across all platforms.
+func (s *SQLiteStore) GetRecentInsights(ctx context.Context, limit int) ([]*data.Insight, error) {
+	return s.queryInsights(ctx, `
+		SELECT `+insightColumns+`
+		FROM insights i
+		ORDER BY i.generated_at DESC
+		LIMIT ?`,
+		limit)
+}
+
+// GetInsight retrieves an insight by ID. It returns nil if it doesn't exist.
+func (s *SQLiteStore) GetInsight(ctx context.Context, id string) (*data.Insight, error) {
+	insight, err := scanInsight(s.db.QueryRowContext(ctx, `
+		SELECT `+insightColumns+`
+		FROM insights i
+		WHERE i.id = ?`,
+		id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return insight, err
+}
+
+// ListInsights retrieves the most recent insights matching filter.
+func (s *SQLiteStore) ListInsights(ctx context.Context, filter data.InsightFilter) ([]*data.Insight, error) {
+	var where []string
+	var args []interface{}
+	if filter.Platform != "" {
+		where = append(where, "i.platform = ?")
+		args = append(args, string(filter.Platform))
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "i.generated_at >= ?")
+		args = append(args, filter.Since)
+	}
+	if !filter.IncludeDismissed {
+		where = append(where, "f.dismissed_at IS NULL")
+	}
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1 // No limit
+	}
+	args = append(args, limit)
+	return s.queryInsights(ctx, `
+		SELECT `+insightColumns+`
+		FROM insights i
+		LEFT JOIN insight_feedback f ON f.insight_id = i.id`+whereClause+`
+		ORDER BY i.generated_at DESC
+		LIMIT ?`,
+		args...)
+}
+
+// SaveInsightDetail saves the evidence and suggested actions of an insight
+// saved with SaveInsight.
+func (s *SQLiteStore) SaveInsightDetail(ctx context.Context, detail *data.InsightDetail) error {
+	// Encode missing lists as [] rather than null.
+	evidenceList, actionList := detail.Evidence, detail.Actions
+	if evidenceList == nil {
+		evidenceList = []data.InsightEvidence{}
+	}
+	if actionList == nil {
+		actionList = []string{}
+	}
+
+	evidence, err := json.Marshal(evidenceList)
+	if err != nil {
+		return fmt.Errorf("encoding insight evidence: %w", err)
+	}
+	actions, err := json.Marshal(actionList)
+	if err != nil {
+		return fmt.Errorf("encoding insight actions: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO insight_details (insight_id, evidence, actions)
+		VALUES (?, ?, ?)
+		ON CONFLICT(insight_id) DO UPDATE SET
+			evidence = excluded.evidence,
+			actions = excluded.actions`,
+		detail.InsightID, string(evidence), string(actions))
+	if err != nil {
+		return fmt.Errorf("saving insight detail: %w", err)
+	}
+	return nil
+}
+
+// GetInsightDetails retrieves the details of the given insights, keyed by
+// insight ID. Insights without details are omitted.
+func (s *SQLiteStore) GetInsightDetails(ctx context.Context, insightIDs []string) (map[string]*data.InsightDetail, error) {
+	details := make(map[string]*data.InsightDetail)
+	if len(insightIDs) == 0 {
+		return details, nil
+	}
+
+	args := make([]interface{}, len(insightIDs))
+	for i, id := range insightIDs {
+		args[i] = id
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT insight_id, evidence, actions
+		FROM insight_details
+		WHERE insight_id IN (?`+strings.Repeat(", ?", len(insightIDs)-1)+`)`,
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying insight details: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var detail data.InsightDetail
+		var evidence, actions string
+		if err := rows.Scan(&detail.InsightID, &evidence, &actions); err != nil {
+			return nil, fmt.Errorf("scanning insight detail: %w", err)
+		}
+		if err := json.Unmarshal([]byte(evidence), &detail.Evidence); err != nil {
+			return nil, fmt.Errorf("decoding insight evidence: %w", err)
+		}
+		if err := json.Unmarshal([]byte(actions), &detail.Actions); err != nil {
+			return nil, fmt.Errorf("decoding insight actions: %w", err)
+		}
+		details[detail.InsightID] = &detail
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating insight details: %w", err)
+	}
+	return details, nil
+}
+
+// UpdateInsightFeedback changes the rating or dismissal of an insight.
+func (s *SQLiteStore) UpdateInsightFeedback(ctx context.Context, id string, update data.InsightFeedbackUpdate) error {
+	var sets []string
+	var args []interface{}
+	if update.Rating != nil {
+		sets = append(sets, "rating = ?")
+		args = append(args, *update.Rating)
+	}
+	if update.Dismissed != nil {
+		sets = append(sets, "dismissed_at = ?")
+		args = append(args, nullTime(*update.Dismissed, time.Now()))
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT OR IGNORE INTO insight_feedback (insight_id) VALUES (?)", id); err != nil {
+		return fmt.Errorf("creating insight feedback: %w", err)
+	}
+
+	sets = append(sets, "updated_at = CURRENT_TIMESTAMP")
+	args = append(args, id)
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE insight_feedback SET "+strings.Join(sets, ", ")+" WHERE insight_id = ?", args...); err != nil {
+		return fmt.Errorf("updating insight feedback: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing insight feedback: %w", err)
+	}
+	return nil
+}
+
+// GetInsightFeedback retrieves the feedback on the given insights, keyed by
+// insight ID. Insights without feedback are omitted.
+func (s *SQLiteStore) GetInsightFeedback(ctx context.Context, insightIDs []string) (map[string]*data.InsightFeedback, error) {
+	feedback := make(map[string]*data.InsightFeedback)
+	if len(insightIDs) == 0 {
+		return feedback, nil
+	}
+
+	args := make([]interface{}, len(insightIDs))
+	for i, id := range insightIDs {
+		args[i] = id
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT insight_id, rating, dismissed_at IS NOT NULL, updated_at
+		FROM insight_feedback
+		WHERE insight_id IN (?`+strings.Repeat(", ?", len(insightIDs)-1)+`)`,
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying insight feedback: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f data.InsightFeedback
+		var updatedAt sqlTime
+		if err := rows.Scan(&f.InsightID, &f.Rating, &f.Dismissed, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scanning insight feedback: %w", err)
+		}
+		f.UpdatedAt = updatedAt.Time
+		feedback[f.InsightID] = &f
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating insight feedback: %w", err)
+	}
+	return feedback, nil
+}
+
+// GetRatedInsights retrieves the most recently rated insights with the
+// given rating.
+func (s *SQLiteStore) GetRatedInsights(ctx context.Context, rating, limit int) ([]*data.Insight, error) {
+	return s.queryInsights(ctx, `
+		SELECT `+insightColumns+`
+		FROM insights i
+		JOIN insight_feedback f ON f.insight_id = i.id
+		WHERE f.rating = ?
+		ORDER BY f.updated_at DESC
+		LIMIT ?`,
+		rating, limit)
+}
+
+// queryInsights runs a query selecting insightColumns.
+func (s *SQLiteStore) queryInsights(ctx context.Context, query string, args ...interface{}) ([]*data.Insight, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying insights: %w", err)
+	}
+	defer rows.Close()
+
+	var insights []*data.Insight
+	for rows.Next() {
+		insight, err := scanInsight(rows)
+		if err != nil {
+			return nil, err
+		}
+		insights = append(insights, insight)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating insights: %w", err)
+	}
+	return insights, nil
+}
+
+// scanInsight scans a row selected with insightColumns.
+func scanInsight(row rowScanner) (*data.Insight, error) {
+	var insight data.Insight
+	var platform string
+	var generatedAt sqlTime
+	if err := row.Scan(&insight.ID, &platform, &insight.Type, &insight.Title,
+		&insight.Description, &insight.Confidence, &generatedAt, &insight.DataRange); err != nil {
+		return nil, fmt.Errorf("scanning insight: %w", err)
+	}
+	insight.Platform = data.Platform(platform)
+	insight.GeneratedAt = generatedAt.Time
+	return &insight, nil
+}
@@ -0,0 +1,73 @@
+// Package storage provides SQLite persistence for leader election leases.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// AcquireLease takes or renews the named lease for holder until now+ttl.
+// It succeeds only if holder already owns the lease or the lease has
+// expired; either way the current lease is returned, so callers compare its
+// Holder to check whether they own it. The fencing token is incremented
+// whenever a new term starts: the lease changes owner or is taken again
+// after expiring.
+func (s *SQLiteStore) AcquireLease(ctx context.Context, name, holder, address string, ttl time.Duration) (*data.Lease, error) {
+	now := time.Now()
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO leases (name, holder, address, token, acquired_at, renewed_at, expires_at)
+		VALUES (?, ?, NULLIF(?, ''), 1, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			token = CASE WHEN leases.holder = excluded.holder AND leases.expires_at > excluded.renewed_at
+				THEN leases.token ELSE leases.token + 1 END,
+			acquired_at = CASE WHEN leases.holder = excluded.holder AND leases.expires_at > excluded.renewed_at
+				THEN leases.acquired_at ELSE excluded.acquired_at END,
+			holder = excluded.holder,
+			address = excluded.address,
+			renewed_at = excluded.renewed_at,
+			expires_at = excluded.expires_at
+		WHERE leases.holder = excluded.holder OR leases.expires_at <= excluded.renewed_at`,
+		name, holder, address, now.UnixMilli(), now.UnixMilli(), now.Add(ttl).UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("acquiring lease %s: %w", name, err)
+	}
+	return s.GetLease(ctx, name)
+}
+
+// ReleaseLease expires the named lease if holder owns it, so another
+// replica can take over without waiting for the TTL.
+func (s *SQLiteStore) ReleaseLease(ctx context.Context, name, holder string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE leases SET expires_at = ? WHERE name = ? AND holder = ?`,
+		time.Now().UnixMilli(), name, holder)
+	if err != nil {
+		return fmt.Errorf("releasing lease %s: %w", name, err)
+	}
+	return nil
+}
+
+// GetLease retrieves the named lease, or nil if it has never been acquired.
+func (s *SQLiteStore) GetLease(ctx context.Context, name string) (*data.Lease, error) {
+	var lease data.Lease
+	var acquiredAt, renewedAt, expiresAt int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT name, holder, COALESCE(address, ''), token, acquired_at, renewed_at, expires_at
+		FROM leases
+		WHERE name = ?`, name).Scan(&lease.Name, &lease.Holder, &lease.Address, &lease.Token,
+		&acquiredAt, &renewedAt, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting lease %s: %w", name, err)
+	}
+	lease.AcquiredAt = time.UnixMilli(acquiredAt)
+	lease.RenewedAt = time.UnixMilli(renewedAt)
+	lease.ExpiresAt = time.UnixMilli(expiresAt)
+	return &lease, nil
+}
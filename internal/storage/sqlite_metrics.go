@@ -0,0 +1,147 @@
+// Package storage provides SQLite queries for platform metric series.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// accountMetricSources maps account metrics to queries selecting their
+// (recorded_at, value) observations from the stats tables.
+var accountMetricSources = map[data.Platform]map[string]string{
+	data.PlatformYouTube: {
+		"subscribers":   "SELECT fetched_at AS recorded_at, subscriber_count AS value FROM youtube_channel_stats",
+		"channel_views": "SELECT fetched_at AS recorded_at, view_count AS value FROM youtube_channel_stats",
+		"videos":        "SELECT fetched_at AS recorded_at, video_count AS value FROM youtube_channel_stats",
+	},
+	data.PlatformX: {
+		"followers": "SELECT fetched_at AS recorded_at, follower_count AS value FROM x_user_stats",
+		"following": "SELECT fetched_at AS recorded_at, following_count AS value FROM x_user_stats",
+		"tweets":    "SELECT fetched_at AS recorded_at, tweet_count AS value FROM x_user_stats",
+		"listed":    "SELECT fetched_at AS recorded_at, listed_count AS value FROM x_user_stats",
+	},
+	data.PlatformLinkedIn: {
+		"connections": "SELECT fetched_at AS recorded_at, connection_count AS value FROM linkedin_profile_stats",
+		"followers":   "SELECT fetched_at AS recorded_at, follower_count AS value FROM linkedin_profile_stats",
+	},
+}
+
+// contentMetricSource is a content metric's value per content item.
+type contentMetricSource struct {
+	expr  string
+	where string // Extra condition on the content items, if any
+	flow  bool   // Measured as gains between content_metrics_history snapshots
+}
+
+// contentMetricSources maps content metrics to content_items expressions.
+// Views and impressions are the same column; engagement rate is a
+// percentage. Posts and engagement rate are observed per item at its
+// publish time.
+//
+// Flows are observed as each item's gain since its previous snapshot, at
+// the snapshot's time, so a period counts the views and engagements that
+// happened in it rather than the lifetime totals of what was published in
+// it. What an item gained before its first snapshot counts at its publish
+// time, and items never snapshotted count their current totals there.
+var contentMetricSources = map[string]contentMetricSource{
+	"views":           {expr: "views", flow: true},
+	"impressions":     {expr: "views", flow: true},
+	"likes":           {expr: "likes", flow: true},
+	"comments":        {expr: "comments", flow: true},
+	"shares":          {expr: "shares", flow: true},
+	"engagements":     {expr: "likes + comments + shares", flow: true},
+	"posts":           {expr: "1"},
+	"engagement_rate": {expr: "engagement_rate * 100", where: "views > 0"},
+}
+
+// contentFlowSource selects a flow content metric's gains between
+// snapshots. Its arguments are the platform, three times.
+const contentFlowSource = `
+	SELECT CASE WHEN h.previous IS NULL THEN COALESCE(ci.published_at, h.recorded_at)
+	            ELSE h.recorded_at END AS recorded_at,
+	       h.value - COALESCE(h.previous, 0) AS value
+	FROM (
+		SELECT content_id, recorded_at, %[1]s AS value,
+		       LAG(%[1]s) OVER (PARTITION BY content_id ORDER BY recorded_at) AS previous
+		FROM content_metrics_history
+		WHERE platform = ?
+	) h
+	LEFT JOIN content_items ci ON ci.platform = ? AND ci.id = h.content_id
+	UNION ALL
+	SELECT published_at, %[1]s FROM content_items ci
+	WHERE platform = ? AND NOT EXISTS (
+		SELECT 1 FROM content_metrics_history h
+		WHERE h.platform = ci.platform AND h.content_id = ci.id)`
+
+// metricSource returns a query selecting the (recorded_at, value)
+// observations of a platform metric, and its arguments. Metrics that
+// aren't account or content metrics are read from metrics_history.
+func metricSource(platform data.Platform, metric string) (string, []interface{}) {
+	if query, ok := accountMetricSources[platform][metric]; ok {
+		return query, nil
+	}
+	if source, ok := contentMetricSources[metric]; ok {
+		if source.flow {
+			return fmt.Sprintf(contentFlowSource, source.expr),
+				[]interface{}{string(platform), string(platform), string(platform)}
+		}
+		query := fmt.Sprintf("SELECT published_at AS recorded_at, %s AS value FROM content_items WHERE platform = ?", source.expr)
+		if source.where != "" {
+			query += " AND " + source.where
+		}
+		return query, []interface{}{string(platform)}
+	}
+	return `SELECT recorded_at, metric_value AS value FROM metrics_history
+		WHERE platform = ? AND metric_name = ?`, []interface{}{string(platform), metric}
+}
+
+// GetMetricSeries returns the observations of a platform metric within
+// dateRange in chronological order. A zero start or end leaves that side
+// of the range open.
+func (s *SQLiteStore) GetMetricSeries(ctx context.Context, platform data.Platform, metric string, dateRange data.DateRange) ([]data.DataPoint, error) {
+	source, args := metricSource(platform, metric)
+	query := "SELECT recorded_at, value FROM (" + source + ") WHERE 1 = 1"
+	if !dateRange.Start.IsZero() {
+		query += " AND recorded_at >= ?"
+		args = append(args, dateRange.Start)
+	}
+	if !dateRange.End.IsZero() {
+		query += " AND recorded_at < ?"
+		args = append(args, dateRange.End)
+	}
+	query += " ORDER BY recorded_at"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying metric series: %w", err)
+	}
+	defer rows.Close()
+
+	var points []data.DataPoint
+	for rows.Next() {
+		var recordedAt sqlTime
+		var value float64
+		if err := rows.Scan(&recordedAt, &value); err != nil {
+			return nil, fmt.Errorf("scanning metric point: %w", err)
+		}
+		points = append(points, data.DataPoint{Timestamp: recordedAt.Time, Value: value})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating metric series: %w", err)
+	}
+	return points, nil
+}
+
+// GetMetricStart returns when a platform metric was first observed, or
+// the zero time if it never was.
+func (s *SQLiteStore) GetMetricStart(ctx context.Context, platform data.Platform, metric string) (time.Time, error) {
+	source, args := metricSource(platform, metric)
+	var start sqlTime
+	if err := s.db.QueryRowContext(ctx, "SELECT MIN(recorded_at) FROM ("+source+")", args...).Scan(&start); err != nil {
+		return time.Time{}, fmt.Errorf("getting metric start: %w", err)
+	}
+	return start.Time, nil
+}
@@ -0,0 +1,211 @@
+// Package storage provides SQLite full-text search.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// Snippet highlight markers. Control characters are used so matches can be
+// highlighted after the snippet text has been HTML-escaped.
+const (
+	snippetOpen  = "\x02"
+	snippetClose = "\x03"
+)
+
+// searchSource describes one FTS5 index and how to select results from it.
+// The select must produce the columns read by Search, and accept the MATCH
+// expression as its first argument. Index rowids are search_keys IDs, not
+// rowids of the indexed table.
+type searchSource struct {
+	kind     data.SearchKind
+	platform data.Platform // Empty if the source spans platforms
+	query    string
+	// platformColumn filters by platform for sources spanning platforms.
+	platformColumn string
+}
+
+var searchSources = []searchSource{
+	{
+		kind:     data.SearchKindContent,
+		platform: data.PlatformYouTube,
+		query: `
+			SELECT 'content', 'youtube', v.id, v.id, v.title,
+			       snippet(youtube_videos_fts, -1, char(2), char(3), '…', 16),
+			       v.published_at, bm25(youtube_videos_fts, 2.0, 1.0)
+			FROM youtube_videos_fts
+			JOIN search_keys k ON k.id = youtube_videos_fts.rowid
+			JOIN youtube_videos v ON v.id = k.key AND k.source = 'youtube_videos'
+			WHERE youtube_videos_fts MATCH ?`,
+	},
+	{
+		kind:     data.SearchKindContent,
+		platform: data.PlatformX,
+		query: `
+			SELECT 'content', 'x', t.id, t.id, substr(t.text, 1, 100),
+			       snippet(x_tweets_fts, 0, char(2), char(3), '…', 16),
+			       t.created_at, bm25(x_tweets_fts)
+			FROM x_tweets_fts
+			JOIN search_keys k ON k.id = x_tweets_fts.rowid
+			JOIN x_tweets t ON t.id = k.key AND k.source = 'x_tweets'
+			WHERE x_tweets_fts MATCH ?`,
+	},
+	{
+		kind:     data.SearchKindContent,
+		platform: data.PlatformLinkedIn,
+		query: `
+			SELECT 'content', 'linkedin', p.id, p.id, substr(p.text, 1, 100),
+			       snippet(linkedin_posts_fts, 0, char(2), char(3), '…', 16),
+			       p.created_at, bm25(linkedin_posts_fts)
+			FROM linkedin_posts_fts
+			JOIN search_keys k ON k.id = linkedin_posts_fts.rowid
+			JOIN linkedin_posts p ON p.id = k.key AND k.source = 'linkedin_posts'
+			WHERE linkedin_posts_fts MATCH ?`,
+	},
+	{
+		kind: data.SearchKindComment,
+		query: `
+			SELECT 'comment', c.platform, c.id, c.content_id, COALESCE(ci.title, ''),
+			       snippet(comments_fts, 0, char(2), char(3), '…', 16),
+			       c.created_at, bm25(comments_fts)
+			FROM comments_fts
+			JOIN search_keys k ON k.id = comments_fts.rowid
+			JOIN comments c ON c.id = k.key AND k.source = 'comments'
+			LEFT JOIN content_items ci ON ci.platform = c.platform AND ci.id = c.content_id
+			WHERE comments_fts MATCH ?`,
+		platformColumn: "c.platform",
+	},
+	{
+		kind: data.SearchKindInsight,
+		query: `
+			SELECT 'insight', COALESCE(i.platform, ''), i.id, '', i.title,
+			       snippet(insights_fts, -1, char(2), char(3), '…', 16),
+			       i.generated_at, bm25(insights_fts, 2.0, 1.0)
+			FROM insights_fts
+			JOIN search_keys k ON k.id = insights_fts.rowid
+			JOIN insights i ON i.id = k.key AND k.source = 'insights'
+			WHERE insights_fts MATCH ?`,
+		platformColumn: "i.platform",
+	},
+}
+
+// Search runs a full-text search across content, comments and insights,
+// returning results ordered by relevance.
+func (s *SQLiteStore) Search(ctx context.Context, query data.SearchQuery) ([]*data.SearchResult, error) {
+	match := ftsQuery(query.Text)
+	if match == "" {
+		return nil, nil
+	}
+
+	var parts []string
+	var args []interface{}
+	for _, src := range searchSources {
+		if query.Kind != "" && src.kind != query.Kind {
+			continue
+		}
+		part := src.query
+		partArgs := []interface{}{match}
+		if query.Platform != "" {
+			if src.platform != "" && src.platform != query.Platform {
+				continue
+			}
+			if src.platformColumn != "" {
+				part += " AND " + src.platformColumn + " = ?"
+				partArgs = append(partArgs, string(query.Platform))
+			}
+		}
+		parts = append(parts, part)
+		args = append(args, partArgs...)
+	}
+	if len(parts) == 0 {
+		return nil, nil
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1 // No limit
+	}
+	args = append(args, limit, query.Offset)
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT * FROM ("+strings.Join(parts, " UNION ALL ")+") ORDER BY 8 LIMIT ? OFFSET ?",
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*data.SearchResult
+	for rows.Next() {
+		var r data.SearchResult
+		var kind, platform string
+		var date sqlTime
+		if err := rows.Scan(&kind, &platform, &r.ID, &r.ContentID, &r.Title, &r.Snippet, &date, &r.Rank); err != nil {
+			return nil, fmt.Errorf("scanning search result: %w", err)
+		}
+		r.Kind = data.SearchKind(kind)
+		r.Platform = data.Platform(platform)
+		r.Date = date.Time
+		r.Snippet = highlightSnippet(r.Snippet)
+		results = append(results, &r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating search results: %w", err)
+	}
+	return results, nil
+}
+
+// ftsQuery converts user search text into an FTS5 MATCH expression.
+// "Quoted phrases" are kept as phrases and a trailing * makes a term a
+// prefix match; every other character is quoted so user input can never
+// produce an FTS5 syntax error.
+func ftsQuery(text string) string {
+	var terms []string
+	add := func(term string, prefix bool) {
+		term = strings.TrimFunc(term, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if term == "" {
+			return
+		}
+		quoted := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			quoted += "*"
+		}
+		terms = append(terms, quoted)
+	}
+
+	for text = strings.TrimSpace(text); text != ""; text = strings.TrimSpace(text) {
+		if text[0] == '"' {
+			end := strings.IndexByte(text[1:], '"')
+			if end < 0 {
+				add(text[1:], false)
+				break
+			}
+			add(text[1:end+1], false)
+			text = text[end+2:]
+			continue
+		}
+		end := strings.IndexFunc(text, unicode.IsSpace)
+		if end < 0 {
+			end = len(text)
+		}
+		word := text[:end]
+		add(word, strings.HasSuffix(word, "*"))
+		text = text[end:]
+	}
+	return strings.Join(terms, " ")
+}
+
+// highlightSnippet HTML-escapes a snippet and turns the FTS5 highlight
+// markers into <mark> tags.
+func highlightSnippet(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, snippetOpen, "<mark>")
+	return strings.ReplaceAll(snippet, snippetClose, "</mark>")
+}
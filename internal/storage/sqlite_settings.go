@@ -0,0 +1,51 @@
+// Package storage provides SQLite persistence for application settings.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// GetSetting retrieves a setting. It returns nil if the setting is unset.
+func (s *SQLiteStore) GetSetting(ctx context.Context, key string) (*data.Setting, error) {
+	var setting data.Setting
+	var updatedAt sqlTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT key, value, updated_at FROM settings WHERE key = ?`,
+		key).Scan(&setting.Key, &setting.Value, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying setting %s: %w", key, err)
+	}
+	setting.UpdatedAt = updatedAt.Time
+	return &setting, nil
+}
+
+// SaveSetting sets a setting's value.
+func (s *SQLiteStore) SaveSetting(ctx context.Context, key, value string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO settings (key, value, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(key) DO UPDATE SET
+			value = excluded.value,
+			updated_at = excluded.updated_at`,
+		key, value)
+	if err != nil {
+		return fmt.Errorf("saving setting %s: %w", key, err)
+	}
+	return nil
+}
+
+// DeleteSetting unsets a setting, restoring its default.
+func (s *SQLiteStore) DeleteSetting(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM settings WHERE key = ?`, key); err != nil {
+		return fmt.Errorf("deleting setting %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Package storage provides SQLite persistence for task run history.
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+const taskRunColumns = `id, task, status, manual, started_at, finished_at, attempts,
+	COALESCE(error, ''), items_fetched, api_units`
+
+// StartTaskRun records the start of a task run and sets its ID.
+func (s *SQLiteStore) StartTaskRun(ctx context.Context, run *data.TaskRun) error {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO task_runs (task, status, manual, started_at, attempts)
+		VALUES (?, ?, ?, ?, ?)`,
+		run.Task, string(run.Status), run.Manual, run.StartedAt, run.Attempts)
+	if err != nil {
+		return fmt.Errorf("saving task run: %w", err)
+	}
+	if run.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("getting task run ID: %w", err)
+	}
+	return nil
+}
+
+// FinishTaskRun records the outcome of a task run started with StartTaskRun.
+func (s *SQLiteStore) FinishTaskRun(ctx context.Context, run *data.TaskRun) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE task_runs
+		SET status = ?, finished_at = ?, attempts = ?, error = NULLIF(?, ''),
+		    items_fetched = ?, api_units = ?
+		WHERE id = ?`,
+		string(run.Status), run.FinishedAt, run.Attempts, run.Error,
+		run.ItemsFetched, run.APIUnits, run.ID)
+	if err != nil {
+		return fmt.Errorf("updating task run: %w", err)
+	}
+	return nil
+}
+
+// GetTaskRuns retrieves the most recent runs, newest first, of the named
+// task or of all tasks if task is empty.
+func (s *SQLiteStore) GetTaskRuns(ctx context.Context, task string, limit int) ([]*data.TaskRun, error) {
+	query := "SELECT " + taskRunColumns + " FROM task_runs"
+	var args []interface{}
+	if task != "" {
+		query += " WHERE task = ?"
+		args = append(args, task)
+	}
+	query += " ORDER BY started_at DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	return s.queryTaskRuns(ctx, query, args...)
+}
+
+// GetLatestTaskRuns retrieves the most recent run of each task.
+func (s *SQLiteStore) GetLatestTaskRuns(ctx context.Context) ([]*data.TaskRun, error) {
+	return s.queryTaskRuns(ctx, `
+		SELECT `+taskRunColumns+`
+		FROM task_runs
+		WHERE id IN (SELECT MAX(id) FROM task_runs GROUP BY task)
+		ORDER BY task`)
+}
+
+// queryTaskRuns runs a query selecting taskRunColumns.
+func (s *SQLiteStore) queryTaskRuns(ctx context.Context, query string, args ...interface{}) ([]*data.TaskRun, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying task runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*data.TaskRun
+	for rows.Next() {
+		var run data.TaskRun
+		var status string
+		var startedAt, finishedAt sqlTime // finished_at is NULL while running
+		if err := rows.Scan(&run.ID, &run.Task, &status, &run.Manual, &startedAt, &finishedAt,
+			&run.Attempts, &run.Error, &run.ItemsFetched, &run.APIUnits); err != nil {
+			return nil, fmt.Errorf("scanning task run: %w", err)
+		}
+		run.Status = data.TaskRunStatus(status)
+		run.StartedAt = startedAt.Time
+		run.FinishedAt = finishedAt.Time
+		runs = append(runs, &run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating task runs: %w", err)
+	}
+	return runs, nil
+}
@@ -0,0 +1,351 @@
+// Package storage provides SQLite persistence for embeddings and topics.
+package storage
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// minEmbedTextLength skips comments too short to say what they're about,
+// such as "nice" or a single emoji.
+const minEmbedTextLength = 15
+
+// ListUnembedded returns up to limit of the newest content items and
+// comments without an embedding from model, including those embedded by a
+// different model. Comments labeled as spam are skipped.
+func (s *SQLiteStore) ListUnembedded(ctx context.Context, model string, limit int) ([]*data.EmbeddingSource, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT kind, platform, item_id, text FROM (
+			SELECT 'content' AS kind, ci.platform, ci.id AS item_id,
+			       trim(ci.title || char(10) || ci.body) AS text, ci.published_at AS at
+			FROM content_items ci
+			LEFT JOIN embeddings e
+			       ON e.kind = 'content' AND e.platform = ci.platform AND e.item_id = ci.id
+			WHERE e.item_id IS NULL OR e.model != ?
+			UNION ALL
+			SELECT 'comment', c.platform, c.id, c.text, c.created_at
+			FROM comments c
+			LEFT JOIN comment_labels l ON l.comment_id = c.id
+			LEFT JOIN embeddings e
+			       ON e.kind = 'comment' AND e.platform = c.platform AND e.item_id = c.id
+			WHERE (e.item_id IS NULL OR e.model != ?)
+			  AND COALESCE(l.intent, '') != 'spam'
+			  AND length(trim(c.text)) >= ?
+		)
+		WHERE text != ''
+		ORDER BY at DESC
+		LIMIT ?`,
+		model, model, minEmbedTextLength, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying unembedded items: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []*data.EmbeddingSource
+	for rows.Next() {
+		var src data.EmbeddingSource
+		var platform string
+		if err := rows.Scan(&src.Kind, &platform, &src.ItemID, &src.Text); err != nil {
+			return nil, fmt.Errorf("scanning unembedded item: %w", err)
+		}
+		src.Platform = data.Platform(platform)
+		sources = append(sources, &src)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating unembedded items: %w", err)
+	}
+	return sources, nil
+}
+
+// SaveEmbeddings saves embeddings, replacing earlier ones for the same
+// items.
+func (s *SQLiteStore) SaveEmbeddings(ctx context.Context, embeddings []*data.Embedding) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO embeddings (kind, platform, item_id, model, dims, vector, text, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(kind, platform, item_id) DO UPDATE SET
+			model = excluded.model,
+			dims = excluded.dims,
+			vector = excluded.vector,
+			text = excluded.text,
+			created_at = excluded.created_at`)
+	if err != nil {
+		return fmt.Errorf("preparing embedding insert: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, e := range embeddings {
+		createdAt := e.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = now
+		}
+		if _, err := stmt.ExecContext(ctx, e.Kind, string(e.Platform), e.ItemID, e.Model,
+			len(e.Vector), encodeVector(e.Vector), e.Text, createdAt); err != nil {
+			return fmt.Errorf("saving embedding for %s %s: %w", e.Kind, e.ItemID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing embeddings: %w", err)
+	}
+	return nil
+}
+
+// ListEmbeddings returns all embeddings from model.
+func (s *SQLiteStore) ListEmbeddings(ctx context.Context, model string) ([]*data.Embedding, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT kind, platform, item_id, model, dims, vector, text, created_at
+		FROM embeddings
+		WHERE model = ?
+		ORDER BY kind, platform, item_id`,
+		model)
+	if err != nil {
+		return nil, fmt.Errorf("querying embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var embeddings []*data.Embedding
+	for rows.Next() {
+		var e data.Embedding
+		var platform string
+		var dims int
+		var vector []byte
+		var createdAt sqlTime
+		if err := rows.Scan(&e.Kind, &platform, &e.ItemID, &e.Model, &dims, &vector,
+			&e.Text, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning embedding: %w", err)
+		}
+		if len(vector) != dims*4 {
+			return nil, fmt.Errorf("embedding for %s %s has %d bytes, want %d", e.Kind, e.ItemID, len(vector), dims*4)
+		}
+		e.Platform = data.Platform(platform)
+		e.Vector = decodeVector(vector)
+		e.CreatedAt = createdAt.Time
+		embeddings = append(embeddings, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating embeddings: %w", err)
+	}
+	return embeddings, nil
+}
+
+// encodeVector encodes v as little-endian float32 values.
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, len(v)*4)
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(f))
+	}
+	return buf
+}
+
+// decodeVector decodes a vector encoded by encodeVector.
+func decodeVector(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return v
+}
+
+// ReplaceTopics replaces all topics and their members with topics, setting
+// their IDs.
+func (s *SQLiteStore) ReplaceTopics(ctx context.Context, topics []*data.Topic) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Members are deleted explicitly in case foreign keys aren't enforced.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM topic_members`); err != nil {
+		return fmt.Errorf("deleting topic members: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM topics`); err != nil {
+		return fmt.Errorf("deleting topics: %w", err)
+	}
+
+	memberStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO topic_members (topic_id, kind, platform, item_id, similarity)
+		VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("preparing topic member insert: %w", err)
+	}
+	defer memberStmt.Close()
+
+	now := time.Now()
+	for _, t := range topics {
+		keywords := t.Keywords
+		if keywords == nil {
+			keywords = []string{}
+		}
+		encoded, err := json.Marshal(keywords)
+		if err != nil {
+			return fmt.Errorf("encoding topic keywords: %w", err)
+		}
+		if t.CreatedAt.IsZero() {
+			t.CreatedAt = now
+		}
+		if len(t.Members) > 0 {
+			t.Size = len(t.Members)
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO topics (label, keywords, size, created_at)
+			VALUES (?, ?, ?, ?)`,
+			t.Label, string(encoded), t.Size, t.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("saving topic %q: %w", t.Label, err)
+		}
+		if t.ID, err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("getting topic ID: %w", err)
+		}
+
+		for _, m := range t.Members {
+			if _, err := memberStmt.ExecContext(ctx, t.ID, m.Kind, string(m.Platform),
+				m.ItemID, m.Similarity); err != nil {
+				return fmt.Errorf("saving member %s %s of topic %q: %w", m.Kind, m.ItemID, t.Label, err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing topics: %w", err)
+	}
+	return nil
+}
+
+// ListTopics returns the topics, largest first, without their members.
+func (s *SQLiteStore) ListTopics(ctx context.Context) ([]*data.Topic, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, label, keywords, size, created_at
+		FROM topics
+		ORDER BY size DESC, id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying topics: %w", err)
+	}
+	defer rows.Close()
+
+	var topics []*data.Topic
+	for rows.Next() {
+		var t data.Topic
+		var keywords string
+		var createdAt sqlTime
+		if err := rows.Scan(&t.ID, &t.Label, &keywords, &t.Size, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning topic: %w", err)
+		}
+		if err := json.Unmarshal([]byte(keywords), &t.Keywords); err != nil {
+			return nil, fmt.Errorf("decoding topic keywords: %w", err)
+		}
+		t.CreatedAt = createdAt.Time
+		topics = append(topics, &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating topics: %w", err)
+	}
+	return topics, nil
+}
+
+// GetTopicStats returns the activity in each topic over dateRange and the
+// preceding range of the same length, in the order of ListTopics, with up
+// to examples of each topic's members closest to its centre.
+func (s *SQLiteStore) GetTopicStats(ctx context.Context, dateRange data.DateRange, examples int) ([]*data.TopicStats, error) {
+	topics, err := s.ListTopics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(topics) == 0 {
+		return nil, nil
+	}
+
+	stats := make([]*data.TopicStats, len(topics))
+	byID := make(map[int64]*data.TopicStats, len(topics))
+	for i, t := range topics {
+		stats[i] = &data.TopicStats{Topic: t}
+		byID[t.ID] = stats[i]
+	}
+
+	// Members are ordered by similarity so the first of each topic are its
+	// examples.
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT m.topic_id, m.kind, m.platform, m.item_id, m.similarity,
+		       COALESCE(e.text, ''),
+		       COALESCE(ci.published_at, c.created_at),
+		       COALESCE(ci.views, 0),
+		       COALESCE(ci.likes + ci.comments + ci.shares, 1 + c.like_count, 0)
+		FROM topic_members m
+		LEFT JOIN embeddings e
+		       ON e.kind = m.kind AND e.platform = m.platform AND e.item_id = m.item_id
+		LEFT JOIN content_items ci
+		       ON m.kind = 'content' AND ci.platform = m.platform AND ci.id = m.item_id
+		LEFT JOIN comments c
+		       ON m.kind = 'comment' AND c.id = m.item_id
+		ORDER BY m.topic_id, m.similarity DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("querying topic members: %w", err)
+	}
+	defer rows.Close()
+
+	previousStart := dateRange.Start.Add(-dateRange.End.Sub(dateRange.Start))
+	contentEngagements := make(map[int64]int64)
+	for rows.Next() {
+		var topicID int64
+		var example data.TopicExample
+		var platform string
+		var at sqlTime
+		var views, engagements int64
+		if err := rows.Scan(&topicID, &example.Kind, &platform, &example.ItemID, &example.Similarity,
+			&example.Text, &at, &views, &engagements); err != nil {
+			return nil, fmt.Errorf("scanning topic member: %w", err)
+		}
+		example.Platform = data.Platform(platform)
+
+		st := byID[topicID]
+		if st == nil {
+			continue
+		}
+		if len(st.Examples) < examples && example.Text != "" {
+			ex := example
+			st.Examples = append(st.Examples, &ex)
+		}
+
+		switch {
+		case at.IsZero():
+			// The item has been deleted since topics were built.
+		case !at.Before(dateRange.Start) && at.Before(dateRange.End):
+			st.Engagements += engagements
+			if example.Kind == data.EmbeddingKindContent {
+				st.Content++
+				st.Views += views
+				contentEngagements[topicID] += engagements
+			} else {
+				st.Comments++
+			}
+		case !at.Before(previousStart) && at.Before(dateRange.Start):
+			st.Previous += engagements
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating topic members: %w", err)
+	}
+
+	for _, st := range stats {
+		if st.Views > 0 {
+			st.EngagementRate = float64(contentEngagements[st.Topic.ID]) / float64(st.Views)
+		}
+	}
+	return stats, nil
+}
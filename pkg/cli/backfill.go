@@ -0,0 +1,91 @@
+// Package cli provides the backfill command for importing content history.
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/config"
+	"github.com/omnipulse/omnipulse/internal/data"
+)
+
+// runBackfill implements "omnipulse backfill". Jobs run inside the server
+// process, so the command talks to a running server's API.
+//
+//	omnipulse backfill [-server URL]            list backfill jobs and their progress
+//	omnipulse backfill [-server URL] <platform> start a backfill of youtube, x or linkedin
+func runBackfill(args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	server := fs.String("server", fmt.Sprintf("http://%s:%d", cfg.Server.Host, cfg.Server.Port),
+		"base URL of the running OmniPulse server")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client := &tasksClient{baseURL: strings.TrimSuffix(*server, "/")}
+
+	switch fs.NArg() {
+	case 0:
+		return listBackfills(ctx, client)
+	case 1:
+		return startBackfill(ctx, client, fs.Arg(0))
+	default:
+		return fmt.Errorf("usage: omnipulse backfill [platform]")
+	}
+}
+
+// listBackfills prints recent backfill jobs with their progress.
+func listBackfills(ctx context.Context, client *tasksClient) error {
+	var status struct {
+		Platforms []data.Platform     `json:"platforms"`
+		Jobs      []*data.BackfillJob `json:"jobs"`
+	}
+	if err := client.do(ctx, http.MethodGet, "/api/v1/backfill", &status); err != nil {
+		return err
+	}
+
+	if len(status.Jobs) == 0 {
+		fmt.Println("No backfill jobs")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPLATFORM\tSTATUS\tPROGRESS\tPAGES\tUNITS\tUPDATED\tERROR")
+	for _, job := range status.Jobs {
+		progress := fmt.Sprintf("%d items", job.Items)
+		if pct := job.ProgressPercent(); pct >= 0 {
+			progress = fmt.Sprintf("%d/%d (%d%%)", job.Items, job.Total, pct)
+		}
+		jobError := "-"
+		if job.Error != "" {
+			jobError = job.Error
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			job.ID, job.Platform, job.Status, progress, job.Pages, job.UnitsUsed,
+			formatTime(job.UpdatedAt), jobError)
+	}
+	return w.Flush()
+}
+
+// startBackfill starts a backfill of one platform on the server.
+func startBackfill(ctx context.Context, client *tasksClient, platform string) error {
+	var job data.BackfillJob
+	if err := client.do(ctx, http.MethodPost, "/api/v1/backfill/"+url.PathEscape(platform), &job); err != nil {
+		return err
+	}
+	fmt.Printf("Started %s backfill (job %d)\n", job.Platform, job.ID)
+	return nil
+}
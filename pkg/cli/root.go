@@ -3,6 +3,7 @@ package cli
 
 import (
 	"fmt"
+	"os"
 )
 
 // Execute runs the root command of the CLI application.
@@ -10,8 +11,26 @@ import (
 // - fetch: Fetch analytics from platforms (YouTube, X, LinkedIn)
 // - serve: Start the HTMX web dashboard
 // - insights: Generate LLM-powered insights
+// - tasks: Show and run scheduled background tasks
+// - backfill: Import a platform's full content history
 func Execute() error {
-	// TODO: Implement CLI with cobra or stdlib flag package
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "tasks":
+			return runTasks(args[1:])
+		case "backfill":
+			return runBackfill(args[1:])
+		}
+	}
+
+	// TODO: Implement remaining commands with the stdlib flag package
+	printUsage()
+	return nil
+}
+
+// printUsage prints the list of commands.
+func printUsage() {
 	fmt.Println("OmniPulse - Multiplatform Content Analytics")
 	fmt.Println("Usage: omnipulse <command> [options]")
 	fmt.Println()
@@ -19,6 +38,7 @@ func Execute() error {
 	fmt.Println("  fetch     Fetch analytics from platforms")
 	fmt.Println("  serve     Start the web dashboard")
 	fmt.Println("  insights  Generate AI-powered insights")
+	fmt.Println("  tasks     Show scheduled tasks, or run one with 'tasks run <name>'")
+	fmt.Println("  backfill  Show backfill progress, or import a platform's history with 'backfill <platform>'")
 	fmt.Println("  migrate   Run database migrations")
-	return nil
 }
@@ -0,0 +1,143 @@
+// Package cli provides the tasks command for inspecting the scheduler.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/omnipulse/omnipulse/internal/config"
+	"github.com/omnipulse/omnipulse/internal/scheduler"
+)
+
+// runTasks implements "omnipulse tasks". The scheduler runs inside the
+// server process, so the command talks to a running server's API.
+//
+//	omnipulse tasks [-server URL]            list tasks and their last runs
+//	omnipulse tasks [-server URL] run <name> run a task now
+func runTasks(args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	fs := flag.NewFlagSet("tasks", flag.ContinueOnError)
+	server := fs.String("server", fmt.Sprintf("http://%s:%d", cfg.Server.Host, cfg.Server.Port),
+		"base URL of the running OmniPulse server")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client := &tasksClient{baseURL: strings.TrimSuffix(*server, "/")}
+
+	switch fs.Arg(0) {
+	case "":
+		return client.list(ctx)
+	case "run":
+		if fs.NArg() != 2 {
+			return fmt.Errorf("usage: omnipulse tasks run <name>")
+		}
+		return client.run(ctx, fs.Arg(1))
+	default:
+		return fmt.Errorf("unknown tasks subcommand %q", fs.Arg(0))
+	}
+}
+
+// tasksClient calls the server's task API.
+type tasksClient struct {
+	baseURL string
+}
+
+// list prints each task with its schedule, last run and next run.
+func (c *tasksClient) list(ctx context.Context) error {
+	var status struct {
+		Running bool                 `json:"running"`
+		Tasks   []scheduler.TaskInfo `json:"tasks"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/tasks", &status); err != nil {
+		return err
+	}
+
+	if !status.Running {
+		fmt.Println("Scheduler is not running")
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TASK\tSCHEDULE\tLAST RUN\tSTATUS\tDURATION\tNEXT RUN\tLAST ERROR")
+	for _, t := range status.Tasks {
+		lastRun, runStatus, duration := "never", "-", "-"
+		if t.LastRun != nil {
+			lastRun = formatTime(t.LastRun.StartedAt)
+			runStatus = string(t.LastRun.Status)
+			if !t.LastRun.FinishedAt.IsZero() {
+				duration = t.LastRun.Duration().Round(time.Millisecond).String()
+			}
+		}
+		if t.Running {
+			runStatus = "running"
+		}
+		lastError := "-"
+		if t.LastError != "" {
+			lastError = t.LastError
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.Name, t.Schedule, lastRun, runStatus, duration, formatTime(t.NextRun), lastError)
+	}
+	return w.Flush()
+}
+
+// run starts a task on the server.
+func (c *tasksClient) run(ctx context.Context, name string) error {
+	if err := c.do(ctx, http.MethodPost, "/api/v1/tasks/"+url.PathEscape(name)+"/run", nil); err != nil {
+		return err
+	}
+	fmt.Printf("Started task %s\n", name)
+	return nil
+}
+
+// do sends a request and decodes a JSON response into out if non-nil.
+func (c *tasksClient) do(ctx context.Context, method, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("contacting server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("server: %s", apiErr.Error)
+		}
+		return fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("decoding response: %w", err)
+		}
+	}
+	return nil
+}
+
+// formatTime formats a time for the task table, or "-" if it is zero.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("Jan 2 15:04:05")
+}